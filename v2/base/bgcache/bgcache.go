@@ -0,0 +1,209 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package bgcache
+
+import (
+	"container/list"
+	"image"
+	"reflect"
+	"sync"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/base/random"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// DefaultMaxBytes is the default memory limit of a cache
+	DefaultMaxBytes int64 = 64 << 20
+	// DefaultCandidates is the default number of crop candidates kept per background and size
+	DefaultCandidates = 4
+)
+
+// Cache keeps backgrounds already scaled and cropped to a target size, so drawing
+// a captcha does not have to touch the full-size source image again.
+// Every source image and size gets several random crop candidates, entries are
+// evicted in LRU order once the memory limit is exceeded.
+// A Cache is safe for concurrent use, the images it returns are shared and must be treated as read-only.
+type Cache struct {
+	mu         sync.Mutex
+	maxBytes   int64
+	usedBytes  int64
+	candidates int
+	ll         *list.List
+	items      map[key]*list.Element
+}
+
+// key identifies the candidates of a source image at a target size
+type key struct {
+	src    image.Image
+	width  int
+	height int
+}
+
+// entry is the value held by the LRU list
+type entry struct {
+	key    key
+	images []*image.NRGBA
+	bytes  int64
+}
+
+// New creates a background cache
+// params:
+//   - maxBytes: Memory limit, values less than or equal to 0 use DefaultMaxBytes
+//   - candidates: Crop candidates per background and size, values less than or equal to 0 use DefaultCandidates
+//
+// return: Cache instance
+func New(maxBytes int64, candidates int) *Cache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if candidates <= 0 {
+		candidates = DefaultCandidates
+	}
+
+	return &Cache{
+		maxBytes:   maxBytes,
+		candidates: candidates,
+		ll:         list.New(),
+		items:      make(map[key]*list.Element),
+	}
+}
+
+// Preload prepares the candidates of every source image for the target size
+// params:
+//   - srcs: Source images
+//   - width, height: Target size
+func (c *Cache) Preload(srcs []image.Image, width, height int) {
+	for _, src := range srcs {
+		c.Get(src, width, height)
+	}
+}
+
+// Get returns one of the cached candidates of src at the target size, they are prepared on a miss
+// params:
+//   - src: Source image
+//   - width, height: Target size
+//
+// return: Background exactly width x height, nil when src is nil or the size is invalid
+func (c *Cache) Get(src image.Image, width, height int) *image.NRGBA {
+	if src == nil || width <= 0 || height <= 0 {
+		return nil
+	}
+
+	if !reflect.TypeOf(src).Comparable() {
+		return prepare(src, width, height, 1)[0]
+	}
+
+	k := key{src: src, width: width, height: height}
+	c.mu.Lock()
+	if el, ok := c.items[k]; ok {
+		c.ll.MoveToFront(el)
+		images := el.Value.(*entry).images
+		c.mu.Unlock()
+		return images[helper.RandIndex(len(images))]
+	}
+	c.mu.Unlock()
+
+	images := prepare(src, width, height, c.candidates)
+	c.add(k, images)
+
+	return images[helper.RandIndex(len(images))]
+}
+
+// Len returns the number of cached source and size pairs
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// UsedBytes returns the memory held by the cached images
+func (c *Cache) UsedBytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usedBytes
+}
+
+// Clear removes all entries
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[key]*list.Element)
+	c.usedBytes = 0
+}
+
+// add stores the candidates and evicts the least recently used entries over the memory limit
+func (c *Cache) add(k key, images []*image.NRGBA) {
+	var bytes int64
+	for _, img := range images {
+		bytes += int64(len(img.Pix))
+	}
+	if bytes > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[k]; ok {
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[k] = c.ll.PushFront(&entry{key: k, images: images, bytes: bytes})
+	c.usedBytes += bytes
+
+	for c.usedBytes > c.maxBytes {
+		el := c.ll.Back()
+		if el == nil {
+			break
+		}
+		e := el.Value.(*entry)
+		c.ll.Remove(el)
+		delete(c.items, e.key)
+		c.usedBytes -= e.bytes
+	}
+}
+
+// prepare scales src to cover the target size when it is smaller, then crops n random candidates
+func prepare(src image.Image, width, height, n int) []*image.NRGBA {
+	b := src.Bounds()
+	if b.Dx() < width || b.Dy() < height {
+		ratio := float64(width) / float64(b.Dx())
+		if r := float64(height) / float64(b.Dy()); r > ratio {
+			ratio = r
+		}
+
+		w := int(float64(b.Dx())*ratio + 0.5)
+		h := int(float64(b.Dy())*ratio + 0.5)
+		if w < width {
+			w = width
+		}
+		if h < height {
+			h = height
+		}
+		scaled := image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.BiLinear.Scale(scaled, scaled.Bounds(), src, b, draw.Src, nil)
+		src = scaled
+		b = scaled.Bounds()
+	}
+
+	images := make([]*image.NRGBA, 0, n)
+	for i := 0; i < n; i++ {
+		pt := image.Point{
+			X: b.Min.X + random.RandInt(0, b.Dx()-width),
+			Y: b.Min.Y + random.RandInt(0, b.Dy()-height),
+		}
+		dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(dst, dst.Bounds(), src, pt, draw.Src)
+		images = append(images, dst)
+	}
+
+	return images
+}
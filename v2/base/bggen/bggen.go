@@ -0,0 +1,379 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package bggen generates backgrounds procedurally, every call draws a new image so the
+// backgrounds of a captcha never come from a finite set an attacker could collect
+package bggen
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vector"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+// Generator draws a new background on every call
+type Generator interface {
+	Generate(width, height int, rnd *rand.Rand) *image.NRGBA
+}
+
+// GeneratorFunc adapts a function to the Generator interface
+type GeneratorFunc func(width, height int, rnd *rand.Rand) *image.NRGBA
+
+// Generate calls f(width, height, rnd)
+func (f GeneratorFunc) Generate(width, height int, rnd *rand.Rand) *image.NRGBA {
+	return f(width, height, rnd)
+}
+
+// Defaults returns one generator of every kind with its default settings
+func Defaults() []Generator {
+	return []Generator{
+		NewGradient(),
+		NewNoise(),
+		NewVoronoi(24),
+		NewPolygons(16),
+		NewBokeh(24),
+	}
+}
+
+// gradient draws a linear gradient through three colors
+type gradient struct{}
+
+// NewGradient returns a generator of linear gradients at a random angle through three colors of a random palette
+func NewGradient() Generator {
+	return &gradient{}
+}
+
+// Generate .
+func (g *gradient) Generate(width, height int, rnd *rand.Rand) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	pal := newPalette(rnd)
+	stops := []color.NRGBA{pal.color(rnd), pal.color(rnd), pal.color(rnd)}
+
+	angle := rnd.Float64() * 2 * math.Pi
+	ux, uy := math.Cos(angle), math.Sin(angle)
+	lo, hi := projectRange(width, height, ux, uy)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := (float64(x)*ux + float64(y)*uy - lo) / (hi - lo)
+			setPixel(img, x, y, rampColor(stops, t))
+		}
+	}
+	return img
+}
+
+// noise draws fractal Perlin noise mapped to a color ramp
+type noise struct{}
+
+// NewNoise returns a generator of fractal Perlin noise mapped to the colors of a random palette
+func NewNoise() Generator {
+	return &noise{}
+}
+
+// Generate .
+func (n *noise) Generate(width, height int, rnd *rand.Rand) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	pal := newPalette(rnd)
+	stops := []color.NRGBA{pal.color(rnd), pal.color(rnd), pal.color(rnd)}
+	p := newPerlin(rnd)
+
+	scale := (2 + rnd.Float64()*3) / float64(helper.MaxInt(width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v, amp, freq := 0.0, 1.0, scale
+			for o := 0; o < 4; o++ {
+				v += p.at(float64(x)*freq, float64(y)*freq) * amp
+				amp *= 0.5
+				freq *= 2
+			}
+			setPixel(img, x, y, rampColor(stops, v*0.6+0.5))
+		}
+	}
+	return img
+}
+
+// voronoi colors the cells around random seeds
+type voronoi struct {
+	cells int
+}
+
+// NewVoronoi returns a generator of Voronoi diagrams of the number of cells, shaded towards their borders
+func NewVoronoi(cells int) Generator {
+	return &voronoi{cells: helper.MaxInt(cells, 2)}
+}
+
+// Generate .
+func (v *voronoi) Generate(width, height int, rnd *rand.Rand) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	pal := newPalette(rnd)
+
+	type seed struct {
+		x, y float64
+		c    color.NRGBA
+	}
+	seeds := make([]seed, v.cells)
+	for i := range seeds {
+		seeds[i] = seed{x: rnd.Float64() * float64(width), y: rnd.Float64() * float64(height), c: pal.color(rnd)}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			d1, d2, nearest := math.MaxFloat64, math.MaxFloat64, 0
+			for i, s := range seeds {
+				d := math.Hypot(float64(x)-s.x, float64(y)-s.y)
+				if d < d1 {
+					d1, d2, nearest = d, d1, i
+				} else if d < d2 {
+					d2 = d
+				}
+			}
+			// darken the pixels close to the border with the second nearest cell
+			shade := 0.75 + 0.25*math.Min((d2-d1)/6, 1)
+			setPixel(img, x, y, scaleColor(seeds[nearest].c, shade))
+		}
+	}
+	return img
+}
+
+// polygons fills translucent random polygons over a plain color
+type polygons struct {
+	count int
+}
+
+// NewPolygons returns a generator of the number of translucent random polygons over a plain color
+func NewPolygons(count int) Generator {
+	return &polygons{count: helper.MaxInt(count, 1)}
+}
+
+// Generate .
+func (p *polygons) Generate(width, height int, rnd *rand.Rand) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	pal := newPalette(rnd)
+	draw.Draw(img, img.Bounds(), image.NewUniform(pal.color(rnd)), image.Point{}, draw.Src)
+
+	size := float64(helper.MaxInt(width, height))
+	for i := 0; i < p.count; i++ {
+		cx, cy := rnd.Float64()*float64(width), rnd.Float64()*float64(height)
+		radius := size * (0.1 + rnd.Float64()*0.3)
+		sides := 3 + rnd.Intn(4)
+		start := rnd.Float64() * 2 * math.Pi
+
+		r := vector.NewRasterizer(width, height)
+		for k := 0; k < sides; k++ {
+			a := start + 2*math.Pi*float64(k)/float64(sides) + (rnd.Float64()-0.5)*0.6
+			d := radius * (0.6 + rnd.Float64()*0.4)
+			x, y := float32(cx+math.Cos(a)*d), float32(cy+math.Sin(a)*d)
+			if k == 0 {
+				r.MoveTo(x, y)
+			} else {
+				r.LineTo(x, y)
+			}
+		}
+		r.ClosePath()
+
+		c := pal.color(rnd)
+		c.A = uint8(60 + rnd.Intn(100))
+		r.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{})
+	}
+	return img
+}
+
+// bokeh draws soft out of focus light discs over a gradient
+type bokeh struct {
+	count int
+}
+
+// NewBokeh returns a generator of the number of soft light discs over a dark gradient
+func NewBokeh(count int) Generator {
+	return &bokeh{count: helper.MaxInt(count, 1)}
+}
+
+// Generate .
+func (b *bokeh) Generate(width, height int, rnd *rand.Rand) *image.NRGBA {
+	img := (&gradient{}).Generate(width, height, rnd)
+	for i := range img.Pix {
+		if i%4 != 3 {
+			img.Pix[i] = uint8(float64(img.Pix[i]) * 0.6)
+		}
+	}
+
+	pal := newPalette(rnd)
+	size := float64(helper.MaxInt(width, height))
+	for i := 0; i < b.count; i++ {
+		cx, cy := rnd.Float64()*float64(width), rnd.Float64()*float64(height)
+		radius := size * (0.03 + rnd.Float64()*0.09)
+		c := pal.color(rnd)
+		strength := 0.25 + rnd.Float64()*0.35
+
+		r := image.Rect(int(cx-radius)-1, int(cy-radius)-1, int(cx+radius)+2, int(cy+radius)+2).Intersect(img.Bounds())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) / radius
+				if d >= 1 {
+					continue
+				}
+				// a flat disc with a soft rim, brighter towards its edge like a lens highlight
+				a := strength * math.Min((1-d)*4, 1) * (0.8 + 0.2*d)
+				i := img.PixOffset(x, y)
+				img.Pix[i] = screen(img.Pix[i], c.R, a)
+				img.Pix[i+1] = screen(img.Pix[i+1], c.G, a)
+				img.Pix[i+2] = screen(img.Pix[i+2], c.B, a)
+			}
+		}
+	}
+	return img
+}
+
+// palette picks colors around a random hue with a moderate saturation and value,
+// so text and shapes drawn over the background stay readable
+type palette struct {
+	hue float64
+}
+
+func newPalette(rnd *rand.Rand) *palette {
+	return &palette{hue: rnd.Float64() * 360}
+}
+
+// color returns a random color of the palette
+func (p *palette) color(rnd *rand.Rand) color.NRGBA {
+	h := math.Mod(p.hue+(rnd.Float64()*2-1)*60+360, 360)
+	return hsv(h, 0.25+rnd.Float64()*0.45, 0.45+rnd.Float64()*0.45)
+}
+
+// perlin is a 2D gradient noise with a permutation of its own
+type perlin struct {
+	perm [512]int
+}
+
+func newPerlin(rnd *rand.Rand) *perlin {
+	p := &perlin{}
+	for i, v := range rnd.Perm(256) {
+		p.perm[i] = v
+		p.perm[i+256] = v
+	}
+	return p
+}
+
+// at returns the noise at x, y, roughly from -0.7 to 0.7
+func (p *perlin) at(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	xi, yi := int(x0)&255, int(y0)&255
+
+	u, v := fade(fx), fade(fy)
+	aa := p.perm[p.perm[xi]+yi]
+	ab := p.perm[p.perm[xi]+yi+1]
+	ba := p.perm[p.perm[xi+1]+yi]
+	bb := p.perm[p.perm[xi+1]+yi+1]
+
+	return lerp(
+		lerp(grad(aa, fx, fy), grad(ba, fx-1, fy), u),
+		lerp(grad(ab, fx, fy-1), grad(bb, fx-1, fy-1), u),
+		v,
+	)
+}
+
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// grad returns the dot product of one of 8 gradient directions with x, y
+func grad(hash int, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// projectRange returns the smallest and largest projection of the image corners on ux, uy
+func projectRange(width, height int, ux, uy float64) (float64, float64) {
+	lo, hi := math.MaxFloat64, -math.MaxFloat64
+	for _, c := range [][2]float64{{0, 0}, {float64(width), 0}, {0, float64(height)}, {float64(width), float64(height)}} {
+		d := c[0]*ux + c[1]*uy
+		lo, hi = math.Min(lo, d), math.Max(hi, d)
+	}
+	if hi-lo < 1 {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// rampColor interpolates the stops at t from 0 to 1
+func rampColor(stops []color.NRGBA, t float64) color.NRGBA {
+	t = helper.ClampUnit(t) * float64(len(stops)-1)
+	i := helper.MinInt(int(t), len(stops)-2)
+	f := t - float64(i)
+	a, b := stops[i], stops[i+1]
+	return color.NRGBA{
+		R: uint8(lerp(float64(a.R), float64(b.R), f) + 0.5),
+		G: uint8(lerp(float64(a.G), float64(b.G), f) + 0.5),
+		B: uint8(lerp(float64(a.B), float64(b.B), f) + 0.5),
+		A: 0xff,
+	}
+}
+
+func scaleColor(c color.NRGBA, k float64) color.NRGBA {
+	return color.NRGBA{R: uint8(float64(c.R) * k), G: uint8(float64(c.G) * k), B: uint8(float64(c.B) * k), A: c.A}
+}
+
+// screen blends the light l over the base b by a
+func screen(b, l uint8, a float64) uint8 {
+	s := 255 - (255-float64(b))*(255-float64(l))/255
+	return uint8(float64(b) + (s-float64(b))*a + 0.5)
+}
+
+func setPixel(img *image.NRGBA, x, y int, c color.NRGBA) {
+	i := img.PixOffset(x, y)
+	img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, 0xff
+}
+
+// hsv converts a hue in degrees, a saturation and a value from 0 to 1 to a color
+func hsv(h, s, v float64) color.NRGBA {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.NRGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 0xff}
+}
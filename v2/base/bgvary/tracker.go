@@ -0,0 +1,164 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package bgvary
+
+import (
+	"hash/fnv"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/wenlng/go-captcha/v2/base/logger"
+)
+
+const (
+	// DefaultThreshold is the default effective number of backgrounds below which a tracker warns
+	DefaultThreshold = 50
+	// DefaultWindow is the default number of recent backgrounds a tracker looks at
+	DefaultWindow = 1000
+)
+
+// Tracker counts the backgrounds used by the recent captchas and warns when the effective
+// number of distinct ones is below a threshold, a few hundred challenges drawn on a small set
+// of backgrounds are enough to rebuild the clean images.
+// The effective number is the exponential of the entropy of the usage, so a set where one
+// background is used most of the time counts as fewer than its size.
+// A Tracker is safe for concurrent use and may be shared by several captchas.
+type Tracker struct {
+	mu        sync.Mutex
+	threshold float64
+	recent    []uint64
+	next      int
+	filled    bool
+	counts    map[uint64]int
+	sinceWarn int
+}
+
+// NewTracker creates a background tracker
+// params:
+//   - threshold: Effective number of backgrounds below which it warns, values less than or equal to 0 use DefaultThreshold
+//   - window: Number of recent backgrounds it looks at, values less than or equal to 0 use DefaultWindow
+//
+// return: Tracker instance
+func NewTracker(threshold, window int) *Tracker {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	return &Tracker{
+		threshold: float64(threshold),
+		recent:    make([]uint64, window),
+		counts:    make(map[uint64]int),
+	}
+}
+
+// Observe records the source background of a captcha, it warns once per window while the
+// window is full and the effective number is below the threshold
+// params:
+//   - img: Source background, before any variation
+//   - l: Logger of the captcha the warning goes to, nil uses the default logger
+func (t *Tracker) Observe(img image.Image, l logger.Logger) {
+	if img == nil {
+		return
+	}
+	fp := fingerprint(img)
+
+	t.mu.Lock()
+	if t.filled {
+		old := t.recent[t.next]
+		if t.counts[old]--; t.counts[old] == 0 {
+			delete(t.counts, old)
+		}
+	}
+	t.recent[t.next] = fp
+	t.counts[fp]++
+	t.next++
+	if t.next == len(t.recent) {
+		t.next, t.filled = 0, true
+	}
+
+	t.sinceWarn++
+	warn := t.filled && t.sinceWarn >= len(t.recent)
+	var effective float64
+	if warn {
+		effective = t.effective()
+		warn = effective < t.threshold
+		if warn {
+			t.sinceWarn = 0
+		}
+	}
+	distinct := len(t.counts)
+	t.mu.Unlock()
+
+	if warn {
+		if l == nil {
+			l = logger.Default()
+		}
+		logger.With(l, logger.F("component", "bgvary")).Warnf("%.1f effective backgrounds (%d distinct) in the last %d captchas, below %.0f, add backgrounds, generators or a variation",
+			effective, distinct, len(t.recent), t.threshold)
+	}
+}
+
+// Effective returns the effective number of distinct backgrounds in the window
+func (t *Tracker) Effective() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.effective()
+}
+
+// Distinct returns the number of distinct backgrounds in the window
+func (t *Tracker) Distinct() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.counts)
+}
+
+// effective returns the exponential of the entropy of the usage, t.mu must be held
+func (t *Tracker) effective() float64 {
+	total := t.next
+	if t.filled {
+		total = len(t.recent)
+	}
+	if total == 0 {
+		return 0
+	}
+
+	h := 0.0
+	for _, n := range t.counts {
+		p := float64(n) / float64(total)
+		h -= p * math.Log(p)
+	}
+	return math.Exp(h)
+}
+
+// fingerprint hashes the size of the image and a grid of its pixels
+func fingerprint(img image.Image) uint64 {
+	const grid = 8
+	b := img.Bounds()
+	h := fnv.New64a()
+	buf := make([]byte, 0, 8+grid*grid*8)
+	buf = appendUint32(buf, uint32(b.Dx()))
+	buf = appendUint32(buf, uint32(b.Dy()))
+
+	for gy := 0; gy < grid; gy++ {
+		for gx := 0; gx < grid; gx++ {
+			x := b.Min.X + (2*gx+1)*b.Dx()/(2*grid)
+			y := b.Min.Y + (2*gy+1)*b.Dy()/(2*grid)
+			r, g, bl, a := img.At(x, y).RGBA()
+			buf = append(buf, byte(r>>8), byte(g>>8), byte(bl>>8), byte(a>>8))
+		}
+	}
+	_, _ = h.Write(buf)
+	return h.Sum64()
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
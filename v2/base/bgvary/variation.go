@@ -0,0 +1,145 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package bgvary makes every use of a background image look different and tracks how many
+// distinct backgrounds are in use, so collecting challenges does not reveal the clean backgrounds
+package bgvary
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"golang.org/x/image/draw"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+// Variation defines the random changes made to a background before a captcha is drawn on it
+type Variation struct {
+	// Crop is the largest share of the width and height cut away before scaling back, from 0 to 0.5
+	Crop float64
+	// Flip mirrors the background horizontally for half of the captchas
+	Flip bool
+	// Hue is the largest hue shift in degrees, from 0 to 180
+	Hue int
+	// Brightness is the largest brightness change, from 0 to 1
+	Brightness float64
+	// Contrast is the largest contrast change, from 0 to 1
+	Contrast float64
+	// Texture is the strength of the noise texture laid over the background, from 0 to 1
+	Texture float64
+}
+
+// Default returns a variation that changes every background noticeably while keeping its look
+func Default() *Variation {
+	return &Variation{
+		Crop:       0.2,
+		Flip:       true,
+		Hue:        30,
+		Brightness: 0.12,
+		Contrast:   0.15,
+		Texture:    0.3,
+	}
+}
+
+// Apply returns a varied copy of the background at the size
+// params:
+//   - img: Background image, left untouched
+//   - width, height: Size of the returned image
+//   - rnd: Random generator
+//
+// return: Varied background
+func (v *Variation) Apply(img image.Image, width, height int, rnd *rand.Rand) *image.NRGBA {
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(out, out.Bounds(), img, v.cropRect(img.Bounds(), width, height, rnd), draw.Src, nil)
+
+	if v.Flip && rnd.Intn(2) == 0 {
+		flip(out)
+	}
+	if v.Hue > 0 {
+		hue := math.Min(float64(v.Hue), 180)
+		canvas.ShiftHue(out, (rnd.Float64()*2-1)*hue*math.Pi/180)
+	}
+	if v.Brightness > 0 || v.Contrast > 0 {
+		bright := (rnd.Float64()*2 - 1) * helper.ClampUnit(v.Brightness) * 255
+		contrast := 1 + (rnd.Float64()*2-1)*helper.ClampUnit(v.Contrast)
+		adjust(out, bright, contrast)
+	}
+	if v.Texture > 0 {
+		texture(out, helper.ClampUnit(v.Texture), rnd)
+	}
+	return out
+}
+
+// cropRect returns a random part of the source with the aspect of the target,
+// it covers at least 1 - Crop of the largest such part
+func (v *Variation) cropRect(b image.Rectangle, width, height int, rnd *rand.Rand) image.Rectangle {
+	// the largest part of the source with the aspect of the target
+	w, h := float64(b.Dx()), float64(b.Dy())
+	if w/h > float64(width)/float64(height) {
+		w = h * float64(width) / float64(height)
+	} else {
+		h = w * float64(height) / float64(width)
+	}
+
+	k := 1 - rnd.Float64()*math.Max(0, math.Min(v.Crop, 0.5))
+	w, h = math.Max(w*k, 1), math.Max(h*k, 1)
+	x := b.Min.X + int(rnd.Float64()*(float64(b.Dx())-w))
+	y := b.Min.Y + int(rnd.Float64()*(float64(b.Dy())-h))
+	return image.Rect(x, y, x+int(w), y+int(h))
+}
+
+// flip mirrors the image horizontally
+func flip(img *image.NRGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for l, r := b.Min.X, b.Max.X-1; l < r; l, r = l+1, r-1 {
+			i, j := img.PixOffset(l, y), img.PixOffset(r, y)
+			for c := 0; c < 4; c++ {
+				img.Pix[i+c], img.Pix[j+c] = img.Pix[j+c], img.Pix[i+c]
+			}
+		}
+	}
+}
+
+// adjust adds the brightness in levels and scales the contrast around the middle gray
+func adjust(img *image.NRGBA, bright, contrast float64) {
+	canvas.EachPixel(img, func(_, _, o int) {
+		for c := 0; c < 3; c++ {
+			img.Pix[o+c] = helper.ClampUint8((float64(img.Pix[o+c])-128)*contrast + 128 + bright)
+		}
+	})
+}
+
+// texture lays a smooth value noise of random scale over the image, with a little grain
+func texture(img *image.NRGBA, strength float64, rnd *rand.Rand) {
+	b := img.Bounds()
+	cell := 4 + rnd.Intn(12)
+	gw, gh := b.Dx()/cell+2, b.Dy()/cell+2
+	grid := make([]float64, gw*gh)
+	for i := range grid {
+		grid[i] = rnd.Float64()*2 - 1
+	}
+
+	amp, grain := 24*strength, 6*strength
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			fx, fy := float64(x)/float64(cell), float64(y)/float64(cell)
+			gx, gy := int(fx), int(fy)
+			ax, ay := fx-float64(gx), fy-float64(gy)
+			top := grid[gy*gw+gx]*(1-ax) + grid[gy*gw+gx+1]*ax
+			bottom := grid[(gy+1)*gw+gx]*(1-ax) + grid[(gy+1)*gw+gx+1]*ax
+			v := (top*(1-ay)+bottom*ay)*amp + (rnd.Float64()*2-1)*grain
+
+			o := img.PixOffset(b.Min.X+x, b.Min.Y+y)
+			for c := 0; c < 3; c++ {
+				img.Pix[o+c] = helper.ClampUint8(float64(img.Pix[o+c]) + v)
+			}
+		}
+	}
+}
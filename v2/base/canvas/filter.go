@@ -0,0 +1,464 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package canvas
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"math/rand"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/base/random"
+	"golang.org/x/image/draw"
+)
+
+// GeometricFilterErr is returned by Generate when a master filter chain has a geometric filter
+var GeometricFilterErr = errors.New("geometric filters move the answer and cannot be applied to the master image")
+
+// Filter changes an image in place, the transparent pixels of thumbs and tiles stay transparent
+type Filter interface {
+	Apply(img *image.NRGBA, rnd *rand.Rand)
+}
+
+// Geometric is implemented by the filters that move pixels rather than change their colors, such as
+// NewWaveWarp and NewSwirl. The answer of a captcha is fixed before its master image is filtered, the
+// moved dots, gap or center would fail a correct answer, so the master filter chains reject them
+type Geometric interface {
+	Geometric()
+}
+
+// HasGeometric checks if a filter chain has a geometric filter
+func HasGeometric(filters []Filter) bool {
+	for _, f := range filters {
+		if _, ok := f.(Geometric); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterFunc adapts a function to the Filter interface
+type FilterFunc func(img *image.NRGBA, rnd *rand.Rand)
+
+// Apply calls f(img, rnd)
+func (f FilterFunc) Apply(img *image.NRGBA, rnd *rand.Rand) {
+	f(img, rnd)
+}
+
+// ApplyFilters applies the filters in order to a copy of the image
+// params:
+//   - img: Source image, left untouched
+//   - filters: Filter chain
+//
+// return:
+//   - image.Image: Filtered copy, or img itself when the chain is empty
+func ApplyFilters(img image.Image, filters []Filter) image.Image {
+	if len(filters) == 0 {
+		return img
+	}
+
+	b := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+
+	rnd := random.New()
+	for _, f := range filters {
+		if f != nil {
+			f.Apply(out, rnd)
+		}
+	}
+	return out
+}
+
+// gaussianBlur blurs with a separable gaussian kernel
+type gaussianBlur struct {
+	sigma float64
+}
+
+// NewGaussianBlur returns a filter blurring with a gaussian of the standard deviation in pixels
+func NewGaussianBlur(sigma float64) Filter {
+	return &gaussianBlur{sigma: math.Max(sigma, 0)}
+}
+
+// Apply .
+func (g *gaussianBlur) Apply(img *image.NRGBA, _ *rand.Rand) {
+	if g.sigma <= 0 {
+		return
+	}
+
+	p := premultiply(img)
+	BlurPlane(p, img.Bounds().Dx(), img.Bounds().Dy(), 4, g.sigma)
+	unpremultiply(p, img)
+}
+
+// BlurPlane blurs interleaved float channels in place with a separable gaussian kernel, the
+// values past the borders repeat the edge ones
+// params:
+//   - p: Values, w*h*channels of them in rows
+//   - w, h: Size of the plane
+//   - channels: Number of channels of every value
+//   - sigma: Standard deviation in pixels
+func BlurPlane(p []float64, w, h, channels int, sigma float64) {
+	if sigma <= 0 || w <= 0 || h <= 0 {
+		return
+	}
+
+	r := int(math.Ceil(sigma * 3))
+	kernel := make([]float64, 2*r+1)
+	sum := 0.0
+	for i := range kernel {
+		d := float64(i - r)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	tmp := make([]float64, len(p))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for c := 0; c < channels; c++ {
+				v := 0.0
+				for k, kv := range kernel {
+					sx := helper.ClampInt(x+k-r, 0, w-1)
+					v += p[(y*w+sx)*channels+c] * kv
+				}
+				tmp[(y*w+x)*channels+c] = v
+			}
+		}
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for c := 0; c < channels; c++ {
+				v := 0.0
+				for k, kv := range kernel {
+					sy := helper.ClampInt(y+k-r, 0, h-1)
+					v += tmp[(sy*w+x)*channels+c] * kv
+				}
+				p[(y*w+x)*channels+c] = v
+			}
+		}
+	}
+}
+
+// RadiusSigma returns the standard deviation of the gaussian as wide as a box blur of the radius,
+// the variances of the two match
+func RadiusSigma(r int) float64 {
+	if r <= 0 {
+		return 0
+	}
+	return math.Sqrt(float64(r*(r+1)) / 3)
+}
+
+// waveWarp moves the pixels along sine waves
+type waveWarp struct {
+	amplitude float64
+	period    float64
+}
+
+// NewWaveWarp returns a filter shifting rows and columns along sine waves of the amplitude and
+// period in pixels, the phases are random
+func NewWaveWarp(amplitude, period float64) Filter {
+	return &waveWarp{amplitude: math.Max(amplitude, 0), period: math.Max(period, 1)}
+}
+
+// Geometric .
+func (wv *waveWarp) Geometric() {}
+
+// Apply .
+func (wv *waveWarp) Apply(img *image.NRGBA, rnd *rand.Rand) {
+	if wv.amplitude <= 0 {
+		return
+	}
+
+	px, py := rnd.Float64()*2*math.Pi, rnd.Float64()*2*math.Pi
+	f := 2 * math.Pi / wv.period
+	remap(img, func(x, y float64) (float64, float64) {
+		return x + wv.amplitude*math.Sin(y*f+px), y + wv.amplitude*math.Sin(x*f+py)
+	})
+}
+
+// swirl turns the pixels around the center, the most in the middle
+type swirl struct {
+	strength float64
+}
+
+// NewSwirl returns a filter twisting the image around its center by up to strength turns of
+// a half circle, the direction is random
+func NewSwirl(strength float64) Filter {
+	return &swirl{strength: strength}
+}
+
+// Geometric .
+func (s *swirl) Geometric() {}
+
+// Apply .
+func (s *swirl) Apply(img *image.NRGBA, rnd *rand.Rand) {
+	if s.strength == 0 {
+		return
+	}
+
+	b := img.Bounds()
+	cx, cy := float64(b.Dx())/2, float64(b.Dy())/2
+	radius := math.Min(cx, cy)
+	turn := s.strength * math.Pi
+	if rnd.Intn(2) == 0 {
+		turn = -turn
+	}
+
+	remap(img, func(x, y float64) (float64, float64) {
+		dx, dy := x-cx, y-cy
+		d := math.Hypot(dx, dy)
+		if d >= radius {
+			return x, y
+		}
+		t := 1 - d/radius
+		a := turn * t * t
+		sin, cos := math.Sincos(a)
+		return cx + dx*cos - dy*sin, cy + dx*sin + dy*cos
+	})
+}
+
+// saltPepper sets random pixels to black or white
+type saltPepper struct {
+	density float64
+}
+
+// NewSaltPepper returns a filter setting the share of visible pixels given by density, from 0 to 1,
+// to black or white
+func NewSaltPepper(density float64) Filter {
+	return &saltPepper{density: helper.ClampUnit(density)}
+}
+
+// Apply .
+func (s *saltPepper) Apply(img *image.NRGBA, rnd *rand.Rand) {
+	if s.density <= 0 {
+		return
+	}
+
+	EachPixel(img, func(_, _, i int) {
+		if img.Pix[i+3] == 0 || rnd.Float64() >= s.density {
+			return
+		}
+		v := uint8(0)
+		if rnd.Intn(2) == 0 {
+			v = 0xff
+		}
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = v, v, v
+	})
+}
+
+// jpegArtifacts runs the colors through a lossy JPEG round trip
+type jpegArtifacts struct {
+	quality int
+}
+
+// NewJPEGArtifacts returns a filter adding the block artifacts of a JPEG of the quality, from 1 to 100,
+// the alpha is kept
+func NewJPEGArtifacts(quality int) Filter {
+	return &jpegArtifacts{quality: helper.ClampInt(quality, 1, 100)}
+}
+
+// Apply .
+func (j *jpegArtifacts) Apply(img *image.NRGBA, _ *rand.Rand) {
+	b := img.Bounds()
+	opaque := image.NewNRGBA(b)
+	draw.Draw(opaque, b, img, b.Min, draw.Src)
+	EachPixel(opaque, func(_, _, i int) {
+		opaque.Pix[i+3] = 0xff
+	})
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, opaque, &jpeg.Options{Quality: j.quality}); err != nil {
+		return
+	}
+	lossy, err := jpeg.Decode(&buf)
+	if err != nil {
+		return
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := lossy.At(x-b.Min.X, y-b.Min.Y).RGBA()
+			i := img.PixOffset(x, y)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2] = uint8(r>>8), uint8(g>>8), uint8(bl>>8)
+		}
+	}
+}
+
+// colorJitter changes brightness, contrast and saturation by random amounts
+type colorJitter struct {
+	brightness float64
+	contrast   float64
+	saturation float64
+}
+
+// NewColorJitter returns a filter changing the brightness, contrast and saturation by random
+// amounts up to the given shares, from 0 to 1
+func NewColorJitter(brightness, contrast, saturation float64) Filter {
+	return &colorJitter{
+		brightness: helper.ClampUnit(brightness),
+		contrast:   helper.ClampUnit(contrast),
+		saturation: helper.ClampUnit(saturation),
+	}
+}
+
+// Apply .
+func (cj *colorJitter) Apply(img *image.NRGBA, rnd *rand.Rand) {
+	bright := (rnd.Float64()*2 - 1) * cj.brightness * 255
+	contrast := 1 + (rnd.Float64()*2-1)*cj.contrast
+	saturation := 1 + (rnd.Float64()*2-1)*cj.saturation
+
+	EachPixel(img, func(_, _, i int) {
+		r, g, b := float64(img.Pix[i]), float64(img.Pix[i+1]), float64(img.Pix[i+2])
+		gray := 0.299*r + 0.587*g + 0.114*b
+		r = gray + (r-gray)*saturation
+		g = gray + (g-gray)*saturation
+		b = gray + (b-gray)*saturation
+
+		img.Pix[i] = helper.ClampUint8((r-128)*contrast + 128 + bright)
+		img.Pix[i+1] = helper.ClampUint8((g-128)*contrast + 128 + bright)
+		img.Pix[i+2] = helper.ClampUint8((b-128)*contrast + 128 + bright)
+	})
+}
+
+// vignette darkens the image towards its corners
+type vignette struct {
+	strength float64
+}
+
+// NewVignette returns a filter darkening the corners by the strength, from 0 to 1
+func NewVignette(strength float64) Filter {
+	return &vignette{strength: helper.ClampUnit(strength)}
+}
+
+// Apply .
+func (v *vignette) Apply(img *image.NRGBA, _ *rand.Rand) {
+	if v.strength <= 0 {
+		return
+	}
+
+	b := img.Bounds()
+	cx, cy := float64(b.Min.X)+float64(b.Dx())/2, float64(b.Min.Y)+float64(b.Dy())/2
+	maxD := math.Hypot(cx-float64(b.Min.X), cy-float64(b.Min.Y))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) / maxD
+			k := 1 - v.strength*d*d
+			i := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = helper.ClampUint8(float64(img.Pix[i+c]) * k)
+			}
+		}
+	}
+}
+
+// gridLines draws a grid over the visible pixels
+type gridLines struct {
+	spacing int
+	color   color.NRGBA
+	alpha   float64
+}
+
+// NewGridLines returns a filter drawing one pixel lines of the color every spacing pixels, with a
+// random offset, blended by alpha from 0 to 1
+func NewGridLines(spacing int, col color.Color, alpha float64) Filter {
+	return &gridLines{
+		spacing: helper.MaxInt(spacing, 2),
+		color:   color.NRGBAModel.Convert(col).(color.NRGBA),
+		alpha:   helper.ClampUnit(alpha),
+	}
+}
+
+// Apply .
+func (g *gridLines) Apply(img *image.NRGBA, rnd *rand.Rand) {
+	if g.alpha <= 0 {
+		return
+	}
+
+	ox, oy := rnd.Intn(g.spacing), rnd.Intn(g.spacing)
+	rgb := [3]float64{float64(g.color.R), float64(g.color.G), float64(g.color.B)}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if (x-b.Min.X+ox)%g.spacing != 0 && (y-b.Min.Y+oy)%g.spacing != 0 {
+				continue
+			}
+			i := img.PixOffset(x, y)
+			if img.Pix[i+3] == 0 {
+				continue
+			}
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = helper.ClampUint8(float64(img.Pix[i+c])*(1-g.alpha) + rgb[c]*g.alpha)
+			}
+		}
+	}
+}
+
+// remap replaces every pixel with the bilinear sample at the source position given by fn
+func remap(img *image.NRGBA, fn func(x, y float64) (float64, float64)) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	p := premultiply(img)
+	out := make([]float64, len(p))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx, sy := fn(float64(x), float64(y))
+			sx = math.Max(0, math.Min(sx, float64(w-1)))
+			sy = math.Max(0, math.Min(sy, float64(h-1)))
+
+			x0, y0 := int(sx), int(sy)
+			x1, y1 := helper.MinInt(x0+1, w-1), helper.MinInt(y0+1, h-1)
+			ax, ay := sx-float64(x0), sy-float64(y0)
+			for c := 0; c < 4; c++ {
+				top := p[(y0*w+x0)*4+c]*(1-ax) + p[(y0*w+x1)*4+c]*ax
+				bottom := p[(y1*w+x0)*4+c]*(1-ax) + p[(y1*w+x1)*4+c]*ax
+				out[(y*w+x)*4+c] = top*(1-ay) + bottom*ay
+			}
+		}
+	}
+	unpremultiply(out, img)
+}
+
+// premultiply returns the pixels with the colors multiplied by their alpha, row by row from the top left
+func premultiply(img *image.NRGBA) []float64 {
+	b := img.Bounds()
+	out := make([]float64, 0, b.Dx()*b.Dy()*4)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			a := float64(img.Pix[i+3]) / 255
+			out = append(out, float64(img.Pix[i])*a, float64(img.Pix[i+1])*a, float64(img.Pix[i+2])*a, float64(img.Pix[i+3]))
+		}
+	}
+	return out
+}
+
+// unpremultiply writes premultiplied pixels back to the image
+func unpremultiply(p []float64, img *image.NRGBA) {
+	b := img.Bounds()
+	k := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			a := p[k+3] / 255
+			if a > 0 {
+				img.Pix[i] = helper.ClampUint8(p[k] / a)
+				img.Pix[i+1] = helper.ClampUint8(p[k+1] / a)
+				img.Pix[i+2] = helper.ClampUint8(p[k+2] / a)
+			}
+			img.Pix[i+3] = helper.ClampUint8(p[k+3])
+			k += 4
+		}
+	}
+}
@@ -0,0 +1,191 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package canvas
+
+import (
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Font defines a font that text can be drawn with.
+// Implementations must be comparable and safe for concurrent use, they are used as cache keys.
+type Font interface {
+	// NewFace creates a face, the face is used by a single goroutine and closed after drawing
+	NewFace(size, dpi float64, hinting font.Hinting) (font.Face, error)
+	// HasGlyph reports whether the font has a glyph for r
+	HasGlyph(r rune) bool
+	// Kern returns the horizontal adjustment between r0 and r1
+	Kern(size, dpi float64, hinting font.Hinting, r0, r1 rune) fixed.Int26_6
+}
+
+var (
+	_ Font = trueTypeFont{}
+	_ Font = sfntFont{}
+)
+
+// NewTrueTypeFont wraps a freetype font
+func NewTrueTypeFont(f *truetype.Font) Font {
+	return trueTypeFont{f: f}
+}
+
+// NewSfntFont wraps an sfnt font, it supports TrueType and OpenType (CFF) outlines.
+// Variable fonts are drawn with their default instance
+func NewSfntFont(f *sfnt.Font) Font {
+	return sfntFont{f: f}
+}
+
+// ParseFonts parses TTF, OTF, TTC or OTC data, every font of a collection is returned
+func ParseFonts(data []byte) ([]Font, error) {
+	c, err := sfnt.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fonts := make([]Font, 0, c.NumFonts())
+	for i := 0; i < c.NumFonts(); i++ {
+		f, err := c.Font(i)
+		if err != nil {
+			return nil, err
+		}
+		fonts = append(fonts, NewSfntFont(f))
+	}
+
+	return fonts, nil
+}
+
+// AsTrueType returns the freetype font wrapped by f
+func AsTrueType(f Font) (*truetype.Font, bool) {
+	if tf, ok := f.(trueTypeFont); ok {
+		return tf.f, true
+	}
+	return nil, false
+}
+
+// PickFont returns the first font of the chain that has a glyph for every rune of str,
+// the first font is returned when none of them has
+func PickFont(chain []Font, str string) Font {
+	if len(chain) == 0 {
+		return nil
+	}
+
+	for _, f := range chain {
+		has := true
+		for _, r := range str {
+			if !f.HasGlyph(r) {
+				has = false
+				break
+			}
+		}
+		if has {
+			return f
+		}
+	}
+
+	return chain[0]
+}
+
+// trueTypeFont adapts *truetype.Font to Font
+type trueTypeFont struct {
+	f *truetype.Font
+}
+
+// NewFace .
+func (t trueTypeFont) NewFace(size, dpi float64, hinting font.Hinting) (font.Face, error) {
+	if t.f == nil {
+		return nil, FontEmptyErr
+	}
+
+	return truetype.NewFace(t.f, &truetype.Options{
+		Size:    size,
+		DPI:     dpi,
+		Hinting: hinting,
+	}), nil
+}
+
+// HasGlyph .
+func (t trueTypeFont) HasGlyph(r rune) bool {
+	return t.f != nil && t.f.Index(r) != 0
+}
+
+// Kern .
+func (t trueTypeFont) Kern(size, dpi float64, hinting font.Hinting, r0, r1 rune) fixed.Int26_6 {
+	if t.f == nil {
+		return 0
+	}
+
+	scale := fixed.Int26_6(size*dpi*64/72 + 0.5)
+	return t.f.Kern(scale, t.f.Index(r0), t.f.Index(r1))
+}
+
+// sfntBuffers pools the scratch buffers needed by sfnt lookups
+var sfntBuffers = sync.Pool{
+	New: func() interface{} {
+		return new(sfnt.Buffer)
+	},
+}
+
+// sfntFont adapts *sfnt.Font to Font
+type sfntFont struct {
+	f *sfnt.Font
+}
+
+// NewFace .
+func (s sfntFont) NewFace(size, dpi float64, hinting font.Hinting) (font.Face, error) {
+	if s.f == nil {
+		return nil, FontEmptyErr
+	}
+
+	return opentype.NewFace(s.f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     dpi,
+		Hinting: hinting,
+	})
+}
+
+// HasGlyph .
+func (s sfntFont) HasGlyph(r rune) bool {
+	if s.f == nil {
+		return false
+	}
+
+	buf := sfntBuffers.Get().(*sfnt.Buffer)
+	defer sfntBuffers.Put(buf)
+
+	index, err := s.f.GlyphIndex(buf, r)
+	return err == nil && index != 0
+}
+
+// Kern .
+func (s sfntFont) Kern(size, dpi float64, hinting font.Hinting, r0, r1 rune) fixed.Int26_6 {
+	if s.f == nil {
+		return 0
+	}
+
+	buf := sfntBuffers.Get().(*sfnt.Buffer)
+	defer sfntBuffers.Put(buf)
+
+	x0, err := s.f.GlyphIndex(buf, r0)
+	if err != nil || x0 == 0 {
+		return 0
+	}
+	x1, err := s.f.GlyphIndex(buf, r1)
+	if err != nil || x1 == 0 {
+		return 0
+	}
+
+	ppem := fixed.Int26_6(size*dpi*64/72 + 0.5)
+	k, err := s.f.Kern(buf, x0, x1, ppem, hinting)
+	if err != nil {
+		return 0
+	}
+	return k
+}
@@ -0,0 +1,242 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package canvas
+
+import (
+	"container/list"
+	"errors"
+	"image"
+	"sync"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultGlyphCacheMaxBytes is the default memory limit of a glyph cache
+const DefaultGlyphCacheMaxBytes int64 = 8 << 20
+
+// textHinting is the hinting of every drawn string
+const textHinting = font.HintingFull
+
+var FontEmptyErr = errors.New("font is empty")
+
+// GlyphCache keeps rasterized glyph alpha masks, so characters repeated across
+// captchas are composited instead of rasterized again.
+// Masks are keyed by font, size, DPI and rune, entries are evicted in
+// LRU order once the memory limit is exceeded.
+// A GlyphCache is safe for concurrent use.
+type GlyphCache struct {
+	mu        sync.Mutex
+	maxBytes  int64
+	usedBytes int64
+	ll        *list.List
+	items     map[glyphKey]*list.Element
+}
+
+// glyphKey identifies a rasterized glyph
+type glyphKey struct {
+	font Font
+	size int
+	dpi  int
+	r    rune
+}
+
+// glyph is a rasterized glyph placed relative to the dot it was drawn at
+type glyph struct {
+	key     glyphKey
+	mask    *image.Alpha
+	bounds  image.Rectangle
+	advance fixed.Int26_6
+}
+
+// NewGlyphCache creates a glyph cache
+// params:
+//   - maxBytes: Memory limit, values less than or equal to 0 use DefaultGlyphCacheMaxBytes
+//
+// return: GlyphCache instance
+func NewGlyphCache(maxBytes int64) *GlyphCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultGlyphCacheMaxBytes
+	}
+
+	return &GlyphCache{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[glyphKey]*list.Element),
+	}
+}
+
+// Len returns the number of cached glyphs
+func (g *GlyphCache) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.ll.Len()
+}
+
+// UsedBytes returns the memory held by the cached masks
+func (g *GlyphCache) UsedBytes() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.usedBytes
+}
+
+// DrawString draws a string on dst with cached glyphs, pt is the baseline origin
+// params:
+//   - dst: Destination image
+//   - params: String drawing parameters
+//   - pt: Baseline origin
+//
+// return: Error information
+func (g *GlyphCache) DrawString(dst draw.Image, params *DrawStringParams, pt fixed.Point26_6) error {
+	return drawText(dst, params, pt, g)
+}
+
+// get returns the cached glyph and marks it as recently used
+func (g *GlyphCache) get(key glyphKey) *glyph {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if el, ok := g.items[key]; ok {
+		g.ll.MoveToFront(el)
+		return el.Value.(*glyph)
+	}
+	return nil
+}
+
+// add stores the glyph and evicts the least recently used glyphs over the memory limit
+func (g *GlyphCache) add(gl *glyph) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.items[gl.key]; ok {
+		return
+	}
+
+	g.items[gl.key] = g.ll.PushFront(gl)
+	g.usedBytes += glyphBytes(gl)
+
+	for g.usedBytes > g.maxBytes {
+		el := g.ll.Back()
+		if el == nil {
+			break
+		}
+		old := el.Value.(*glyph)
+		g.ll.Remove(el)
+		delete(g.items, old.key)
+		g.usedBytes -= glyphBytes(old)
+	}
+}
+
+// glyphBytes returns the memory held by a glyph mask
+func glyphBytes(gl *glyph) int64 {
+	if gl.mask == nil {
+		return 0
+	}
+	return int64(len(gl.mask.Pix))
+}
+
+// drawText draws text with font faces, every grapheme cluster is drawn with the first font of
+// the chain that has all of its glyphs, glyphs are taken from the cache when it is set.
+// Text of a right-to-left script is laid out in visual order, glyphs are not shaped
+// so Arabic letters are drawn in their isolated forms
+func drawText(dst draw.Image, params *DrawStringParams, pt fixed.Point26_6, cache *GlyphCache) error {
+	chain := params.FontChain
+	if len(chain) == 0 {
+		if params.Font == nil {
+			return FontEmptyErr
+		}
+		chain = []Font{NewTrueTypeFont(params.Font)}
+	}
+
+	size := float64(params.Size)
+	dpi := float64(params.FontDPI)
+	src := image.NewUniform(params.Color)
+
+	faces := make(map[Font]font.Face)
+	defer func() {
+		for _, face := range faces {
+			_ = face.Close()
+		}
+	}()
+
+	clusters := helper.Graphemes(params.Text)
+	if helper.IsRTL(params.Text) {
+		for i, j := 0, len(clusters)-1; i < j; i, j = i+1, j-1 {
+			clusters[i], clusters[j] = clusters[j], clusters[i]
+		}
+	}
+
+	var prevFont Font
+	var prev rune
+	x := pt.X
+	y := pt.Y.Round()
+	for _, cluster := range clusters {
+		f := PickFont(chain, cluster)
+		for i, r := range cluster {
+			if i == 0 && prevFont == f {
+				x += f.Kern(size, dpi, textHinting, prev, r)
+				x = (x + 32) &^ 63
+			}
+
+			key := glyphKey{font: f, size: params.Size, dpi: params.FontDPI, r: r}
+			var gl *glyph
+			if cache != nil {
+				gl = cache.get(key)
+			}
+			if gl == nil {
+				face, ok := faces[f]
+				if !ok {
+					var err error
+					if face, err = f.NewFace(size, dpi, textHinting); err != nil {
+						return err
+					}
+					faces[f] = face
+				}
+
+				gl = rasterizeGlyph(face, key)
+				if cache != nil {
+					cache.add(gl)
+				}
+			}
+
+			if gl.mask != nil {
+				p := image.Point{X: x.Round(), Y: y}
+				draw.DrawMask(dst, gl.bounds.Add(p), src, image.Point{}, gl.mask, image.Point{}, draw.Over)
+			}
+
+			x += gl.advance
+			prev = r
+		}
+		prevFont = f
+	}
+
+	return nil
+}
+
+// rasterizeGlyph rasterizes a glyph at the origin and copies the mask out of the face buffer
+func rasterizeGlyph(face font.Face, key glyphKey) *glyph {
+	gl := &glyph{key: key}
+
+	dr, mask, maskp, advance, ok := face.Glyph(fixed.Point26_6{}, key.r)
+	if !ok {
+		return gl
+	}
+	gl.advance = advance
+
+	if dr.Empty() {
+		return gl
+	}
+
+	alpha := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	draw.Draw(alpha, alpha.Bounds(), mask, maskp, draw.Src)
+	gl.mask = alpha
+	gl.bounds = dr
+
+	return gl
+}
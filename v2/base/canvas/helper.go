@@ -9,6 +9,8 @@ package canvas
 import (
 	"image"
 	"math"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
 )
 
 // RotatePoint rotates a point's coordinates
@@ -68,3 +70,38 @@ func CalcResizedRect(src image.Rectangle, width int, height int, centerAlign boo
 
 	return dst
 }
+
+// EachPixel calls fn with the coordinates and the offset in Pix of every pixel of the image
+// params:
+//   - img: Image
+//   - fn: Called with x, y and the offset of the pixel
+func EachPixel(img *image.NRGBA, fn func(x, y, i int)) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			fn(x, y, img.PixOffset(x, y))
+		}
+	}
+}
+
+// ShiftHue rotates the hue of every visible pixel by the angle in radians, in the YIQ color space
+// params:
+//   - img: Image, changed in place
+//   - angle: Angle in radians
+func ShiftHue(img *image.NRGBA, angle float64) {
+	sin, cos := math.Sincos(angle)
+	EachPixel(img, func(_, _, i int) {
+		if img.Pix[i+3] == 0 {
+			return
+		}
+		r, g, b := float64(img.Pix[i]), float64(img.Pix[i+1]), float64(img.Pix[i+2])
+		y := 0.299*r + 0.587*g + 0.114*b
+		in := 0.596*r - 0.274*g - 0.322*b
+		q := 0.211*r - 0.523*g + 0.312*b
+		in, q = in*cos-q*sin, in*sin+q*cos
+
+		img.Pix[i] = helper.ClampUint8(y + 0.956*in + 0.621*q)
+		img.Pix[i+1] = helper.ClampUint8(y - 0.272*in - 0.647*q)
+		img.Pix[i+2] = helper.ClampUint8(y - 1.106*in + 1.703*q)
+	})
+}
@@ -13,7 +13,6 @@ import (
 
 	"github.com/golang/freetype"
 	"golang.org/x/image/draw"
-	"golang.org/x/image/font"
 	"golang.org/x/image/math/f64"
 	"golang.org/x/image/math/fixed"
 )
@@ -26,6 +25,7 @@ type NRGBA interface {
 	DrawString(params *DrawStringParams, pt fixed.Point26_6) error
 	CalcMarginBlankArea() *AreaRect
 	Rotate(angle int, overCrop bool)
+	Mirror()
 	Scale(zoomSize int, keepRatio, centerAlign bool)
 	CropCircle(x, y, radius int)
 	CropScaleCircle(x, y, radius int, zoomSize int)
@@ -64,6 +64,10 @@ func (n *nRGBA) Get() *image.NRGBA {
 
 // DrawString draws a string on the canvas
 func (n *nRGBA) DrawString(params *DrawStringParams, pt fixed.Point26_6) error {
+	if params.Cache != nil || len(params.FontChain) > 0 {
+		return drawText(n.Get(), params, pt, params.Cache)
+	}
+
 	dc := freetype.NewContext()
 	dc.SetDPI(float64(params.FontDPI))
 	dc.SetFont(params.Font)
@@ -71,7 +75,7 @@ func (n *nRGBA) DrawString(params *DrawStringParams, pt fixed.Point26_6) error {
 	dc.SetDst(n.Get())
 
 	dc.SetFontSize(float64(params.Size))
-	dc.SetHinting(font.HintingFull)
+	dc.SetHinting(textHinting)
 
 	fontColor := image.NewUniform(params.Color)
 	dc.SetSrc(fontColor)
@@ -263,6 +267,19 @@ func (n *nRGBA) Scale(zoomSize int, keepRatio, centerAlign bool) {
 	n.NRGBA = img
 }
 
+// Mirror flips the canvas horizontally
+func (n *nRGBA) Mirror() {
+	src := n.Get()
+	b := src.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			img.SetNRGBA(b.Dx()-1-x, y, src.NRGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	n.NRGBA = img
+}
+
 // SubImage captures a sub-image
 func (n *nRGBA) SubImage(r image.Rectangle) {
 	n.NRGBA = n.Get().SubImage(r).(*image.NRGBA)
@@ -12,7 +12,6 @@ import (
 	"math"
 
 	"github.com/golang/freetype"
-	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
 )
 
@@ -226,6 +225,10 @@ func (p *palette) CalcMarginBlankArea() *AreaRect {
 
 // DrawString draws a string on the canvas
 func (p *palette) DrawString(params *DrawStringParams, pt fixed.Point26_6) error {
+	if params.Cache != nil || len(params.FontChain) > 0 {
+		return drawText(p.Get(), params, pt, params.Cache)
+	}
+
 	dc := freetype.NewContext()
 	dc.SetDPI(float64(params.FontDPI))
 	dc.SetFont(params.Font)
@@ -233,7 +236,7 @@ func (p *palette) DrawString(params *DrawStringParams, pt fixed.Point26_6) error
 	dc.SetDst(p.Get())
 
 	dc.SetFontSize(float64(params.Size))
-	dc.SetHinting(font.HintingFull)
+	dc.SetHinting(textHinting)
 
 	fontColor := image.NewUniform(params.Color)
 	dc.SetSrc(fontColor)
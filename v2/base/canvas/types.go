@@ -51,4 +51,8 @@ type DrawStringParams struct {
 	FontDPI int
 	Text    string
 	Font    *truetype.Font
+	// FontChain is tried in order for every character, Font is ignored when it is set
+	FontChain []Font
+	// Cache draws the string with cached glyphs when it is set
+	Cache *GlyphCache
 }
@@ -0,0 +1,197 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package helper
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// graphemeKind classifies a rune for grapheme cluster segmentation
+type graphemeKind int
+
+const (
+	graphemeOther graphemeKind = iota
+	graphemeCR
+	graphemeLF
+	graphemeExtend
+	graphemeSpacingMark
+	graphemeZWJ
+	graphemeRegional
+	graphemeL
+	graphemeV
+	graphemeT
+	graphemeLV
+	graphemeLVT
+)
+
+// kindOfGrapheme returns the segmentation class of r, a simplified subset of UAX #29
+func kindOfGrapheme(r rune) graphemeKind {
+	switch {
+	case r == '\r':
+		return graphemeCR
+	case r == '\n':
+		return graphemeLF
+	case r == 0x200D:
+		return graphemeZWJ
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return graphemeRegional
+	case r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F:
+		// Emoji modifiers and tag characters
+		return graphemeExtend
+	case r == 0x0E33 || r == 0x0EB3:
+		// Thai and Lao SARA AM
+		return graphemeSpacingMark
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return graphemeL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return graphemeV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return graphemeT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return graphemeLV
+		}
+		return graphemeLVT
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), r == 0x200C:
+		return graphemeExtend
+	case unicode.Is(unicode.Mc, r):
+		return graphemeSpacingMark
+	}
+	return graphemeOther
+}
+
+// isGraphemeBoundary reports whether a cluster boundary lies between two runes
+// params:
+//   - prev: Kind of the previous rune
+//   - cur: Kind of the current rune
+//   - regionals: Number of regional indicators in a row before cur
+func isGraphemeBoundary(prev, cur graphemeKind, regionals int) bool {
+	switch {
+	case prev == graphemeCR && cur == graphemeLF:
+		return false
+	case prev == graphemeCR || prev == graphemeLF || cur == graphemeCR || cur == graphemeLF:
+		return true
+	case prev == graphemeL && (cur == graphemeL || cur == graphemeV || cur == graphemeLV || cur == graphemeLVT):
+		return false
+	case (prev == graphemeLV || prev == graphemeV) && (cur == graphemeV || cur == graphemeT):
+		return false
+	case (prev == graphemeLVT || prev == graphemeT) && cur == graphemeT:
+		return false
+	case cur == graphemeExtend || cur == graphemeZWJ || cur == graphemeSpacingMark:
+		return false
+	case prev == graphemeZWJ:
+		// Emoji ZWJ sequences
+		return false
+	case prev == graphemeRegional && cur == graphemeRegional:
+		return regionals%2 == 0
+	}
+	return true
+}
+
+// Graphemes splits a string into user-perceived characters (grapheme clusters),
+// combining marks, emoji modifiers and ZWJ sequences, flags and Hangul jamo stay together
+func Graphemes(str string) []string {
+	var clusters []string
+	start := 0
+	prev := graphemeOther
+	regionals := 0
+	for i, r := range str {
+		cur := kindOfGrapheme(r)
+		if i > 0 && isGraphemeBoundary(prev, cur, regionals) {
+			clusters = append(clusters, str[start:i])
+			start = i
+		}
+
+		if cur == graphemeRegional {
+			regionals++
+		} else {
+			regionals = 0
+		}
+		prev = cur
+	}
+
+	if start < len(str) {
+		clusters = append(clusters, str[start:])
+	}
+	return clusters
+}
+
+// LenGrapheme calculates the number of user-perceived characters of a string
+func LenGrapheme(str string) int {
+	return len(Graphemes(str))
+}
+
+// isWideRune reports whether r is East Asian wide or fullwidth, or an emoji presented as wide
+func isWideRune(r rune) bool {
+	switch {
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0x303E,
+		r >= 0x3041 && r <= 0x33FF,
+		r >= 0x3400 && r <= 0x4DBF,
+		r >= 0x4E00 && r <= 0x9FFF,
+		r >= 0xA000 && r <= 0xA4CF,
+		r >= 0xA960 && r <= 0xA97F,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F1E6 && r <= 0x1F1FF,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F680 && r <= 0x1F6FF,
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x1FA70 && r <= 0x1FAFF,
+		r >= 0x20000 && r <= 0x2FFFD,
+		r >= 0x30000 && r <= 0x3FFFD:
+		return true
+	}
+	return false
+}
+
+// GraphemeWidth returns the display width of a single grapheme cluster, 0, 1 or 2 cells
+func GraphemeWidth(cluster string) int {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	if r == utf8.RuneError {
+		return 0
+	}
+
+	switch kindOfGrapheme(r) {
+	case graphemeCR, graphemeLF, graphemeExtend, graphemeSpacingMark, graphemeZWJ:
+		return 0
+	}
+	if isWideRune(r) {
+		return 2
+	}
+
+	for _, next := range cluster {
+		if next == 0xFE0F {
+			// Emoji presentation selector
+			return 2
+		}
+	}
+	return 1
+}
+
+// DisplayWidth calculates the display width of a string in cells, wide characters take 2 cells
+func DisplayWidth(str string) int {
+	width := 0
+	for _, cluster := range Graphemes(str) {
+		width += GraphemeWidth(cluster)
+	}
+	return width
+}
+
+// IsRTL checks if a string contains characters of a right-to-left script
+func IsRTL(str string) bool {
+	for _, r := range str {
+		if unicode.In(r, unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko) {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,7 @@ import (
 	"image/png"
 	"os"
 	"path"
+	"time"
 )
 
 var (
@@ -20,6 +21,34 @@ var (
 	ImageMissingDataErr = errors.New("missing image data")
 )
 
+// EncodeHook is called after every encoding of an image data to bytes or base64
+//   - format: "jpeg" or "png"
+//   - size: Length of the encoded bytes or string
+//   - elapsed: Duration of the encoding
+type EncodeHook func(format string, size int, elapsed time.Duration, err error)
+
+// bytes encodes to bytes and reports it, a nil hook only encodes
+func (h EncodeHook) bytes(format string, encode func() ([]byte, error)) ([]byte, error) {
+	if h == nil {
+		return encode()
+	}
+	start := time.Now()
+	b, err := encode()
+	h(format, len(b), time.Since(start), err)
+	return b, err
+}
+
+// string encodes to a string and reports it, a nil hook only encodes
+func (h EncodeHook) string(format string, encode func() (string, error)) (string, error) {
+	if h == nil {
+		return encode()
+	}
+	start := time.Now()
+	str, err := encode()
+	h(format, len(str), time.Since(start), err)
+	return str, err
+}
+
 // saveToFile saves an image to a file
 func saveToFile(img image.Image, filepath string, isTransparent bool, quality int) error {
 	var file *os.File
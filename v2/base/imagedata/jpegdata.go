@@ -30,6 +30,7 @@ var _ JPEGImageData = (*jpegImageDta)(nil)
 // jpegImageDta struct for JPEG image data
 type jpegImageDta struct {
 	image image.Image
+	hook  EncodeHook
 }
 
 // NewJPEGImageData creates a new JPEG image data instance
@@ -39,6 +40,14 @@ func NewJPEGImageData(img image.Image) JPEGImageData {
 	}
 }
 
+// NewJPEGImageDataWithHook creates a new JPEG image data instance reporting its encodings to hook
+func NewJPEGImageDataWithHook(img image.Image, hook EncodeHook) JPEGImageData {
+	return &jpegImageDta{
+		image: img,
+		hook:  hook,
+	}
+}
+
 // Get retrieves the original image
 func (c *jpegImageDta) Get() image.Image {
 	return c.image
@@ -59,7 +68,7 @@ func (c *jpegImageDta) ToBytes() ([]byte, error) {
 		return []byte{}, ImageEmptyErr
 	}
 
-	return codec.EncodeJPEGToByte(c.image, option.QualityNone)
+	return c.hook.bytes("jpeg", func() ([]byte, error) { return codec.EncodeJPEGToByte(c.image, option.QualityNone) })
 }
 
 // ToBytesWithQuality converts the JPEG image to a byte array with specified quality
@@ -69,9 +78,9 @@ func (c *jpegImageDta) ToBytesWithQuality(imageQuality int) ([]byte, error) {
 	}
 
 	if imageQuality <= option.QualityNone && imageQuality >= option.QualityLevel5 {
-		return codec.EncodeJPEGToByte(c.image, imageQuality)
+		return c.hook.bytes("jpeg", func() ([]byte, error) { return codec.EncodeJPEGToByte(c.image, imageQuality) })
 	}
-	return codec.EncodeJPEGToByte(c.image, option.QualityNone)
+	return c.hook.bytes("jpeg", func() ([]byte, error) { return codec.EncodeJPEGToByte(c.image, option.QualityNone) })
 }
 
 // ToBase64Data converts the JPEG image to Base64 data (without prefix)
@@ -80,7 +89,7 @@ func (c *jpegImageDta) ToBase64Data() (string, error) {
 		return "", ImageEmptyErr
 	}
 
-	return codec.EncodeJPEGToBase64Data(c.image, option.QualityNone)
+	return c.hook.string("jpeg", func() (string, error) { return codec.EncodeJPEGToBase64Data(c.image, option.QualityNone) })
 }
 
 // ToBase64DataWithQuality converts the JPEG image to Base64 data with specified quality (without prefix)
@@ -90,9 +99,9 @@ func (c *jpegImageDta) ToBase64DataWithQuality(imageQuality int) (string, error)
 	}
 
 	if imageQuality <= option.QualityNone && imageQuality >= option.QualityLevel5 {
-		return codec.EncodeJPEGToBase64Data(c.image, imageQuality)
+		return c.hook.string("jpeg", func() (string, error) { return codec.EncodeJPEGToBase64Data(c.image, imageQuality) })
 	}
-	return codec.EncodeJPEGToBase64Data(c.image, option.QualityNone)
+	return c.hook.string("jpeg", func() (string, error) { return codec.EncodeJPEGToBase64Data(c.image, option.QualityNone) })
 }
 
 // ToBase64 converts the JPEG image to a Base64 string
@@ -101,7 +110,7 @@ func (c *jpegImageDta) ToBase64() (string, error) {
 		return "", ImageEmptyErr
 	}
 
-	return codec.EncodeJPEGToBase64(c.image, option.QualityNone)
+	return c.hook.string("jpeg", func() (string, error) { return codec.EncodeJPEGToBase64(c.image, option.QualityNone) })
 }
 
 // ToBase64WithQuality converts the JPEG image to a Base64 string with specified quality
@@ -111,7 +120,7 @@ func (c *jpegImageDta) ToBase64WithQuality(imageQuality int) (string, error) {
 	}
 
 	if imageQuality <= option.QualityNone && imageQuality >= option.QualityLevel5 {
-		return codec.EncodeJPEGToBase64(c.image, imageQuality)
+		return c.hook.string("jpeg", func() (string, error) { return codec.EncodeJPEGToBase64(c.image, imageQuality) })
 	}
-	return codec.EncodeJPEGToBase64(c.image, option.QualityNone)
+	return c.hook.string("jpeg", func() (string, error) { return codec.EncodeJPEGToBase64(c.image, option.QualityNone) })
 }
@@ -27,6 +27,7 @@ var _ PNGImageData = (*pngImageDta)(nil)
 // pngImageDta struct for PNG image data
 type pngImageDta struct {
 	image image.Image
+	hook  EncodeHook
 }
 
 // NewPNGImageData creates a new PNG image data instance
@@ -36,6 +37,14 @@ func NewPNGImageData(img image.Image) PNGImageData {
 	}
 }
 
+// NewPNGImageDataWithHook creates a new PNG image data instance reporting its encodings to hook
+func NewPNGImageDataWithHook(img image.Image, hook EncodeHook) PNGImageData {
+	return &pngImageDta{
+		image: img,
+		hook:  hook,
+	}
+}
+
 // Get retrieves the original image
 func (c *pngImageDta) Get() image.Image {
 	return c.image
@@ -55,7 +64,7 @@ func (c *pngImageDta) ToBytes() ([]byte, error) {
 	if c.image == nil {
 		return []byte{}, ImageEmptyErr
 	}
-	return codec.EncodePNGToByte(c.image)
+	return c.hook.bytes("png", func() ([]byte, error) { return codec.EncodePNGToByte(c.image) })
 }
 
 // ToBase64Data converts the PNG image to Base64 data (without prefix)
@@ -63,7 +72,7 @@ func (c *pngImageDta) ToBase64Data() (string, error) {
 	if c.image == nil {
 		return "", ImageEmptyErr
 	}
-	return codec.EncodePNGToBase64Data(c.image)
+	return c.hook.string("png", func() (string, error) { return codec.EncodePNGToBase64Data(c.image) })
 }
 
 // ToBase64 converts the PNG image to a Base64 string
@@ -71,5 +80,5 @@ func (c *pngImageDta) ToBase64() (string, error) {
 	if c.image == nil {
 		return "", ImageEmptyErr
 	}
-	return codec.EncodePNGToBase64(c.image)
+	return c.hook.string("png", func() (string, error) { return codec.EncodePNGToBase64(c.image) })
 }
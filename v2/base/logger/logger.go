@@ -8,8 +8,11 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
 const (
@@ -27,45 +30,199 @@ type Logger interface {
 	Debugf(format string, v ...interface{})
 }
 
+// Field is a structured key and value attached to the messages of a logger
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F returns a field
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// FieldLogger is a Logger that carries structured fields itself
+type FieldLogger interface {
+	Logger
+	With(fields ...Field) Logger
+}
+
+// With returns a logger adding the fields to every message of l,
+// loggers that are not FieldLoggers get them appended to the message as key=value
+func With(l Logger, fields ...Field) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	if fl, ok := l.(FieldLogger); ok {
+		return fl.With(fields...)
+	}
+	return &fieldLogger{l: l, fields: fields}
+}
+
+// New creates a text logger writing to stderr
 func New() Logger {
-	l := &logx{l: log.New(os.Stderr, "", log.Ldate|log.Lmicroseconds)}
-	return l
+	return NewWriter(os.Stderr)
+}
+
+// NewWriter creates a text logger writing to w, messages are colored only when w is a terminal
+// and the NO_COLOR environment variable is not set
+func NewWriter(w io.Writer) Logger {
+	return &logx{l: log.New(w, "", log.Ldate|log.Lmicroseconds), color: isTerminal(w)}
+}
+
+// NewNop creates a logger that discards every message
+func NewNop() Logger {
+	return nop{}
 }
 
-var _ Logger = (*logx)(nil)
+// defaultLogger holds the logger used where no other is set
+var defaultLogger atomic.Value
 
-var Logx = New()
+type holder struct {
+	l Logger
+}
+
+func init() {
+	defaultLogger.Store(holder{l: New()})
+}
+
+// Default returns the logger used where no other is set, a text logger writing to stderr unless replaced
+func Default() Logger {
+	return defaultLogger.Load().(holder).l
+}
+
+// SetDefault replaces the logger used where no other is set, nil restores the text logger writing to stderr
+func SetDefault(l Logger) {
+	if l == nil {
+		l = New()
+	}
+	defaultLogger.Store(holder{l: l})
+}
+
+// Logx forwards to the default logger
+//
+// Deprecated: As of 2.1.0, it will be removed, please use [Default] and [SetDefault].
+var Logx Logger = proxy{}
+
+var _ FieldLogger = (*logx)(nil)
 
 // logx .
 type logx struct {
-	l *log.Logger
+	l      *log.Logger
+	color  bool
+	fields []Field
 }
 
 // Infof .
 func (l *logx) Infof(format string, v ...interface{}) {
-	l.output("INFO GO-CAPTCHA: %s", fmt.Sprintf("\x1b[%dm"+format+"\x1b[0m", color_green, v))
+	l.output("INFO", color_green, format, v)
 }
 
 // Errorf .
 func (l *logx) Errorf(format string, v ...interface{}) {
-	l.output("ERROR GO-CAPTCHA: %s", fmt.Sprintf("\x1b[%dm"+format+"\x1b[0m", color_red, v))
+	l.output("ERROR", color_red, format, v)
 }
 
 // Warnf .
 func (l *logx) Warnf(format string, v ...interface{}) {
-	l.output("WARN GO-CAPTCHA: %s", fmt.Sprintf("\x1b[%dm"+format+"\x1b[0m", color_yellow, v))
+	l.output("WARN", color_yellow, format, v)
 }
 
 // Debugf .
 func (l *logx) Debugf(format string, v ...interface{}) {
-	l.output("DEBUG GO-CAPTCHA: %s", fmt.Sprintf("\x1b[%dm"+format+"\x1b[0m", color_blue, v))
+	l.output("DEBUG", color_blue, format, v)
+}
+
+// With .
+func (l *logx) With(fields ...Field) Logger {
+	nl := *l
+	nl.fields = append(append([]Field(nil), l.fields...), fields...)
+	return &nl
 }
 
 // output .
-func (l *logx) output(format string, v ...interface{}) {
-	if len(v) == 0 {
-		l.l.Print(format)
-		return
+func (l *logx) output(level string, color uint8, format string, v []interface{}) {
+	msg := fmt.Sprintf(format, v...) + formatFields(l.fields)
+	if l.color {
+		msg = fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, msg)
+	}
+	l.l.Print(level + " GO-CAPTCHA: " + msg)
+}
+
+// fieldLogger appends its fields to the messages of a logger without fields of its own
+type fieldLogger struct {
+	l      Logger
+	fields []Field
+}
+
+// Infof .
+func (f *fieldLogger) Infof(format string, v ...interface{}) {
+	f.l.Infof("%s", fmt.Sprintf(format, v...)+formatFields(f.fields))
+}
+
+// Errorf .
+func (f *fieldLogger) Errorf(format string, v ...interface{}) {
+	f.l.Errorf("%s", fmt.Sprintf(format, v...)+formatFields(f.fields))
+}
+
+// Warnf .
+func (f *fieldLogger) Warnf(format string, v ...interface{}) {
+	f.l.Warnf("%s", fmt.Sprintf(format, v...)+formatFields(f.fields))
+}
+
+// Debugf .
+func (f *fieldLogger) Debugf(format string, v ...interface{}) {
+	f.l.Debugf("%s", fmt.Sprintf(format, v...)+formatFields(f.fields))
+}
+
+// With .
+func (f *fieldLogger) With(fields ...Field) Logger {
+	return &fieldLogger{l: f.l, fields: append(append([]Field(nil), f.fields...), fields...)}
+}
+
+// nop discards every message
+type nop struct{}
+
+func (nop) Infof(string, ...interface{})  {}
+func (nop) Errorf(string, ...interface{}) {}
+func (nop) Warnf(string, ...interface{})  {}
+func (nop) Debugf(string, ...interface{}) {}
+func (n nop) With(...Field) Logger        { return n }
+
+// proxy forwards to the default logger at the time of every call
+type proxy struct{}
+
+func (proxy) Infof(format string, v ...interface{})  { Default().Infof(format, v...) }
+func (proxy) Errorf(format string, v ...interface{}) { Default().Errorf(format, v...) }
+func (proxy) Warnf(format string, v ...interface{})  { Default().Warnf(format, v...) }
+func (proxy) Debugf(format string, v ...interface{}) { Default().Debugf(format, v...) }
+
+// formatFields returns the fields as " key=value" pairs, values with spaces are quoted
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, f := range fields {
+		v := fmt.Sprint(f.Value)
+		if v == "" || strings.ContainsAny(v, " \t\"=") {
+			v = fmt.Sprintf("%q", v)
+		}
+		sb.WriteString(" " + f.Key + "=" + v)
+	}
+	return sb.String()
+}
+
+// isTerminal reports whether w is a terminal that takes color codes
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
 	}
-	l.l.Printf(format, v...)
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
 }
@@ -0,0 +1,58 @@
+//go:build go1.21
+
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+var _ FieldLogger = (*slogLogger)(nil)
+
+// slogLogger writes to a slog.Logger, fields become attributes
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlog adapts a slog.Logger, the fields of With become its attributes, nil uses slog.Default
+func NewSlog(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &slogLogger{l: l}
+}
+
+// Infof .
+func (s *slogLogger) Infof(format string, v ...interface{}) {
+	s.l.Info(fmt.Sprintf(format, v...))
+}
+
+// Errorf .
+func (s *slogLogger) Errorf(format string, v ...interface{}) {
+	s.l.Error(fmt.Sprintf(format, v...))
+}
+
+// Warnf .
+func (s *slogLogger) Warnf(format string, v ...interface{}) {
+	s.l.Warn(fmt.Sprintf(format, v...))
+}
+
+// Debugf .
+func (s *slogLogger) Debugf(format string, v ...interface{}) {
+	s.l.Debug(fmt.Sprintf(format, v...))
+}
+
+// With .
+func (s *slogLogger) With(fields ...Field) Logger {
+	args := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		args = append(args, slog.Any(f.Key, f.Value))
+	}
+	return &slogLogger{l: s.l.With(args...)}
+}
@@ -0,0 +1,255 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package observe
+
+import (
+	"bufio"
+	"expvar"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric types of the exposition format
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+	typeSummary = "summary"
+)
+
+// family is a metric with its labels
+type family struct {
+	name   string
+	typ    string
+	help   string
+	labels []string
+}
+
+var (
+	generateInFlight = &family{"gocaptcha_generate_in_flight", typeGauge, "Generations in progress.", []string{"kind", "mode"}}
+	generateTotal    = &family{"gocaptcha_generate_total", typeCounter, "Generations by result.", []string{"kind", "mode", "result"}}
+	generateDuration = &family{"gocaptcha_generate_duration_seconds", typeSummary, "Duration of the generations.", []string{"kind", "mode"}}
+	stageDuration    = &family{"gocaptcha_stage_duration_seconds", typeSummary, "Duration of the stages of the generations, encode included.", []string{"kind", "mode", "stage"}}
+	resourceTotal    = &family{"gocaptcha_resource_selected_total", typeCounter, "Resources selected by the generations.", []string{"kind", "type", "name"}}
+	encodeBytes      = &family{"gocaptcha_encode_bytes_total", typeCounter, "Bytes of the encoded images.", []string{"kind", "image", "format"}}
+	encodeErrors     = &family{"gocaptcha_encode_errors_total", typeCounter, "Failed encodings of images.", []string{"kind", "image", "format"}}
+	verifyTotal      = &family{"gocaptcha_verify_total", typeCounter, "Verifications by result.", []string{"kind", "result"}}
+
+	families = []*family{generateInFlight, generateTotal, generateDuration, stageDuration, resourceTotal, encodeBytes, encodeErrors, verifyTotal}
+)
+
+// sample is a series of a family
+type sample struct {
+	values []string
+	value  float64
+	count  uint64
+}
+
+var _ Observer = (*Metrics)(nil)
+var _ http.Handler = (*Metrics)(nil)
+
+// Metrics is an observer keeping counters and duration summaries of the events in memory,
+// it is published with expvar and written in the Prometheus text exposition format.
+// Metrics is safe for concurrent use.
+type Metrics struct {
+	mu     sync.Mutex
+	series map[*family]map[string]*sample
+}
+
+// NewMetrics creates a metrics observer
+// return: Metrics instance
+func NewMetrics() *Metrics {
+	return &Metrics{series: make(map[*family]map[string]*sample, len(families))}
+}
+
+// NewExpvar creates a metrics observer published as the expvar variable name,
+// like expvar.Publish it panics when the name is already used
+// params:
+//   - name: Expvar variable name, such as "gocaptcha"
+//
+// return: Metrics instance
+func NewExpvar(name string) *Metrics {
+	m := NewMetrics()
+	expvar.Publish(name, expvar.Func(m.Snapshot))
+	return m
+}
+
+// GenerateStart .
+func (m *Metrics) GenerateStart(e GenerateEvent) {
+	m.mu.Lock()
+	m.get(generateInFlight, e.Kind, e.Mode).value++
+	m.mu.Unlock()
+}
+
+// GenerateEnd .
+func (m *Metrics) GenerateEnd(e GenerateEvent) {
+	result := "ok"
+	if e.Err != nil {
+		result = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(generateInFlight, e.Kind, e.Mode).value--
+	m.get(generateTotal, e.Kind, e.Mode, result).value++
+	m.observe(m.get(generateDuration, e.Kind, e.Mode), e.Duration.Seconds())
+	for _, st := range e.Stages {
+		m.observe(m.get(stageDuration, e.Kind, e.Mode, st.Name), st.Duration.Seconds())
+	}
+}
+
+// ResourceSelected .
+func (m *Metrics) ResourceSelected(e ResourceEvent) {
+	m.mu.Lock()
+	m.get(resourceTotal, e.Kind, e.Type, e.Name).value++
+	m.mu.Unlock()
+}
+
+// Encoded .
+func (m *Metrics) Encoded(e EncodeEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e.Err != nil {
+		m.get(encodeErrors, e.Kind, e.Image, e.Format).value++
+		return
+	}
+	m.observe(m.get(stageDuration, e.Kind, e.Mode, StageEncode), e.Duration.Seconds())
+	m.get(encodeBytes, e.Kind, e.Image, e.Format).value += float64(e.Size)
+}
+
+// Verified .
+func (m *Metrics) Verified(e VerifyEvent) {
+	result := "fail"
+	if e.OK {
+		result = "pass"
+	}
+
+	m.mu.Lock()
+	m.get(verifyTotal, e.Kind, result).value++
+	m.mu.Unlock()
+}
+
+// Snapshot returns the metrics by name, then by labels joined as "k=v,k=v",
+// counters and gauges as numbers and summaries as {"sum", "count"}, it is the expvar value
+// return: Snapshot
+func (m *Metrics) Snapshot() interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]interface{}, len(m.series))
+	for _, f := range families {
+		series := m.series[f]
+		if len(series) == 0 {
+			continue
+		}
+		values := make(map[string]interface{}, len(series))
+		for _, s := range series {
+			pairs := make([]string, len(f.labels))
+			for i, l := range f.labels {
+				pairs[i] = l + "=" + s.values[i]
+			}
+			key := strings.Join(pairs, ",")
+			if f.typ == typeSummary {
+				values[key] = map[string]interface{}{"sum": s.value, "count": s.count}
+			} else {
+				values[key] = s.value
+			}
+		}
+		out[f.name] = values
+	}
+	return out
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+// params:
+//   - w: Writer
+//
+// return: Error information
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		series := m.series[f]
+		if len(series) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(series))
+		for k := range series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		bw.WriteString("# HELP " + f.name + " " + f.help + "\n")
+		bw.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		for _, k := range keys {
+			s := series[k]
+			labels := formatLabels(f.labels, s.values)
+			if f.typ == typeSummary {
+				bw.WriteString(f.name + "_sum" + labels + " " + formatFloat(s.value) + "\n")
+				bw.WriteString(f.name + "_count" + labels + " " + strconv.FormatUint(s.count, 10) + "\n")
+			} else {
+				bw.WriteString(f.name + labels + " " + formatFloat(s.value) + "\n")
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format, for a /metrics endpoint
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+// get returns the series of a family with the label values, m.mu must be held
+func (m *Metrics) get(f *family, values ...string) *sample {
+	series := m.series[f]
+	if series == nil {
+		series = make(map[string]*sample)
+		m.series[f] = series
+	}
+
+	key := strings.Join(values, "\xff")
+	s := series[key]
+	if s == nil {
+		s = &sample{values: values}
+		series[key] = s
+	}
+	return s
+}
+
+// observe adds a value to a summary, m.mu must be held
+func (m *Metrics) observe(s *sample, v float64) {
+	s.value += v
+	s.count++
+}
+
+// formatLabels returns the labels as {k="v",...}, values are escaped
+func formatLabels(names, values []string) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(n + `="` + labelEscaper.Replace(values[i]) + `"`)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat .
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,160 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package observe
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stage names of a generation
+const (
+	StageLayout = "layout"
+	StageMaster = "master"
+	StageThumb  = "thumb"
+	StageEncode = "encode"
+)
+
+// Stage is the time spent in one stage of a generation
+type Stage struct {
+	Name     string
+	Duration time.Duration
+}
+
+// GenerateEvent describes a generation, Stages, Duration and Err are only set when it ends
+type GenerateEvent struct {
+	Kind     string
+	Mode     string
+	Stages   []Stage
+	Duration time.Duration
+	Err      error
+}
+
+// ResourceEvent describes a resource selected by a generation
+//   - Type: "background", "font", "shape", "tile" or "image"
+//   - Name: Name of the resource, resources without one are named after their index as "background#2",
+//     generated ones as "generator#0"
+type ResourceEvent struct {
+	Kind string
+	Mode string
+	Type string
+	Name string
+}
+
+// EncodeEvent describes the encoding of a generated image, images are encoded when the caller asks for
+// their bytes, so it comes after the end of the generation and once per encoding
+//   - Image: "master" or "thumb", the slide tile is the thumb
+//   - Format: "jpeg" or "png"
+//   - Size: Length of the encoded bytes or string
+type EncodeEvent struct {
+	Kind     string
+	Mode     string
+	Image    string
+	Format   string
+	Size     int
+	Duration time.Duration
+	Err      error
+}
+
+// VerifyEvent describes the outcome of a verification
+type VerifyEvent struct {
+	Kind string
+	OK   bool
+}
+
+// Observer receives the events of generation and verification, its methods are called
+// synchronously from the goroutines that generate, so they must be quick and safe for concurrent use
+type Observer interface {
+	GenerateStart(e GenerateEvent)
+	GenerateEnd(e GenerateEvent)
+	ResourceSelected(e ResourceEvent)
+	Encoded(e EncodeEvent)
+	Verified(e VerifyEvent)
+}
+
+var _ Observer = Nop{}
+
+// Nop ignores every event, embed it to implement only some methods of Observer
+type Nop struct{}
+
+func (Nop) GenerateStart(GenerateEvent)    {}
+func (Nop) GenerateEnd(GenerateEvent)      {}
+func (Nop) ResourceSelected(ResourceEvent) {}
+func (Nop) Encoded(EncodeEvent)            {}
+func (Nop) Verified(VerifyEvent)           {}
+
+// Multi returns an observer passing every event to each of observers in order
+func Multi(observers ...Observer) Observer {
+	return multi(append([]Observer(nil), observers...))
+}
+
+type multi []Observer
+
+func (m multi) GenerateStart(e GenerateEvent) {
+	for _, o := range m {
+		o.GenerateStart(e)
+	}
+}
+
+func (m multi) GenerateEnd(e GenerateEvent) {
+	for _, o := range m {
+		o.GenerateEnd(e)
+	}
+}
+
+func (m multi) ResourceSelected(e ResourceEvent) {
+	for _, o := range m {
+		o.ResourceSelected(e)
+	}
+}
+
+func (m multi) Encoded(e EncodeEvent) {
+	for _, o := range m {
+		o.Encoded(e)
+	}
+}
+
+func (m multi) Verified(e VerifyEvent) {
+	for _, o := range m {
+		o.Verified(e)
+	}
+}
+
+// defaultObserver holds the observer used where no other is set
+var defaultObserver atomic.Value
+
+type holder struct {
+	o Observer
+}
+
+func init() {
+	defaultObserver.Store(holder{o: Nop{}})
+}
+
+// Default returns the observer used where no other is set, Nop unless replaced
+func Default() Observer {
+	return defaultObserver.Load().(holder).o
+}
+
+// SetDefault replaces the observer used where no other is set, nil restores Nop
+func SetDefault(o Observer) {
+	if o == nil {
+		o = Nop{}
+	}
+	defaultObserver.Store(holder{o: o})
+}
+
+// Verified reports the outcome of a verification to the default observer
+// params:
+//   - kind: "click", "slide" or "rotate"
+//   - ok: Outcome of the verification
+//
+// return: ok
+func Verified(kind string, ok bool) bool {
+	Default().Verified(VerifyEvent{Kind: kind, OK: ok})
+	return ok
+}
@@ -0,0 +1,106 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package observe
+
+import (
+	"fmt"
+	"time"
+)
+
+// Recorder reports the events of one generation to an observer and measures its stages,
+// it is used by a single goroutine
+type Recorder struct {
+	o      Observer
+	kind   string
+	mode   string
+	start  time.Time
+	last   time.Time
+	stages []Stage
+}
+
+// Start reports the start of a generation and returns its recorder
+// params:
+//   - o: Observer, nil uses Default
+//   - kind: Kind of captcha
+//   - mode: Mode of the captcha, empty for kinds without modes
+//
+// return: Recorder
+func Start(o Observer, kind, mode string) *Recorder {
+	if o == nil {
+		o = Default()
+	}
+	now := time.Now()
+	r := &Recorder{o: o, kind: kind, mode: mode, start: now, last: now}
+	o.GenerateStart(GenerateEvent{Kind: kind, Mode: mode})
+	return r
+}
+
+// Mark adds the time since the previous mark to a stage, marking a stage again adds to it
+// params:
+//   - stage: Stage name
+func (r *Recorder) Mark(stage string) {
+	now := time.Now()
+	d := now.Sub(r.last)
+	r.last = now
+
+	for i := range r.stages {
+		if r.stages[i].Name == stage {
+			r.stages[i].Duration += d
+			return
+		}
+	}
+	r.stages = append(r.stages, Stage{Name: stage, Duration: d})
+}
+
+// Resource reports a selected resource
+// params:
+//   - typ: Resource type
+//   - name: Resource name
+func (r *Recorder) Resource(typ, name string) {
+	r.o.ResourceSelected(ResourceEvent{Kind: r.kind, Mode: r.mode, Type: typ, Name: name})
+}
+
+// ResourceIndex reports a selected resource named after its index
+// params:
+//   - typ: Resource type, also the prefix of the name
+//   - index: Index of the resource
+func (r *Recorder) ResourceIndex(typ string, index int) {
+	r.Resource(typ, fmt.Sprintf("%s#%d", typ, index))
+}
+
+// End reports the end of the generation
+// params:
+//   - err: Error of the generation
+func (r *Recorder) End(err error) {
+	r.o.GenerateEnd(GenerateEvent{
+		Kind:     r.kind,
+		Mode:     r.mode,
+		Stages:   r.stages,
+		Duration: time.Since(r.start),
+		Err:      err,
+	})
+}
+
+// EncodeHook returns a hook reporting the encodings of a generated image, it suits imagedata.EncodeHook
+// params:
+//   - image: "master" or "thumb"
+//
+// return: Hook
+func (r *Recorder) EncodeHook(image string) func(format string, size int, elapsed time.Duration, err error) {
+	o, kind, mode := r.o, r.kind, r.mode
+	return func(format string, size int, elapsed time.Duration, err error) {
+		o.Encoded(EncodeEvent{
+			Kind:     kind,
+			Mode:     mode,
+			Image:    image,
+			Format:   format,
+			Size:     size,
+			Duration: elapsed,
+			Err:      err,
+		})
+	}
+}
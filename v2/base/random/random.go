@@ -11,10 +11,16 @@ import (
 	"math"
 	"math/big"
 	"math/rand"
+	"sync"
 	"time"
 )
 
-var rnd = newRnd()
+// rnd is shared by all goroutines, *rand.Rand is not safe for concurrent use,
+// so every access must hold rndMu
+var (
+	rnd   = newRnd()
+	rndMu sync.Mutex
+)
 
 // newRnd .
 func newRnd() *rand.Rand {
@@ -23,19 +29,25 @@ func newRnd() *rand.Rand {
 	return rand.New(src)
 }
 
-// Rand63n generates a 64-bit random number
-func Rand63n(ri int64) {
-	rnd.Int63n(ri)
+// Rand63n generates a 64-bit random number in the interval [0, ri)
+func Rand63n(ri int64) int64 {
+	rndMu.Lock()
+	defer rndMu.Unlock()
+	return rnd.Int63n(ri)
 }
 
-// Rand31n generates a 32-bit random number
-func Rand31n(ri int32) {
-	rnd.Int31n(ri)
+// Rand31n generates a 32-bit random number in the interval [0, ri)
+func Rand31n(ri int32) int32 {
+	rndMu.Lock()
+	defer rndMu.Unlock()
+	return rnd.Int31n(ri)
 }
 
 // Perm generates a random permutation
 func Perm(n int) []int {
-	return rand.Perm(n)
+	rndMu.Lock()
+	defer rndMu.Unlock()
+	return rnd.Perm(n)
 }
 
 // RandInt generates a safe random number in the interval [n, m]
@@ -54,3 +66,8 @@ func RandInt(min, max int) int {
 	result, _ := rand2.Int(rand2.Reader, big.NewInt(int64(max-min+1)))
 	return int(int64(min) + result.Int64())
 }
+
+// New creates a generator seeded from the shared one, for hot loops of a single goroutine
+func New() *rand.Rand {
+	return rand.New(rand.NewSource(Rand63n(math.MaxInt64)))
+}
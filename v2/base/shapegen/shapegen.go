@@ -0,0 +1,333 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package shapegen draws the shapes of the click shape mode procedurally, every call renders
+// the shape again with a random fill or stroke style and a slight geometric variation, so no
+// two drawings of a shape are the same image an attacker could match
+package shapegen
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"golang.org/x/image/vector"
+)
+
+// Style is the way a shape is painted
+type Style int
+
+const (
+	StyleFill   Style = iota // Filled shape
+	StyleStroke              // Outline of the shape
+	StyleDouble              // Outline around a smaller filled shape
+)
+
+// Shape renders a named shape
+type Shape interface {
+	// Name returns the human-readable name of the shape, such as "triangle" or "heart",
+	// it names the shape in the prompt and must be unique among the shapes of a captcha
+	Name() string
+	// Render draws the shape in the color c on a transparent square image of size pixels
+	Render(size int, c color.Color, rnd *rand.Rand) *image.NRGBA
+}
+
+// Defaults returns one shape of every kind with its default settings
+func Defaults() []Shape {
+	return []Shape{
+		NewStar(5),
+		NewPolygon(3),
+		NewPolygon(4),
+		NewPolygon(5),
+		NewPolygon(6),
+		NewHeart(),
+		NewArrow(),
+		NewCrescent(),
+		NewCross(),
+		NewRing(),
+	}
+}
+
+// point is a point in unit coordinates, the shapes fit the square from -1 to 1
+type point struct {
+	x, y float64
+}
+
+// outline is a shape made of closed contours, the contours after the first one are holes
+type outline struct {
+	name string
+	// styles are the styles a render picks from, shapes that are not star-shaped around
+	// the origin cannot be stroked by scaling their contour and only fill
+	styles   []Style
+	contours func(rnd *rand.Rand) [][]point
+}
+
+var allStyles = []Style{StyleFill, StyleStroke, StyleDouble}
+
+// Name .
+func (o *outline) Name() string {
+	return o.name
+}
+
+// Render .
+func (o *outline) Render(size int, c color.Color, rnd *rand.Rand) *image.NRGBA {
+	if size < 1 {
+		size = 1
+	}
+	if c == nil {
+		c = color.White
+	}
+
+	contours := o.contours(rnd)
+	contours = vary(contours, rnd)
+
+	// the rasterizer clamps the absolute winding, a contour running the other way cuts a hole
+	var paths [][]point
+	switch o.styles[rnd.Intn(len(o.styles))] {
+	case StyleStroke:
+		k := 0.55 + rnd.Float64()*0.13
+		paths = append(paths, contours[0], reverse(scale(contours[0], k)))
+	case StyleDouble:
+		k := 0.7 + rnd.Float64()*0.08
+		paths = append(paths, contours[0], reverse(scale(contours[0], k)), scale(contours[0], k-0.2))
+	default:
+		paths = append(paths, contours[0])
+		for _, hole := range contours[1:] {
+			paths = append(paths, reverse(hole))
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	half := float64(size) / 2
+	radius := half / 1.15
+	r := vector.NewRasterizer(size, size)
+	for _, path := range paths {
+		for i, p := range path {
+			x, y := float32(half+p.x*radius), float32(half+p.y*radius)
+			if i == 0 {
+				r.MoveTo(x, y)
+			} else {
+				r.LineTo(x, y)
+			}
+		}
+		r.ClosePath()
+	}
+	r.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{})
+	return img
+}
+
+// NewStar returns a star with the number of points, a 5-pointed star is named "star"
+// and the others "N-pointed star"
+func NewStar(points int) Shape {
+	if points < 3 {
+		points = 3
+	}
+	name := "star"
+	if points != 5 {
+		name = fmt.Sprintf("%d-pointed star", points)
+	}
+	return &outline{
+		name:   name,
+		styles: allStyles,
+		contours: func(rnd *rand.Rand) [][]point {
+			inner := 0.38 + rnd.Float64()*0.12
+			pts := make([]point, 0, points*2)
+			for i := 0; i < points*2; i++ {
+				d := 1.0
+				if i%2 == 1 {
+					d = inner
+				}
+				pts = append(pts, polar(-math.Pi/2+math.Pi*float64(i)/float64(points), d))
+			}
+			return [][]point{pts}
+		},
+	}
+}
+
+// polygonNames are the names of the regular polygons by number of sides
+var polygonNames = map[int]string{
+	3: "triangle",
+	4: "square",
+	5: "pentagon",
+	6: "hexagon",
+	7: "heptagon",
+	8: "octagon",
+}
+
+// NewPolygon returns a regular polygon with the number of sides, named "triangle", "square",
+// "pentagon", "hexagon", "heptagon", "octagon" or "N-sided polygon"
+func NewPolygon(sides int) Shape {
+	if sides < 3 {
+		sides = 3
+	}
+	name, ok := polygonNames[sides]
+	if !ok {
+		name = fmt.Sprintf("%d-sided polygon", sides)
+	}
+
+	// a vertex on top, or a flat top for an even number of sides
+	start := -math.Pi / 2
+	if sides%2 == 0 {
+		start += math.Pi / float64(sides)
+	}
+	return &outline{
+		name:   name,
+		styles: allStyles,
+		contours: func(rnd *rand.Rand) [][]point {
+			pts := make([]point, 0, sides)
+			for i := 0; i < sides; i++ {
+				pts = append(pts, polar(start+2*math.Pi*float64(i)/float64(sides), 1))
+			}
+			return [][]point{pts}
+		},
+	}
+}
+
+// NewHeart returns a heart named "heart"
+func NewHeart() Shape {
+	return &outline{
+		name:   "heart",
+		styles: allStyles,
+		contours: func(rnd *rand.Rand) [][]point {
+			pts := make([]point, 0, 64)
+			for i := 0; i < 64; i++ {
+				t := 2 * math.Pi * float64(i) / 64
+				x := 16 * math.Pow(math.Sin(t), 3)
+				y := 13*math.Cos(t) - 5*math.Cos(2*t) - 2*math.Cos(3*t) - math.Cos(4*t)
+				// the curve spans x from -16 to 16 and y from -17 to 12
+				pts = append(pts, point{x: x / 16, y: -(y + 2.5) / 15})
+			}
+			return [][]point{pts}
+		},
+	}
+}
+
+// NewArrow returns an arrow pointing right named "arrow"
+func NewArrow() Shape {
+	return &outline{
+		name:   "arrow",
+		styles: allStyles,
+		contours: func(rnd *rand.Rand) [][]point {
+			// the head starts left of the origin so that every point is seen from it
+			shaft := 0.2 + rnd.Float64()*0.08
+			head := 0.62 + rnd.Float64()*0.12
+			return [][]point{{
+				{-1, -shaft}, {-0.1, -shaft}, {-0.1, -head}, {1, 0},
+				{-0.1, head}, {-0.1, shaft}, {-1, shaft},
+			}}
+		},
+	}
+}
+
+// NewCrescent returns a crescent named "crescent"
+func NewCrescent() Shape {
+	return &outline{
+		name:   "crescent",
+		styles: []Style{StyleFill},
+		contours: func(rnd *rand.Rand) [][]point {
+			// the unit circle minus a circle of radius r centered at (d, 0)
+			d := 0.4 + rnd.Float64()*0.12
+			r := 0.82 + rnd.Float64()*0.06
+			ix := (1 - r*r + d*d) / (2 * d)
+			iy := math.Sqrt(1 - ix*ix)
+
+			outer := math.Atan2(iy, ix)
+			inner := math.Atan2(iy, ix-d)
+			pts := make([]point, 0, 64)
+			for i := 0; i <= 32; i++ {
+				pts = append(pts, polar(outer+(2*math.Pi-2*outer)*float64(i)/32, 1))
+			}
+			for i := 1; i < 32; i++ {
+				p := polar(-inner-(2*math.Pi-2*inner)*float64(i)/32, r)
+				pts = append(pts, point{x: p.x + d, y: p.y})
+			}
+			return [][]point{pts}
+		},
+	}
+}
+
+// NewCross returns a cross with arms of equal length named "cross"
+func NewCross() Shape {
+	return &outline{
+		name:   "cross",
+		styles: allStyles,
+		contours: func(rnd *rand.Rand) [][]point {
+			w := 0.3 + rnd.Float64()*0.1
+			return [][]point{{
+				{-w, -1}, {w, -1}, {w, -w}, {1, -w}, {1, w}, {w, w},
+				{w, 1}, {-w, 1}, {-w, w}, {-1, w}, {-1, -w}, {-w, -w},
+			}}
+		},
+	}
+}
+
+// NewRing returns a ring named "ring"
+func NewRing() Shape {
+	return &outline{
+		name:   "ring",
+		styles: []Style{StyleFill},
+		contours: func(rnd *rand.Rand) [][]point {
+			return [][]point{circle(1), circle(0.5 + rnd.Float64()*0.12)}
+		},
+	}
+}
+
+// vary stretches, rotates slightly and jitters the vertices of the contours, curves made of
+// many points are not jittered to stay smooth
+func vary(contours [][]point, rnd *rand.Rand) [][]point {
+	sx := 0.9 + rnd.Float64()*0.2
+	sy := 0.9 + rnd.Float64()*0.2
+	angle := (rnd.Float64() - 0.5) * 0.2
+	sin, cos := math.Sin(angle), math.Cos(angle)
+
+	varied := make([][]point, len(contours))
+	for i, contour := range contours {
+		varied[i] = make([]point, len(contour))
+		for j, p := range contour {
+			jitter := 1.0
+			if len(contour) <= 16 {
+				jitter += (rnd.Float64() - 0.5) * 0.08
+			}
+			x, y := p.x*sx*jitter, p.y*sy*jitter
+			varied[i][j] = point{x: x*cos - y*sin, y: x*sin + y*cos}
+		}
+	}
+	return varied
+}
+
+// polar returns the point at the angle and distance from the origin
+func polar(angle, d float64) point {
+	return point{x: math.Cos(angle) * d, y: math.Sin(angle) * d}
+}
+
+// circle returns a circle of radius r around the origin
+func circle(r float64) []point {
+	pts := make([]point, 0, 48)
+	for i := 0; i < 48; i++ {
+		pts = append(pts, polar(2*math.Pi*float64(i)/48, r))
+	}
+	return pts
+}
+
+// scale scales a contour toward the origin
+func scale(contour []point, k float64) []point {
+	scaled := make([]point, len(contour))
+	for i, p := range contour {
+		scaled[i] = point{x: p.x * k, y: p.y * k}
+	}
+	return scaled
+}
+
+// reverse returns a contour running the other way
+func reverse(contour []point) []point {
+	reversed := make([]point, len(contour))
+	for i, p := range contour {
+		reversed[len(contour)-1-i] = p
+	}
+	return reversed
+}
@@ -6,13 +6,23 @@
 
 package click
 
+import (
+	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/base/observe"
+)
+
 // Builder defines an interface for building captcha
+// A Builder is not safe for concurrent use, the Captcha instances it makes are
 type Builder interface {
 	SetOptions(opts ...Option)
 	SetResources(resources ...Resource)
+	SetLogger(l logger.Logger)
+	SetObserver(o observe.Observer)
 	Clear()
 	Make() Captcha
 	MakeShape() Captcha
+	MakeOddOneOut() Captcha
+	MakeCount() Captcha
 	// Deprecated: As of 2.1.0, it will be removed, please use [MakeShape].
 	MakeWithShape() Captcha
 }
@@ -24,6 +34,8 @@ var _ Builder = (*builder)(nil)
 type builder struct {
 	opts      []Option
 	resources []Resource
+	logger    logger.Logger
+	observer  observe.Observer
 }
 
 // NewBuilder creates a new Builder instance
@@ -64,11 +76,23 @@ func (b *builder) SetResources(resources ...Resource) {
 	}
 }
 
+// SetLogger sets the logger of the captchas made afterwards, nil uses the default logger, see logger.SetDefault
+func (b *builder) SetLogger(l logger.Logger) {
+	b.logger = l
+}
+
+// SetObserver sets the observer of the captchas made afterwards, nil uses the default observer, see observe.SetDefault
+func (b *builder) SetObserver(o observe.Observer) {
+	b.observer = o
+}
+
 // Make generates a text-mode captcha
 // return: Captcha instance
 func (b *builder) Make() Captcha {
 	// Create text-mode captcha
 	capt := newWithMode(ModeText)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
 
 	capt.setOptions(b.opts...)
 	capt.setResources(b.resources...)
@@ -79,6 +103,30 @@ func (b *builder) Make() Captcha {
 // return: Captcha instance
 func (b *builder) MakeShape() Captcha {
 	capt := newWithMode(ModeShape)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
+	capt.setOptions(b.opts...)
+	capt.setResources(b.resources...)
+	return capt
+}
+
+// MakeOddOneOut generates an odd-one-out captcha, it draws shapes when any is set, characters otherwise
+// return: Captcha instance
+func (b *builder) MakeOddOneOut() Captcha {
+	capt := newWithMode(ModeOddOneOut)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
+	capt.setOptions(b.opts...)
+	capt.setResources(b.resources...)
+	return capt
+}
+
+// MakeCount generates a counting captcha, it draws shapes when any is set, characters otherwise
+// return: Captcha instance
+func (b *builder) MakeCount() Captcha {
+	capt := newWithMode(ModeCount)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
 	capt.setOptions(b.opts...)
 	capt.setResources(b.resources...)
 	return capt
@@ -88,6 +136,8 @@ func (b *builder) MakeShape() Captcha {
 // return: Captcha instance
 func (b *builder) MakeWithShape() Captcha {
 	capt := newWithMode(ModeShape)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
 	capt.setOptions(b.opts...)
 	capt.setResources(b.resources...)
 	return capt
@@ -8,51 +8,97 @@ package click
 
 import (
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"math"
 	"math/rand"
 
+	"github.com/wenlng/go-captcha/v2/base/bggen"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
 	"github.com/wenlng/go-captcha/v2/base/helper"
 	"github.com/wenlng/go-captcha/v2/base/imagedata"
 	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/base/observe"
 	"github.com/wenlng/go-captcha/v2/base/option"
 	"github.com/wenlng/go-captcha/v2/base/randgen"
 	"github.com/wenlng/go-captcha/v2/base/random"
 )
 
 // Captcha defines the interface for captcha
+//
+// A Captcha is safe for concurrent use by multiple goroutines. Its options and
+// resources are copied when it is made and never change afterwards, the images and
+// fonts they hold are shared and must be left untouched, every call of Generate works
+// on its own dots and canvases. To reconfigure at runtime,
+// call With or WithResources, they return a new instance and leave the receiver untouched.
 type Captcha interface {
 	setOptions(opts ...Option)
 	setResources(resources ...Resource)
+	setLogger(l logger.Logger)
+	setObserver(o observe.Observer)
 	GetOptions() *Options
+	GetMode() Mode
 	Generate() (CaptchaData, error)
+	With(opts ...Option) Captcha
+	WithResources(resources ...Resource) Captcha
 }
 
 // Mode defines the mode of the captcha
 type Mode int
 
 const (
-	ModeText  Mode = iota // Text mode
-	ModeShape             // Shape mode
+	ModeText      Mode = iota // Text mode
+	ModeShape                 // Shape mode
+	ModeOddOneOut             // Odd-one-out mode, click the copy of a shape or character that is mirrored or turned
+	ModeCount                 // Counting mode, click every copy of the prompted shape or character
 )
 
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeText:
+		return "text"
+	case ModeShape:
+		return "shape"
+	case ModeOddOneOut:
+		return "odd-one-out"
+	case ModeCount:
+		return "count"
+	}
+	return "unknown"
+}
+
+// Ordered reports whether the dots of the mode are clicked in the order of the thumbnail and checked
+// one by one with Validate, the dots of the other modes are clicked in any order, see ValidateSet
+func (m Mode) Ordered() bool {
+	return m != ModeOddOneOut && m != ModeCount
+}
+
 var _ Captcha = (*captcha)(nil)
 
+// defaultBackgroundGenerators draw the master backgrounds when no background is set
+var defaultBackgroundGenerators = bggen.Defaults()
+
 var (
 	EmptyShapesErr          = errors.New("no shapes provided")
 	EmptyCharacterErr       = errors.New("no character provided")
+	EmptyFontErr            = errors.New("no font provided")
 	CharRangeLenErr         = errors.New("character length must be greater than rangeLen.Max")
 	ShapesRangeLenErr       = errors.New("total number of shapes must be greater than rangeLen.Max")
 	ShapesTypeErr           = errors.New("shape must be an image type")
 	EmptyBackgroundImageErr = errors.New("no background image")
 	ModeSupportErr          = errors.New("mode is not supported")
+	OddOneOutErr            = errors.New("no shape or character looks different when mirrored or turned")
+	DotsFitErr              = errors.New("the dots of rangeLen and rangeSize cannot fit in imageSize")
+	PromptColorErr          = errors.New("the color attribute cannot name shape images drawn in their original color")
 )
 
 // captcha is the concrete implementation of the Captcha interface
 type captcha struct {
 	version   string
 	logger    logger.Logger
+	observer  observe.Observer
 	drawImage DrawImage
 	opts      *Options
 	resources *Resources
@@ -67,7 +113,6 @@ type captcha struct {
 // return: captcha instance
 func newWithMode(mode Mode, opts ...Option) Captcha {
 	capt := &captcha{
-		logger:    logger.New(),
 		drawImage: NewDrawImage(),
 		mode:      mode,
 		opts:      NewOptions(),
@@ -92,17 +137,121 @@ func newWithMode(mode Mode, opts ...Option) Captcha {
 // setOptions sets the captcha options
 // opts: Options to set
 func (c *captcha) setOptions(opts ...Option) {
+	c.opts.logger = c.log()
 	for _, opt := range opts {
 		opt(c.opts)
 	}
+	c.preloadBackgrounds()
 }
 
 // setResources sets the captcha resources
 // res: Resources to set
 func (c *captcha) setResources(res ...Resource) {
+	c.resources.logger = c.log()
 	for _, resource := range res {
 		resource(c.resources)
 	}
+	c.preloadBackgrounds()
+}
+
+// setLogger sets the logger of the captcha, nil uses the default logger
+func (c *captcha) setLogger(l logger.Logger) {
+	c.logger = l
+}
+
+// setObserver sets the observer of the captcha, nil uses the default observer
+func (c *captcha) setObserver(o observe.Observer) {
+	c.observer = o
+}
+
+// log returns the logger of the captcha with its kind and mode as fields
+func (c *captcha) log() logger.Logger {
+	l := c.logger
+	if l == nil {
+		l = logger.Default()
+	}
+	return logger.With(l, logger.F("kind", "click"), logger.F("mode", c.mode.String()))
+}
+
+// preloadBackgrounds prepares the background cache for the configured image sizes, the master
+// backgrounds are left out with a background variation
+func (c *captcha) preloadBackgrounds() {
+	cache := c.opts.backgroundCache
+	if cache == nil {
+		return
+	}
+
+	if c.opts.imageSize != nil && c.opts.backgroundVariation == nil {
+		cache.Preload(c.resources.rangBackgrounds, c.opts.imageSize.Width, c.opts.imageSize.Height)
+	}
+	if c.opts.thumbImageSize != nil {
+		cache.Preload(c.resources.rangThumbBackgrounds, c.opts.thumbImageSize.Width, c.opts.thumbImageSize.Height)
+	}
+}
+
+// randBackground randomly selects a background, served from the background cache when it is set
+// params:
+//   - images: Background images
+//   - size: Image size
+//   - rec: Recorder of the generation
+//
+// return: Background image
+func (c *captcha) randBackground(images []image.Image, size *option.Size, rec *observe.Recorder) image.Image {
+	index := helper.RandIndex(len(images))
+	if index < 0 {
+		return nil
+	}
+	rec.ResourceIndex("thumb_background", index)
+
+	img := images[index]
+	if img == nil || c.opts.backgroundCache == nil {
+		return img
+	}
+
+	return c.opts.backgroundCache.Get(img, size.Width, size.Height)
+}
+
+// randMasterBackground randomly selects a master background among the images and the generators,
+// the generators of bggen.Defaults draw it when neither is set, images get the background variation
+// when it is set, otherwise they are served from the background cache
+// params:
+//   - size: Image size
+//   - rec: Recorder of the generation
+//
+// return: Background image
+func (c *captcha) randMasterBackground(size *option.Size, rec *observe.Recorder) image.Image {
+	images, generators := c.resources.rangBackgrounds, c.resources.backgroundGenerators
+	if len(images) == 0 && len(generators) == 0 {
+		generators = defaultBackgroundGenerators
+	}
+
+	i := random.RandInt(0, len(images)+len(generators)-1)
+	if i >= len(images) {
+		rec.Resource("background", fmt.Sprintf("generator#%d", i-len(images)))
+		img := generators[i-len(images)].Generate(size.Width, size.Height, random.New())
+		if c.opts.backgroundTracker != nil {
+			c.opts.backgroundTracker.Observe(img, c.log())
+		}
+		return img
+	}
+
+	rec.ResourceIndex("background", i)
+	img := images[i]
+	if img == nil {
+		return nil
+	}
+	if c.opts.backgroundTracker != nil {
+		c.opts.backgroundTracker.Observe(img, c.log())
+	}
+
+	// the variation crops the full-size source, the few candidates of the cache would leave it little to vary
+	if c.opts.backgroundVariation != nil {
+		return c.opts.backgroundVariation.Apply(img, size.Width, size.Height, random.New())
+	}
+	if c.opts.backgroundCache != nil {
+		img = c.opts.backgroundCache.Get(img, size.Width, size.Height)
+	}
+	return img
 }
 
 // GetOptions gets the captcha options
@@ -111,62 +260,167 @@ func (c *captcha) GetOptions() *Options {
 	return c.opts
 }
 
+// GetMode gets the captcha mode
+// return: Captcha mode
+func (c *captcha) GetMode() Mode {
+	return c.mode
+}
+
+// With creates a new captcha from a copy of the current options with opts applied
+// params:
+//   - opts: Options to apply on the copy
+//
+// return: New captcha instance, the receiver is not modified
+func (c *captcha) With(opts ...Option) Captcha {
+	capt := c.clone()
+	capt.setOptions(opts...)
+	return capt
+}
+
+// WithResources creates a new captcha from a copy of the current resources with resources applied
+// params:
+//   - resources: Resources to apply on the copy
+//
+// return: New captcha instance, the receiver is not modified
+func (c *captcha) WithResources(resources ...Resource) Captcha {
+	capt := c.clone()
+	capt.setResources(resources...)
+	return capt
+}
+
+// clone copies the captcha with its own options and resources
+// return: Copied captcha
+func (c *captcha) clone() *captcha {
+	return &captcha{
+		version:   c.version,
+		logger:    c.logger,
+		observer:  c.observer,
+		drawImage: c.drawImage,
+		mode:      c.mode,
+		opts:      c.opts.clone(),
+		resources: c.resources.clone(),
+	}
+}
+
 // Generate generates captcha data
 // returns:
 //   - CaptchaData: Generated captcha data
 //   - error: Error information
 func (c *captcha) Generate() (CaptchaData, error) {
-	if c.mode == ModeShape {
-		return c.generateWithShape()
+	rec := observe.Start(c.observer, "click", c.mode.String())
+
+	var data CaptchaData
+	var err error
+	switch c.mode {
+	case ModeShape:
+		data, err = c.generateWithShape(rec)
+	case ModeOddOneOut:
+		data, err = c.generateOddOneOut(rec)
+	case ModeCount:
+		data, err = c.generateCount(rec)
+	default:
+		data, err = c.generateWithText(rec)
 	}
 
-	return c.generateWithText()
+	rec.End(err)
+	return data, err
 }
 
 // generateWithShape generates captcha data for shape mode
+// params:
+//   - rec: Recorder of the generation
+//
 // returns:
 //   - CaptchaData: Generated captcha data
 //   - error: Error information
-func (c *captcha) generateWithShape() (CaptchaData, error) {
+func (c *captcha) generateWithShape(rec *observe.Recorder) (CaptchaData, error) {
 	if err := c.check(); err != nil {
 		return nil, err
 	}
 
-	shapes, err := c.genShapes()
-	if err != nil {
+	var shapes, colors []string
+	var sizes []int
+	var err error
+	if c.opts.promptAttributes != 0 {
+		shapes, colors, sizes = c.genAttributeShapes(random.RandInt(c.opts.rangeLen.Min, c.opts.rangeLen.Max))
+	} else if shapes, err = c.genShapes(); err != nil {
 		return nil, err
 	}
 
 	var dots, thumbDots, verifyDots map[int]*Dot
 	var verifyShapes []string
 	var masterImage, thumbImage image.Image
+	cache := c.newDrawCache(rec)
 
-	dots = c.genDots(c.opts.imageSize, c.opts.rangeSize, shapes, 10)
-	verifyDots, verifyShapes = c.rangeCheckDots(dots)
-	thumbDots = c.genDots(c.opts.thumbImageSize, c.opts.rangeThumbSize, verifyShapes, 0)
-
-	masterImage, err = c.genMasterImage(c.opts.imageSize, dots)
+	dots = c.genDots(c.opts.rangeSize, shapes, sizes)
+	for i, co := range colors {
+		dots[i].Color = co
+	}
+	rec.Mark(observe.StageLayout)
+	masterImage, err = c.genMasterImage(c.opts.imageSize, dots, cache, rec)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageMaster)
+
+	verifyDots, verifyShapes = c.rangeCheckDots(dots)
+	thumbDots = c.genDots(c.opts.rangeThumbSize, verifyShapes, c.thumbSizes(verifyDots))
+	if c.opts.promptAttributes&AttributeColor != 0 {
+		// the thumbnail shows the color to click
+		for i, dot := range verifyDots {
+			thumbDots[i].Color2 = dot.Color
+		}
+	}
+	rec.Mark(observe.StageLayout)
 
-	thumbImage, err = c.genThumbImage(c.opts.thumbImageSize, thumbDots)
+	thumbImage, err = c.genThumbImage(c.opts.thumbImageSize, thumbDots, cache, rec)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageThumb)
+
+	masterImage = canvas.ApplyFilters(masterImage, c.opts.masterFilters)
+	rec.Mark(observe.StageMaster)
+	thumbImage = canvas.ApplyFilters(thumbImage, c.opts.thumbFilters)
+	rec.Mark(observe.StageThumb)
 
 	return &CaptData{
 		dots:        verifyDots,
-		masterImage: imagedata.NewJPEGImageData(masterImage),
-		thumbImage:  imagedata.NewPNGImageData(thumbImage),
+		masterImage: imagedata.NewJPEGImageDataWithHook(masterImage, rec.EncodeHook("master")),
+		thumbImage:  imagedata.NewPNGImageDataWithHook(thumbImage, rec.EncodeHook("thumb")),
+		prompt:      c.genPrompt(verifyDots),
 	}, nil
 }
 
+// thumbSizes returns the thumbnail sizes of the verification dots when the size is named in
+// the prompt, the largest or smallest thumbnail size, nil otherwise
+// params:
+//   - dots: Map of verification dot data
+//
+// return: List of sizes
+func (c *captcha) thumbSizes(dots map[int]*Dot) []int {
+	if c.opts.promptAttributes&AttributeSize == 0 {
+		return nil
+	}
+
+	sizes := make([]int, len(dots))
+	for i := range sizes {
+		sizes[i] = c.opts.rangeThumbSize.Min
+		if c.isLarge(dots[i].Size) {
+			sizes[i] = c.opts.rangeThumbSize.Max
+		}
+	}
+	return sizes
+}
+
 // generateWithText generates captcha data for text mode
+// params:
+//   - rec: Recorder of the generation
+//
 // returns:
 //   - CaptchaData: Generated captcha data
 //   - error: Error information
-func (c *captcha) generateWithText() (CaptchaData, error) {
+func (c *captcha) generateWithText(rec *observe.Recorder) (CaptchaData, error) {
 	if err := c.check(); err != nil {
 		return nil, err
 	}
@@ -179,25 +433,36 @@ func (c *captcha) generateWithText() (CaptchaData, error) {
 	var dots, thumbDots, verifyDots map[int]*Dot
 	var verifyShapes []string
 	var masterImage, thumbImage image.Image
+	cache := c.newDrawCache(rec)
 
-	dots = c.genDots(c.opts.imageSize, c.opts.rangeSize, chars, 10)
-	verifyDots, verifyShapes = c.rangeCheckDots(dots)
-	thumbDots = c.genDots(c.opts.thumbImageSize, c.opts.rangeThumbSize, verifyShapes, 0)
-
-	masterImage, err = c.genMasterImage(c.opts.imageSize, dots)
+	dots = c.genDots(c.opts.rangeSize, chars, nil)
+	rec.Mark(observe.StageLayout)
+	masterImage, err = c.genMasterImage(c.opts.imageSize, dots, cache, rec)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageMaster)
 
-	thumbImage, err = c.genThumbImage(c.opts.thumbImageSize, thumbDots)
+	verifyDots, verifyShapes = c.rangeCheckDots(dots)
+	thumbDots = c.genDots(c.opts.rangeThumbSize, verifyShapes, nil)
+	rec.Mark(observe.StageLayout)
+
+	thumbImage, err = c.genThumbImage(c.opts.thumbImageSize, thumbDots, cache, rec)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageThumb)
+
+	masterImage = canvas.ApplyFilters(masterImage, c.opts.masterFilters)
+	rec.Mark(observe.StageMaster)
+	thumbImage = canvas.ApplyFilters(thumbImage, c.opts.thumbFilters)
+	rec.Mark(observe.StageThumb)
 
 	return &CaptData{
 		dots:        verifyDots,
-		masterImage: imagedata.NewJPEGImageData(masterImage),
-		thumbImage:  imagedata.NewPNGImageData(thumbImage),
+		masterImage: imagedata.NewJPEGImageDataWithHook(masterImage, rec.EncodeHook("master")),
+		thumbImage:  imagedata.NewPNGImageDataWithHook(thumbImage, rec.EncodeHook("thumb")),
+		prompt:      c.genPrompt(verifyDots),
 	}, nil
 }
 
@@ -227,22 +492,15 @@ func (c *captcha) genChars() ([]string, error) {
 	return chars, nil
 }
 
-// genDots generates an ordered list of dots
+// genDots generates an ordered list of dots, the dots of the master image are laid out by genMasterImage
 // params:
-//   - imageSize: Image size
 //   - size: Dot size range
 //   - values: List of values (characters or shapes)
-//   - padding: Padding
+//   - sizes: Sizes of the dots, nil picks them randomly in the size range
 //
 // return: Map of dot data
-func (c *captcha) genDots(imageSize *option.Size, size *option.RangeVal, values []string, padding int) map[int]*Dot {
+func (c *captcha) genDots(size *option.RangeVal, values []string, sizes []int) map[int]*Dot {
 	var dots = make(map[int]*Dot, len(values))
-	width := imageSize.Width
-	height := imageSize.Height
-	if padding > 0 {
-		width -= padding
-		height -= padding
-	}
 
 	length := len(values)
 	for i := 0; i < length; i++ {
@@ -253,11 +511,14 @@ func (c *captcha) genDots(imageSize *option.Size, size *option.RangeVal, values
 		randColor2 := randgen.RandHexColor(c.opts.rangeThumbColors)
 
 		randSize := random.RandInt(size.Min, size.Max)
+		if i < len(sizes) && sizes[i] > 0 {
+			randSize = sizes[i]
+		}
 		cHeight := randSize
 		cWidth := randSize
 
-		if c.mode == ModeText && helper.LenChineseChar(value) > 1 {
-			cWidth = randSize * helper.LenChineseChar(value)
+		if !c.useShapes() && helper.LenGrapheme(value) > 1 {
+			cWidth = randSize * helper.LenGrapheme(value)
 
 			if randAngle > 0 {
 				surplus := cWidth - randSize
@@ -269,19 +530,8 @@ func (c *captcha) genDots(imageSize *option.Size, size *option.RangeVal, values
 			}
 		}
 
-		dy := 10
-		w := width / length
-		rd := math.Abs(float64(w) - float64(cWidth))
-		xx := (i * w) + random.RandInt(0, int(math.Max(rd, 1)))
-		yy := random.RandInt(dy, height+cHeight)
-
-		x := int(math.Min(math.Max(float64(xx), float64(dy)), float64(width-dy-(padding*2))))
-		y := int(math.Min(math.Max(float64(yy), float64(cHeight+dy)), float64(height+(cHeight/2)-(padding*2))))
-
 		dots[i] = &Dot{
 			Index:  i,
-			X:      x,
-			Y:      y - cHeight,
 			Size:   randSize,
 			Width:  cWidth,
 			Height: cHeight,
@@ -290,7 +540,7 @@ func (c *captcha) genDots(imageSize *option.Size, size *option.RangeVal, values
 			Color2: randColor2,
 		}
 
-		if c.mode == ModeShape {
+		if c.useShapes() {
 			dots[i].Shape = value
 		} else {
 			dots[i].Text = value
@@ -303,33 +553,59 @@ func (c *captcha) genDots(imageSize *option.Size, size *option.RangeVal, values
 // check checks the captcha parameters
 // returns: Error information
 func (c *captcha) check() error {
-	if c.mode == ModeText {
+	if c.mode != ModeText && c.mode != ModeShape && c.mode != ModeOddOneOut && c.mode != ModeCount {
+		if len(c.resources.rangBackgrounds) == 0 {
+			return EmptyBackgroundImageErr
+		}
+		return ModeSupportErr
+	}
+	if canvas.HasGeometric(c.opts.masterFilters) {
+		return canvas.GeometricFilterErr
+	}
+	if err := c.checkFit(); err != nil {
+		return err
+	}
+
+	if !c.useShapes() {
 		if len(c.resources.chars) < c.opts.rangeLen.Max {
 			return CharRangeLenErr
 		}
-		return nil
-	} else if c.mode == ModeShape {
-		if len(c.resources.shapes) < c.opts.rangeLen.Max {
-			return ShapesRangeLenErr
-		}
-
-		for _, img := range c.resources.shapeMaps {
-			if img == nil {
-				return ShapesTypeErr
-			}
+		if len(c.resources.rangFonts)+len(c.resources.rangSfntFonts)+len(c.resources.fallbackFonts) == 0 {
+			return EmptyFontErr
 		}
-
 		return nil
 	}
 
-	if len(c.resources.rangBackgrounds) == 0 {
-		return EmptyBackgroundImageErr
+	if len(c.resources.shapes) < c.opts.rangeLen.Max {
+		return ShapesRangeLenErr
+	}
+	for _, img := range c.resources.shapeMaps {
+		if img == nil {
+			return ShapesTypeErr
+		}
 	}
+	// the shape images keep their own colors, only the generated shapes are drawn in the named one
+	if c.mode == ModeShape && c.opts.promptAttributes&AttributeColor != 0 &&
+		c.opts.useShapeOriginalColor && len(c.resources.shapeMaps) > 0 {
+		return PromptColorErr
+	}
+	return nil
+}
 
-	return ModeSupportErr
+// useShapes checks if the dots are shapes, the odd-one-out and counting modes draw shapes when any is set
+// return: Whether the dots are shapes rather than characters
+func (c *captcha) useShapes() bool {
+	switch c.mode {
+	case ModeShape:
+		return true
+	case ModeOddOneOut, ModeCount:
+		return len(c.resources.shapes) > 0
+	}
+	return false
 }
 
 // rangeCheckDots generates random verification dots
+// The verification dots are copies, so the dots drawn on the master image keep their own index
 // params:
 //   - dots: Map of dot data
 //
@@ -346,10 +622,10 @@ func (c *captcha) rangeCheckDots(dots map[int]*Dot) (map[int]*Dot, []string) {
 			break
 		}
 
-		dot := dots[value]
+		dot := *dots[value]
 		dot.Index = i
-		chkDots[i] = dot
-		if c.mode == ModeShape {
+		chkDots[i] = &dot
+		if c.useShapes() {
 			values = append(values, chkDots[i].Shape)
 		} else {
 			values = append(values, chkDots[i].Text)
@@ -358,15 +634,16 @@ func (c *captcha) rangeCheckDots(dots map[int]*Dot) (map[int]*Dot, []string) {
 	return chkDots, values
 }
 
-// genMasterImage generates the main captcha image
+// genMasterImage generates the main captcha image, the dots are laid out by placeDots
 // params:
 //   - size: Image size
-//   - dots: Map of dot data
+//   - dots: Map of dot data, X and Y are set
+//   - cache: Shapes and fonts shared by the dots
 //
 // returns:
 //   - image.Image: Generated image
 //   - error: Error information
-func (c *captcha) genMasterImage(size *option.Size, dots map[int]*Dot) (image.Image, error) {
+func (c *captcha) genMasterImage(size *option.Size, dots map[int]*Dot, cache *drawCache, rec *observe.Recorder) (image.Image, error) {
 	var drawDots = make([]*DrawDot, 0, len(dots))
 
 	for i := 0; i < len(dots); i++ {
@@ -380,28 +657,24 @@ func (c *captcha) genMasterImage(size *option.Size, dots map[int]*Dot) (image.Im
 			Angle:  dot.Angle,
 			Color:  dot.Color,
 			Size:   dot.Size,
+			Mirror: dot.Mirror,
 		}
 
-		if c.mode == ModeShape {
-			drawDot.DrawType = DrawTypeImage
-			if img, ok := c.resources.shapeMaps[dot.Shape]; ok {
-				drawDot.Image = img
-			}
-			drawDot.UseOriginalColor = c.opts.useShapeOriginalColor
-		} else {
-			drawDot.DrawType = DrawTypeString
-			drawDot.Text = dot.Text
-			drawDot.FontDPI = c.opts.fontDPI
-			drawDot.Font = randgen.RandFont(c.resources.rangFonts)
+		c.setDrawContent(drawDot, dot.Color, cache, rec)
+		if drawDot.DrawType == DrawTypeImage {
+			rec.Resource("shape", dot.Shape)
 		}
 
 		drawDots = append(drawDots, drawDot)
 	}
+	if err := c.placeDots(size, drawDots); err != nil {
+		return nil, err
+	}
 
 	return c.drawImage.DrawWithNRGBA(&DrawImageParams{
 		Width:          size.Width,
 		Height:         size.Height,
-		Background:     randgen.RandImage(c.resources.rangBackgrounds),
+		Background:     c.randMasterBackground(size, rec),
 		Alpha:          c.opts.imageAlpha,
 		FontHinting:    c.opts.fontHinting,
 		CaptchaDrawDot: drawDots,
@@ -409,6 +682,8 @@ func (c *captcha) genMasterImage(size *option.Size, dots map[int]*Dot) (image.Im
 		ShowShadow:  c.opts.displayShadow,
 		ShadowColor: c.opts.shadowColor,
 		ShadowPoint: c.opts.shadowPoint,
+
+		Perturbers: c.opts.perturbers,
 	})
 }
 
@@ -416,19 +691,26 @@ func (c *captcha) genMasterImage(size *option.Size, dots map[int]*Dot) (image.Im
 // params:
 //   - size: Image size
 //   - dots: Map of dot data
+//   - cache: Shapes and fonts shared by the dots
 //
 // returns:
 //   - image.Image: Generated thumbnail
 //   - error: Error information
-func (c *captcha) genThumbImage(size *option.Size, dots map[int]*Dot) (image.Image, error) {
+func (c *captcha) genThumbImage(size *option.Size, dots map[int]*Dot, cache *drawCache, rec *observe.Recorder) (image.Image, error) {
 	var drawDots = make([]*DrawDot, 0, len(dots))
 
+	rtl := c.isThumbRTL(dots)
 	width := size.Width / len(dots)
 	for i := 0; i < len(dots); i++ {
+		// Slots are filled in reading order, the first prompted dot is rightmost for RTL
 		dot := dots[i]
+		if rtl {
+			dot = dots[len(dots)-1-i]
+		}
+
 		length := 1
-		if c.mode == ModeText {
-			length = len(dot.Text)
+		if !c.useShapes() && helper.DisplayWidth(dot.Text) > 1 {
+			length = helper.DisplayWidth(dot.Text)
 		}
 
 		dx := int(math.Max(float64(width*i+width/dot.Width), 8))
@@ -444,19 +726,7 @@ func (c *captcha) genThumbImage(size *option.Size, dots map[int]*Dot) (image.Ima
 			Width:  dot.Width,
 			Height: dot.Height,
 		}
-
-		if c.mode == ModeShape {
-			drawDot.DrawType = DrawTypeImage
-			if img, ok := c.resources.shapeMaps[dot.Shape]; ok {
-				drawDot.Image = img
-			}
-			drawDot.UseOriginalColor = c.opts.useShapeOriginalColor
-		} else {
-			drawDot.DrawType = DrawTypeString
-			drawDot.Text = dot.Text
-			drawDot.FontDPI = c.opts.fontDPI
-			drawDot.Font = randgen.RandFont(c.resources.rangFonts)
-		}
+		c.setDrawContent(drawDot, dot.Color2, cache, rec)
 
 		drawDots = append(drawDots, drawDot)
 	}
@@ -472,7 +742,7 @@ func (c *captcha) genThumbImage(size *option.Size, dots map[int]*Dot) (image.Ima
 	}
 
 	if len(c.resources.rangThumbBackgrounds) > 0 {
-		params.Background = randgen.RandImage(c.resources.rangThumbBackgrounds)
+		params.Background = c.randBackground(c.resources.rangThumbBackgrounds, size, rec)
 	}
 
 	var mTextColors []color.Color
@@ -480,6 +750,12 @@ func (c *captcha) genThumbImage(size *option.Size, dots map[int]*Dot) (image.Ima
 		co, _ := helper.ParseHexColor(cStr)
 		mTextColors = append(mTextColors, co)
 	}
+	if c.opts.promptAttributes&AttributeColor != 0 {
+		for _, dot := range dots {
+			co, _ := helper.ParseHexColor(dot.Color2)
+			mTextColors = append(mTextColors, co)
+		}
+	}
 
 	var bgColors []color.Color
 	for _, co := range c.opts.rangeThumbBgColors {
@@ -493,6 +769,113 @@ func (c *captcha) genThumbImage(size *option.Size, dots map[int]*Dot) (image.Ima
 	return c.drawImage.DrawWithPalette(params, mTextColors, bgColors)
 }
 
+// drawCache holds what the dots of one generation share
+type drawCache struct {
+	// shapes holds the rendering of each generated shape, every copy of a shape looks the same
+	shapes map[string]image.Image
+	// fonts is the font chain of every character when set, otherwise each one picks a chain
+	fonts []canvas.Font
+}
+
+// newDrawCache creates the cache of a generation, the characters of the odd-one-out and counting
+// modes share one font chain so that their copies look the same
+// params:
+//   - rec: Recorder of the generation
+//
+// return: Draw cache
+func (c *captcha) newDrawCache(rec *observe.Recorder) *drawCache {
+	cache := &drawCache{shapes: make(map[string]image.Image)}
+	if (c.mode == ModeOddOneOut || c.mode == ModeCount) && !c.useShapes() {
+		cache.fonts = c.randFontChain(rec)
+	}
+	return cache
+}
+
+// setDrawContent sets the shape image or the text and fonts of a draw dot
+// params:
+//   - drawDot: Draw dot
+//   - hex: Color of a generated shape, used by WithUseShapeOriginalColor
+//   - cache: Shapes and fonts shared by the dots
+//   - rec: Recorder of the generation
+func (c *captcha) setDrawContent(drawDot *DrawDot, hex string, cache *drawCache, rec *observe.Recorder) {
+	dot := drawDot.Dot
+	if c.useShapes() {
+		drawDot.DrawType = DrawTypeImage
+		drawDot.Image = c.shapeImage(dot, hex, cache)
+		drawDot.UseOriginalColor = c.opts.useShapeOriginalColor
+		return
+	}
+
+	drawDot.DrawType = DrawTypeString
+	drawDot.Text = dot.Text
+	drawDot.FontDPI = c.opts.fontDPI
+	drawDot.GlyphCache = c.opts.glyphCache
+	drawDot.Fonts = cache.fonts
+	if drawDot.Fonts == nil {
+		drawDot.Fonts = c.randFontChain(rec)
+	}
+	drawDot.Font, _ = canvas.AsTrueType(drawDot.Fonts[0])
+}
+
+// shapeImage returns the image of the shape of a dot, a generated shape is rendered once per
+// generation, in the color of the dot with WithUseShapeOriginalColor
+// params:
+//   - dot: Dot data
+//   - hex: Color of the generated shape, used by WithUseShapeOriginalColor
+//   - cache: Shapes shared by the dots
+//
+// return: Shape image, nil when the shape is unknown
+func (c *captcha) shapeImage(dot *Dot, hex string, cache *drawCache) image.Image {
+	if img, ok := c.resources.shapeMaps[dot.Shape]; ok {
+		return img
+	}
+	s, ok := c.resources.shapeGenerators[dot.Shape]
+	if !ok {
+		return nil
+	}
+
+	key := dot.Shape
+	if c.opts.useShapeOriginalColor {
+		key += hex
+	}
+	if img, ok := cache.shapes[key]; ok {
+		return img
+	}
+
+	co, _ := helper.ParseHexColor(hex)
+	size := dot.Width
+	if dot.Height > size {
+		size = dot.Height
+	}
+	img := s.Render(size+10, co, random.New())
+	cache.shapes[key] = img
+	return img
+}
+
+// isThumbRTL checks if the thumbnail prompt is read from right to left
+// params:
+//   - dots: Map of thumbnail dot data
+//
+// return: Whether to lay out right to left
+func (c *captcha) isThumbRTL(dots map[int]*Dot) bool {
+	switch c.opts.textDirection {
+	case TextDirectionLTR:
+		return false
+	case TextDirectionRTL:
+		return true
+	}
+
+	if c.useShapes() {
+		return false
+	}
+	for _, dot := range dots {
+		if helper.IsRTL(dot.Text) {
+			return true
+		}
+	}
+	return false
+}
+
 // genRandShape generates a random shape array
 // params:
 //   - length: Number of shapes
@@ -527,6 +910,27 @@ func (c *captcha) genRandChar(length int) []string {
 	return strA
 }
 
+// randFontChain randomly selects a font and builds its fallback chain,
+// the other fonts follow the selected one and the fallback fonts come last
+// params:
+//   - rec: Recorder of the generation
+//
+// return: Font chain
+func (c *captcha) randFontChain(rec *observe.Recorder) []canvas.Font {
+	fonts := c.resources.fonts()
+	chain := make([]canvas.Font, 0, len(fonts)+len(c.resources.fallbackFonts))
+
+	index := helper.RandIndex(len(fonts))
+	if index >= 0 {
+		rec.ResourceIndex("font", index)
+		chain = append(chain, fonts[index])
+		chain = append(chain, fonts[:index]...)
+		chain = append(chain, fonts[index+1:]...)
+	}
+
+	return append(chain, c.resources.fallbackFonts...)
+}
+
 // randDistortWithLevel generates a random distortion level
 // params:
 //   - level: Distortion level
@@ -13,6 +13,7 @@ type CaptchaData interface {
 	GetData() map[int]*Dot
 	GetMasterImage() imagedata.JPEGImageData
 	GetThumbImage() imagedata.PNGImageData
+	GetPrompt() string
 }
 
 // CaptData is the concrete implementation of the CaptchaData interface
@@ -20,6 +21,7 @@ type CaptData struct {
 	dots        map[int]*Dot
 	masterImage imagedata.JPEGImageData
 	thumbImage  imagedata.PNGImageData
+	prompt      string
 }
 
 var _ CaptchaData = (*CaptData)(nil)
@@ -41,3 +43,9 @@ func (c CaptData) GetMasterImage() imagedata.JPEGImageData {
 func (c CaptData) GetThumbImage() imagedata.PNGImageData {
 	return c.thumbImage
 }
+
+// GetPrompt gets the prompt of the thumbnail as text, in the locale of WithPromptLocale
+// return: Prompt such as "Click the red triangle, then the large circle"
+func (c CaptData) GetPrompt() string {
+	return c.prompt
+}
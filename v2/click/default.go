@@ -7,6 +7,9 @@
 package click
 
 import (
+	"strings"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
 	"github.com/wenlng/go-captcha/v2/base/option"
 	"golang.org/x/image/font"
 )
@@ -36,9 +39,45 @@ var thumbColors = []string{
 // Default shadow color
 var shadowColor = "#101010"
 
+// Default glyph cache, shared by all captcha instances
+var defaultGlyphCache = canvas.NewGlyphCache(canvas.DefaultGlyphCacheMaxBytes)
+
 // Default character set
 var defaultChars = []string{"我", "是", "行", "为", "式", "验", "证", "码", "的", "随", "机", "文", "本", "种", "子"}
 
+// Default character sets by locale, the characters are picked to be hard to confuse with each other
+var localeChars = map[string][]string{
+	"zh": defaultChars,
+	"en": {"A", "B", "C", "D", "E", "F", "G", "H", "J", "K", "M", "N", "P", "R", "T", "W", "X", "Y"},
+	"ja": {"あ", "い", "う", "え", "お", "か", "き", "く", "さ", "す", "た", "な", "ぬ", "ほ", "も", "や"},
+	"ko": {"가", "나", "다", "라", "마", "바", "사", "아", "자", "차", "카", "타", "파", "하", "강"},
+	"ar": {"ب", "ج", "د", "ر", "س", "ش", "ص", "ط", "ع", "ف", "ق", "ك", "ل", "م", "ه"},
+	"he": {"א", "ב", "ג", "ד", "ה", "ו", "ז", "ח", "ט", "כ", "ל", "מ", "נ", "ס", "ש"},
+	"th": {"ก", "ข", "ค", "ง", "จ", "ช", "ด", "ต", "ท", "น", "บ", "ป", "ม", "ย", "ส"},
+	"ru": {"Б", "Г", "Д", "Ж", "З", "И", "Л", "П", "Ф", "Ц", "Ч", "Ш", "Щ", "Э", "Ю"},
+}
+
+// LocaleChars gets a copy of the default character set of a locale,
+// region and script subtags are ignored, "zh-CN" and "zh_TW" both give "zh"
+// params:
+//   - locale: Locale tag
+//
+// return:
+//   - []string: Character set
+//   - bool: Whether the locale is supported
+func LocaleChars(locale string) ([]string, bool) {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	chars, ok := localeChars[lang]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), chars...), true
+}
+
 // getDefaultColors gets the default color list
 // return: List of colors
 func getDefaultColors() []string {
@@ -69,6 +108,7 @@ func defaultOptions() Option {
 	return func(opts *Options) {
 		opts.fontDPI = 72
 		opts.fontHinting = font.HintingNone
+		opts.glyphCache = defaultGlyphCache
 
 		opts.rangeLen = &option.RangeVal{Min: 6, Max: 7}
 		opts.rangeAnglePos = []*option.RangeVal{
@@ -86,6 +126,8 @@ func defaultOptions() Option {
 		opts.shadowPoint = &option.Point{X: -1, Y: -1}
 		opts.imageSize = &option.Size{Width: 300, Height: 220}
 		opts.imageAlpha = 1
+		opts.dotSpacing = 4
+		opts.dotMargin = 6
 
 		opts.rangeVerifyLen = &option.RangeVal{Min: 2, Max: 4}
 		opts.disabledRangeVerifyLen = false
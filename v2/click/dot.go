@@ -10,6 +10,7 @@ import (
 	"image"
 
 	"github.com/golang/freetype/truetype"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
 )
 
 // Dot represents a single point (character or shape) in the captcha
@@ -25,6 +26,8 @@ type Dot struct {
 	Angle  int    `json:"angle"`
 	Color  string `json:"color"`
 	Color2 string `json:"color2"`
+	// Mirror is true for the mirrored dot of the odd-one-out mode
+	Mirror bool `json:"mirror,omitempty"`
 }
 
 // DrawDot represents the dot data used for drawing
@@ -42,6 +45,13 @@ type DrawDot struct {
 	Angle            int
 	Color            string
 	Color2           string
+	Mirror           bool
 	Font             *truetype.Font
+	Fonts            []canvas.Font
+	GlyphCache       *canvas.GlyphCache
 	DrawType         DrawType
+
+	// mask is the coverage of the text rendered when the dot was measured, drawing paints it
+	// rather than rendering the text again
+	mask canvas.NRGBA
 }
@@ -44,6 +44,7 @@ type DrawImageParams struct {
 	ShadowColor           string
 	ShadowPoint           *option.Point
 	ThumbDisturbAlpha     float32
+	Perturbers            []Perturber
 }
 
 // DrawImage defines the interface for drawing images
@@ -106,6 +107,10 @@ func (d *drawImage) DrawWithNRGBA(params *DrawImageParams) (image.Image, error)
 	draw.Draw(m.Get(), b, img, point, draw.Src)
 	draw.Draw(m.Get(), cvs.Bounds(), cvs, image.Point{}, draw.Over)
 	m.SubImage(image.Rect(0, 0, params.Width, params.Height))
+
+	if len(params.Perturbers) > 0 {
+		perturb(m.Get(), dots, params.Perturbers, random.New())
+	}
 	return m, nil
 }
 
@@ -133,6 +138,8 @@ func (d *drawImage) DrawWithPalette(params *DrawImageParams, tColors []color.Col
 	p = append(p, tColors...)
 	p = append(p, nBgColors...)
 
+	// the circles and slim lines are drawn under the text on transparent pixels, which the canvas
+	// filters leave alone, so they stay part of the drawing, the distortion is a filter
 	cvs := canvas.NewPalette(image.Rect(0, 0, params.Width, params.Height), p)
 	if params.BackgroundCirclesNum > 0 {
 		d.randomFillWithCircles(cvs, params.BackgroundCirclesNum, 1, nBgColors)
@@ -160,13 +167,15 @@ func (d *drawImage) DrawWithPalette(params *DrawImageParams, tColors []color.Col
 		} else {
 			pt := freetype.Pt(dot.X, dot.Y)
 			err = cvs.DrawString(&canvas.DrawStringParams{
-				Color:   cColor,
-				Size:    dot.Size,
-				Width:   dot.Width,
-				Height:  dot.Height,
-				FontDPI: dot.FontDPI,
-				Text:    dot.Text,
-				Font:    dot.Font,
+				Color:     cColor,
+				Size:      dot.Size,
+				Width:     dot.Width,
+				Height:    dot.Height,
+				FontDPI:   dot.FontDPI,
+				Text:      dot.Text,
+				Font:      dot.Font,
+				FontChain: dot.Fonts,
+				Cache:     dot.GlyphCache,
 			}, pt)
 		}
 
@@ -181,18 +190,13 @@ func (d *drawImage) DrawWithPalette(params *DrawImageParams, tColors []color.Col
 		m := canvas.CreateNRGBACanvas(b.Dx(), b.Dy(), true)
 		point := randgen.RangCutImagePos(params.Width, params.Height, img)
 		draw.Draw(m.Get(), b, img, point, draw.Src)
-		cvs.Distort(float64(random.RandInt(5, 10)), float64(random.RandInt(120, 200)))
-		draw.Draw(m.Get(), cvs.Bounds(), cvs, image.Point{}, draw.Over)
+		distorted := canvas.ApplyFilters(cvs, distortFilters(random.RandInt(120, 200)))
+		draw.Draw(m.Get(), cvs.Bounds(), distorted, image.Point{}, draw.Over)
 		rc := m.Get().SubImage(image.Rect(0, 0, params.Width, params.Height)).(*image.NRGBA)
 		return rc, nil
 	}
 
-	if params.BackgroundDistort > 0 {
-		cvs.Distort(float64(random.RandInt(5, 10)), float64(params.BackgroundDistort))
-	}
-
-	return cvs, nil
-
+	return canvas.ApplyFilters(cvs, distortFilters(params.BackgroundDistort)), nil
 }
 
 // DrawWithNRGBA2 draws the image using NRGBA format (enhanced)
@@ -230,6 +234,8 @@ func (d *drawImage) DrawWithNRGBA2(params *DrawImageParams, tColors []color.Colo
 		draw.Draw(ccvs.Get(), rc.Bounds(), rc, image.Point{}, draw.Over)
 	}
 
+	// the circles and slim lines are drawn on a transparent layer, which the canvas filters leave
+	// alone, so they stay part of the drawing, the distortion of the layer is a filter
 	cvs := canvas.NewPalette(image.Rect(0, 0, params.Width, params.Height), p)
 	if params.BackgroundCirclesNum > 0 {
 		d.randomFillWithCircles(cvs, params.BackgroundCirclesNum, 1, nBgColors)
@@ -237,9 +243,7 @@ func (d *drawImage) DrawWithNRGBA2(params *DrawImageParams, tColors []color.Colo
 	if params.BackgroundSlimLineNum > 0 {
 		d.randomDrawSlimLine(cvs, params.BackgroundSlimLineNum, nBgColors)
 	}
-	if params.BackgroundDistort > 0 {
-		cvs.Distort(float64(random.RandInt(5, 10)), float64(params.BackgroundDistort))
-	}
+	disturb := canvas.ApplyFilters(cvs, distortFilters(params.BackgroundDistort))
 
 	cvsBounds := cvs.Bounds()
 	width := cvsBounds.Dx() / len(dots)
@@ -287,12 +291,19 @@ func (d *drawImage) DrawWithNRGBA2(params *DrawImageParams, tColors []color.Colo
 		}
 	}
 
-	ncvs := canvas.NewNRGBA(image.Rect(0, 0, params.Width, params.Height), true)
-	draw.Draw(ncvs.Get(), cvs.Bounds(), cvs, image.Point{}, draw.Over)
-	draw.Draw(ccvs.Get(), ncvs.Bounds(), ncvs, image.Point{}, draw.Over)
+	draw.Draw(ccvs.Get(), disturb.Bounds(), disturb, image.Point{}, draw.Over)
 	return ccvs, nil
 }
 
+// distortFilters returns the filter chain distorting a thumb along sine waves of the period in pixels,
+// it is empty when the period is 0
+func distortFilters(period int) []canvas.Filter {
+	if period <= 0 {
+		return nil
+	}
+	return []canvas.Filter{canvas.NewWaveWarp(float64(random.RandInt(5, 10)), float64(period))}
+}
+
 // randomFillWithCircles draws circles randomly
 // params:
 //   - m: Palette canvas
@@ -350,16 +361,13 @@ func (d *drawImage) DrawDotImage(dot *DrawDot, params *DrawImageParams) (canvas.
 	cColor, _ := helper.ParseHexColor(dot.Color)
 	cColor.A = helper.FormatAlpha(params.Alpha)
 
-	var cImage image.Image
-	var err error
-	if dot.DrawType == DrawTypeImage {
-		cImage, err = d.DrawShapeImage(dot, cColor)
-	} else {
-		cImage, err = d.DrawStringImage(dot, cColor)
-	}
+	cImage, err := d.drawContent(dot, cColor)
 	if err != nil {
 		return nil, nil, err
 	}
+	if dot.Mirror {
+		cImage.Mirror()
+	}
 
 	shadowColorHex := shadowColor
 	if params.ShadowColor != "" {
@@ -369,15 +377,13 @@ func (d *drawImage) DrawDotImage(dot *DrawDot, params *DrawImageParams) (canvas.
 
 	cvs := canvas.CreateNRGBACanvas(dot.Width+10, dot.Height+10, true)
 	if params.ShowShadow {
-		var shadowImg canvas.NRGBA
-		if dot.DrawType == DrawTypeImage {
-			shadowImg, err = d.DrawShapeImage(dot, sColor)
-		} else {
-			shadowImg, err = d.DrawStringImage(dot, sColor)
-		}
+		shadowImg, err := d.drawContent(dot, sColor)
 		if err != nil {
 			return nil, nil, err
 		}
+		if dot.Mirror {
+			shadowImg.Mirror()
+		}
 
 		pointX := params.ShadowPoint.X
 		pointY := params.ShadowPoint.Y
@@ -391,6 +397,28 @@ func (d *drawImage) DrawDotImage(dot *DrawDot, params *DrawImageParams) (canvas.
 	return cvs, ap, nil
 }
 
+// drawContent draws the text or shape of a dot in a color, a text measured before is painted
+// from its mask
+// params:
+//   - dot: Draw dot
+//   - cColor: Color
+//
+// returns:
+//   - canvas.NRGBA: Drawn image
+//   - error: Error information
+func (d *drawImage) drawContent(dot *DrawDot, cColor color.Color) (canvas.NRGBA, error) {
+	if dot.mask != nil {
+		b := dot.mask.Bounds()
+		cvs := canvas.CreateNRGBACanvas(b.Dx(), b.Dy(), true)
+		draw.DrawMask(cvs.Get(), b, image.NewUniform(cColor), image.Point{}, dot.mask.Get(), b.Min, draw.Over)
+		return cvs, nil
+	}
+	if dot.DrawType == DrawTypeImage {
+		return d.DrawShapeImage(dot, cColor)
+	}
+	return d.DrawStringImage(dot, cColor)
+}
+
 // DrawStringImage draws a text image
 // params:
 //   - dot: Draw dot
@@ -403,18 +431,20 @@ func (d *drawImage) DrawStringImage(dot *DrawDot, textColor color.Color) (canvas
 	cvs := canvas.CreateNRGBACanvas(dot.Width+10, dot.Height+10, true)
 
 	pt := freetype.Pt(12, dot.Height-5)
-	if helper.IsChineseChar(dot.Text) {
+	if helper.IsChineseChar(dot.Text) || (helper.LenGrapheme(dot.Text) == 1 && helper.DisplayWidth(dot.Text) == 2) {
 		pt = freetype.Pt(10, dot.Height)
 	}
 
 	err := cvs.DrawString(&canvas.DrawStringParams{
-		Color:   textColor,
-		Size:    dot.Size,
-		Width:   dot.Width,
-		Height:  dot.Height,
-		FontDPI: dot.FontDPI,
-		Text:    dot.Text,
-		Font:    dot.Font,
+		Color:     textColor,
+		Size:      dot.Size,
+		Width:     dot.Width,
+		Height:    dot.Height,
+		FontDPI:   dot.FontDPI,
+		Text:      dot.Text,
+		Font:      dot.Font,
+		FontChain: dot.Fonts,
+		Cache:     dot.GlyphCache,
 	}, pt)
 
 	if err != nil {
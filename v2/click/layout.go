@@ -0,0 +1,153 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package click
+
+import (
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/base/random"
+)
+
+const (
+	// maxDotsDensity is the largest share of the free area the dots may cover, random placement
+	// seldom packs boxes any denser
+	maxDotsDensity = 0.45
+	// placeTries is the number of random positions tried for a dot before the layout starts over
+	placeTries = 100
+	// placeRounds is the number of layouts tried before giving up
+	placeRounds = 20
+)
+
+// checkFit checks that rangeLen dots of the largest size can fit in the master image
+// return: DotsFitErr when the dots cannot fit
+func (c *captcha) checkFit() error {
+	spacing := c.opts.dotSpacing
+	box := c.opts.rangeSize.Max + spacing
+	width := c.opts.imageSize.Width - 2*c.opts.dotMargin + spacing
+	height := c.opts.imageSize.Height - 2*c.opts.dotMargin + spacing
+
+	if box > width || box > height {
+		return DotsFitErr
+	}
+	if float64(c.opts.rangeLen.Max*box*box) > maxDotsDensity*float64(width*height) {
+		return DotsFitErr
+	}
+	return nil
+}
+
+// dotBox measures the bounding box of a dot once drawn, the content is drawn unrotated and
+// its extent rotated by the angle of the dot, the rendered text is kept as the mask of the dot
+// params:
+//   - dot: Draw dot with its content set
+//
+// return: Size of the box
+func (c *captcha) dotBox(dot *DrawDot) image.Point {
+	d := &drawImage{}
+	var img canvas.NRGBA
+	var err error
+	if dot.DrawType == DrawTypeImage {
+		img, err = d.DrawShapeImage(dot, color.Black)
+	} else {
+		img, err = d.DrawStringImage(dot, color.Black)
+		if err == nil {
+			dot.mask = img
+		}
+	}
+
+	// the extent is the whole canvas when nothing could be measured
+	width, height := dot.Width+10, dot.Height+10
+	if err == nil {
+		if area := img.CalcMarginBlankArea(); area.MaxX > area.MinX && area.MaxY > area.MinY {
+			width, height = area.MaxX-area.MinX, area.MaxY-area.MinY
+		}
+	}
+	if c.opts.displayShadow {
+		width += abs(c.opts.shadowPoint.X)
+		height += abs(c.opts.shadowPoint.Y)
+	}
+
+	// the bilinear rotation spreads the edges by a pixel
+	w, h := canvas.RotatedSize(width+2, height+2, float64(dot.Angle))
+	return image.Point{X: w, Y: h}
+}
+
+// placeDots places the dots on the master image by rejection sampling, a dot takes a random
+// position where its rotated bounding box keeps the spacing from the boxes placed before and
+// the margin from the edges, the largest dots are placed first
+// params:
+//   - imageSize: Size of the master image
+//   - dots: List of draw dots with their content set, X and Y of them and of their dots are set
+//
+// return: DotsFitErr when no layout is found
+func (c *captcha) placeDots(imageSize *option.Size, dots []*DrawDot) error {
+	spacing := c.opts.dotSpacing
+	margin := c.opts.dotMargin
+	maxX := imageSize.Width - margin
+	maxY := imageSize.Height - margin
+
+	order := make([]int, len(dots))
+	boxes := make([]image.Point, len(dots))
+	for i, dot := range dots {
+		boxes[i] = c.dotBox(dot)
+		if margin+boxes[i].X > maxX || margin+boxes[i].Y > maxY {
+			return DotsFitErr
+		}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return boxes[order[a]].X*boxes[order[a]].Y > boxes[order[b]].X*boxes[order[b]].Y
+	})
+
+	// the boxes grow by the spacing to the right and bottom, so that two of them that
+	// do not overlap are at least the spacing apart
+	placed := make([]image.Rectangle, len(dots))
+	for round := 0; round < placeRounds; round++ {
+		ok := true
+		for k, i := range order {
+			box := boxes[i]
+			found := false
+			for try := 0; try < placeTries && !found; try++ {
+				x := random.RandInt(margin, maxX-box.X)
+				y := random.RandInt(margin, maxY-box.Y)
+				placed[i] = image.Rect(x, y, x+box.X+spacing, y+box.Y+spacing)
+
+				found = true
+				for _, j := range order[:k] {
+					if placed[i].Overlaps(placed[j]) {
+						found = false
+						break
+					}
+				}
+			}
+			if !found {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			for i, dot := range dots {
+				dot.X, dot.Y = placed[i].Min.X, placed[i].Min.Y
+				dot.Dot.X, dot.Dot.Y = dot.X, dot.Y
+			}
+			return nil
+		}
+	}
+	return DotsFitErr
+}
+
+// abs .
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,334 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package click
+
+import (
+	"image"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/imagedata"
+	"github.com/wenlng/go-captcha/v2/base/observe"
+	"github.com/wenlng/go-captcha/v2/base/random"
+)
+
+// oddTransforms are the ways the odd dot differs, mirrored, turned upside down or both, i.e. flipped vertically
+var oddTransforms = []struct {
+	mirror bool
+	turn   int
+}{
+	{mirror: true},
+	{turn: 180},
+	{mirror: true, turn: 180},
+}
+
+// generateOddOneOut generates captcha data for the odd-one-out mode, copies of one shape or character
+// at nearly the same angle and one of them mirrored or turned, the verification dot is the odd one
+// params:
+//   - rec: Recorder of the generation
+//
+// returns:
+//   - CaptchaData: Generated captcha data
+//   - error: Error information
+func (c *captcha) generateOddOneOut(rec *observe.Recorder) (CaptchaData, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	length := random.RandInt(c.opts.rangeLen.Min, c.opts.rangeLen.Max)
+	if length < 3 {
+		length = 3
+	}
+	sizes := make([]int, length)
+	size := random.RandInt(c.opts.rangeSize.Min, c.opts.rangeSize.Max)
+	for i := range sizes {
+		sizes[i] = size
+	}
+
+	cache := c.newDrawCache(rec)
+	var dots map[int]*Dot
+	var value string
+	var odd, turn int
+	var mirror, ok bool
+	for try := 0; try < 10 && !ok; try++ {
+		values, err := c.genRandValues(1)
+		if err != nil {
+			return nil, err
+		}
+		value = values[0]
+
+		values = make([]string, length)
+		for i := range values {
+			values[i] = value
+		}
+		dots = c.genDots(c.opts.rangeSize, values, sizes)
+		odd = random.RandInt(0, length-1)
+		mirror, turn, ok = c.oddTransform(dots[odd], cache, rec)
+	}
+	if !ok {
+		return nil, OddOneOutErr
+	}
+
+	angle := dots[0].Angle
+	for _, dot := range dots {
+		dot.Angle = (angle + random.RandInt(-8, 8) + 360) % 360
+	}
+	dots[odd].Mirror = mirror
+	dots[odd].Angle = (dots[odd].Angle + turn) % 360
+	rec.Mark(observe.StageLayout)
+
+	masterImage, err := c.genMasterImage(c.opts.imageSize, dots, cache, rec)
+	if err != nil {
+		return nil, err
+	}
+	rec.Mark(observe.StageMaster)
+
+	answer := *dots[odd]
+	answer.Index = 0
+	verifyDots := map[int]*Dot{0: &answer}
+
+	// the thumbnail shows the copies as they are
+	thumbDots := c.genDots(c.opts.rangeThumbSize, []string{value}, nil)
+	thumbDots[0].Angle = angle
+	rec.Mark(observe.StageLayout)
+
+	return c.finishData(masterImage, thumbDots, verifyDots, cache, rec)
+}
+
+// generateCount generates captcha data for the counting mode, copies of the prompted shape or character
+// among other ones, the verification dots are all the copies in any order, see ValidateSet
+// params:
+//   - rec: Recorder of the generation
+//
+// returns:
+//   - CaptchaData: Generated captcha data
+//   - error: Error information
+func (c *captcha) generateCount(rec *observe.Recorder) (CaptchaData, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	length := random.RandInt(c.opts.rangeLen.Min, c.opts.rangeLen.Max)
+	if length < 3 {
+		length = 3
+	}
+	count := random.RandInt(c.opts.rangeVerifyLen.Min, c.opts.rangeVerifyLen.Max)
+	if count < 2 {
+		count = 2
+	} else if count > length-1 {
+		count = length - 1
+	}
+
+	picked, err := c.genRandValues(length - count + 1)
+	if err != nil {
+		return nil, err
+	}
+	target := picked[0]
+	values := make([]string, 0, length)
+	for i := 0; i < count; i++ {
+		values = append(values, target)
+	}
+	values = append(values, picked[1:]...)
+	shuffled := make([]string, length)
+	for i, k := range random.Perm(length) {
+		shuffled[k] = values[i]
+	}
+
+	cache := c.newDrawCache(rec)
+	dots := c.genDots(c.opts.rangeSize, shuffled, nil)
+	rec.Mark(observe.StageLayout)
+
+	masterImage, err := c.genMasterImage(c.opts.imageSize, dots, cache, rec)
+	if err != nil {
+		return nil, err
+	}
+	rec.Mark(observe.StageMaster)
+
+	verifyDots := make(map[int]*Dot, count)
+	for i := 0; i < length; i++ {
+		if shuffled[i] == target {
+			dot := *dots[i]
+			dot.Index = len(verifyDots)
+			verifyDots[dot.Index] = &dot
+		}
+	}
+
+	thumbDots := c.genDots(c.opts.rangeThumbSize, []string{target}, nil)
+	rec.Mark(observe.StageLayout)
+
+	return c.finishData(masterImage, thumbDots, verifyDots, cache, rec)
+}
+
+// finishData draws the thumbnail, applies the filters and returns the captcha data
+// params:
+//   - masterImage: Main image
+//   - thumbDots: Map of thumbnail dot data
+//   - verifyDots: Map of verification dot data
+//   - cache: Shapes and fonts shared by the dots
+//   - rec: Recorder of the generation
+//
+// returns:
+//   - CaptchaData: Generated captcha data
+//   - error: Error information
+func (c *captcha) finishData(masterImage image.Image, thumbDots, verifyDots map[int]*Dot, cache *drawCache, rec *observe.Recorder) (CaptchaData, error) {
+	thumbImage, err := c.genThumbImage(c.opts.thumbImageSize, thumbDots, cache, rec)
+	if err != nil {
+		return nil, err
+	}
+	rec.Mark(observe.StageThumb)
+
+	masterImage = canvas.ApplyFilters(masterImage, c.opts.masterFilters)
+	rec.Mark(observe.StageMaster)
+	thumbImage = canvas.ApplyFilters(thumbImage, c.opts.thumbFilters)
+	rec.Mark(observe.StageThumb)
+
+	return &CaptData{
+		dots:        verifyDots,
+		masterImage: imagedata.NewJPEGImageDataWithHook(masterImage, rec.EncodeHook("master")),
+		thumbImage:  imagedata.NewPNGImageDataWithHook(thumbImage, rec.EncodeHook("thumb")),
+		prompt:      c.genPrompt(verifyDots),
+	}, nil
+}
+
+// genRandValues generates distinct random shapes, or characters when the dots are not shapes
+// params:
+//   - length: Number of values
+//
+// returns:
+//   - []string: List of shape names or characters
+//   - error: Error information
+func (c *captcha) genRandValues(length int) ([]string, error) {
+	// the modes raise the length above the range of check, fewer values would never be distinct
+	if c.useShapes() {
+		if len(c.resources.shapes) < length {
+			return nil, ShapesRangeLenErr
+		}
+		if values := c.genRandShape(length); len(values) > 0 {
+			return values, nil
+		}
+		return nil, EmptyShapesErr
+	}
+	if len(c.resources.chars) < length {
+		return nil, CharRangeLenErr
+	}
+	if values := c.genRandChar(length); len(values) > 0 {
+		return values, nil
+	}
+	return nil, EmptyCharacterErr
+}
+
+// oddTransform picks a transform that makes a dot look different, a symmetric shape or character
+// looks the same mirrored or turned and is skipped
+// params:
+//   - dot: Dot data
+//   - cache: Shapes and fonts shared by the dots
+//   - rec: Recorder of the generation
+//
+// returns:
+//   - bool: Whether to mirror the dot
+//   - int: Angle to turn the dot by
+//   - bool: Whether a transform makes the dot look different
+func (c *captcha) oddTransform(dot *Dot, cache *drawCache, rec *observe.Recorder) (bool, int, bool) {
+	drawDot := &DrawDot{
+		Dot:    dot,
+		Width:  dot.Width,
+		Height: dot.Height,
+		Size:   dot.Size,
+		Color:  "#000000",
+	}
+	c.setDrawContent(drawDot, drawDot.Color, cache, rec)
+	img, _, err := (&drawImage{}).DrawDotImage(drawDot, &DrawImageParams{Alpha: 1})
+	if err != nil {
+		return false, 0, false
+	}
+
+	m := newMask(img)
+	for _, k := range random.Perm(len(oddTransforms)) {
+		t := oddTransforms[k]
+		if m.difference(t.mirror, t.turn == 180) > 0.25 {
+			return t.mirror, t.turn, true
+		}
+	}
+	return false, 0, false
+}
+
+// mask is the opaque pixels of a drawn dot
+type mask struct {
+	width  int
+	height int
+	pix    []bool
+}
+
+// newMask returns the mask of an image cropped to its opaque pixels, so that a symmetric
+// dot is symmetric in its mask
+func newMask(img image.Image) *mask {
+	b := img.Bounds()
+	area := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0x7fff {
+				area = area.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	m := &mask{width: area.Dx(), height: area.Dy()}
+	m.pix = make([]bool, m.width*m.height)
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			_, _, _, a := img.At(area.Min.X+x, area.Min.Y+y).RGBA()
+			m.pix[y*m.width+x] = a > 0x7fff
+		}
+	}
+	return m
+}
+
+// near checks if the mask has a pixel set within 1 pixel of x, y
+func (m *mask) near(x, y int) bool {
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			nx, ny := x+dx, y+dy
+			if nx >= 0 && nx < m.width && ny >= 0 && ny < m.height && m.pix[ny*m.width+nx] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// difference returns the share of the pixels of the mask and its transform that differ by more
+// than 1 pixel of antialiasing, 0 when the transform looks the same
+// params:
+//   - mirror: Whether the transform mirrors the mask
+//   - turn: Whether the transform turns the mask upside down
+//
+// return: Difference between 0 and 1
+func (m *mask) difference(mirror, turn bool) float64 {
+	var diff, union int
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			tx, ty := x, y
+			if mirror != turn {
+				tx = m.width - 1 - x
+			}
+			if turn {
+				ty = m.height - 1 - y
+			}
+
+			a, b := m.pix[y*m.width+x], m.pix[ty*m.width+tx]
+			if a || b {
+				union++
+				if (a && !m.near(tx, ty)) || (b && !m.near(x, y)) {
+					diff++
+				}
+			}
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(diff) / float64(union)
+}
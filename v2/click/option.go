@@ -9,6 +9,10 @@ package click
 import (
 	"errors"
 
+	"github.com/wenlng/go-captcha/v2/base/bgcache"
+	"github.com/wenlng/go-captcha/v2/base/bgvary"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/helper"
 	"github.com/wenlng/go-captcha/v2/base/logger"
 	"github.com/wenlng/go-captcha/v2/base/option"
 	"golang.org/x/image/font"
@@ -19,10 +23,20 @@ var (
 	RangeVerifyLenErr = errors.New("the max value of 'rangeVerifyLen' must be less than or equal to the min value of 'rangeLen'")
 )
 
+// TextDirection defines the reading direction of the thumbnail prompt
+type TextDirection int
+
+const (
+	TextDirectionAuto TextDirection = iota // Right-to-left when a prompted character is of an RTL script
+	TextDirectionLTR
+	TextDirectionRTL
+)
+
 // Options defines the configuration options for the captcha
 type Options struct {
 	fontDPI     int
 	fontHinting font.Hinting
+	glyphCache  *canvas.GlyphCache
 
 	imageSize     *option.Size
 	rangeLen      *option.RangeVal
@@ -33,6 +47,8 @@ type Options struct {
 	shadowColor   string
 	shadowPoint   *option.Point
 	imageAlpha    float32
+	dotSpacing    int
+	dotMargin     int
 
 	thumbImageSize          *option.Size
 	rangeVerifyLen          *option.RangeVal
@@ -45,8 +61,24 @@ type Options struct {
 	thumbBgSlimLineNum      int
 	isThumbNonDeformAbility bool
 	thumbDisturbAlpha       float32
+	textDirection           TextDirection
 
 	useShapeOriginalColor bool
+
+	promptAttributes Attribute
+	promptLocale     *PromptLocale
+
+	backgroundCache     *bgcache.Cache
+	backgroundVariation *bgvary.Variation
+	backgroundTracker   *bgvary.Tracker
+
+	masterFilters []canvas.Filter
+	thumbFilters  []canvas.Filter
+
+	perturbStrength float32
+	perturbers      []Perturber
+
+	logger logger.Logger
 }
 
 // GetImageSize .
@@ -115,6 +147,16 @@ func (o *Options) GetImageAlpha() float32 {
 	return o.imageAlpha
 }
 
+// GetDotSpacing .
+func (o *Options) GetDotSpacing() int {
+	return o.dotSpacing
+}
+
+// GetDotMargin .
+func (o *Options) GetDotMargin() int {
+	return o.dotMargin
+}
+
 // GetThumbImageSize .
 func (o *Options) GetThumbImageSize() *option.Size {
 	return &option.Size{
@@ -178,6 +220,19 @@ func (o *Options) GetUseShapeOriginalColor() bool {
 	return o.useShapeOriginalColor
 }
 
+// GetPromptAttributes .
+func (o *Options) GetPromptAttributes() Attribute {
+	return o.promptAttributes
+}
+
+// GetPromptLocale .
+func (o *Options) GetPromptLocale() *PromptLocale {
+	if o.promptLocale == nil {
+		return nil
+	}
+	return o.promptLocale.clone()
+}
+
 // GetIsThumbNonDeformAbility .
 func (o *Options) GetIsThumbNonDeformAbility() bool {
 	return o.isThumbNonDeformAbility
@@ -188,6 +243,74 @@ func (o *Options) GetThumbDisturbAlpha() float32 {
 	return o.thumbDisturbAlpha
 }
 
+// GetTextDirection .
+func (o *Options) GetTextDirection() TextDirection {
+	return o.textDirection
+}
+
+// GetFontDPI .
+func (o *Options) GetFontDPI() int {
+	return o.fontDPI
+}
+
+// GetFontHinting .
+func (o *Options) GetFontHinting() font.Hinting {
+	return o.fontHinting
+}
+
+// GetGlyphCache .
+func (o *Options) GetGlyphCache() *canvas.GlyphCache {
+	return o.glyphCache
+}
+
+// GetBackgroundCache .
+func (o *Options) GetBackgroundCache() *bgcache.Cache {
+	return o.backgroundCache
+}
+
+// GetBackgroundVariation .
+func (o *Options) GetBackgroundVariation() *bgvary.Variation {
+	if o.backgroundVariation == nil {
+		return nil
+	}
+	v := *o.backgroundVariation
+	return &v
+}
+
+// GetBackgroundTracker .
+func (o *Options) GetBackgroundTracker() *bgvary.Tracker {
+	return o.backgroundTracker
+}
+
+// GetPerturbStrength .
+func (o *Options) GetPerturbStrength() float32 {
+	return o.perturbStrength
+}
+
+// GetPerturbers .
+func (o *Options) GetPerturbers() []Perturber {
+	return append([]Perturber(nil), o.perturbers...)
+}
+
+// GetMasterFilters .
+func (o *Options) GetMasterFilters() []canvas.Filter {
+	return append([]canvas.Filter(nil), o.masterFilters...)
+}
+
+// GetThumbFilters .
+func (o *Options) GetThumbFilters() []canvas.Filter {
+	return append([]canvas.Filter(nil), o.thumbFilters...)
+}
+
+// warn logs an invalid option value with the logger of the captcha
+func (o *Options) warn(option string, err error) {
+	l := o.logger
+	if l == nil {
+		l = logger.Default()
+	}
+	logger.With(l, logger.F("option", option)).Warnf("%v", err)
+}
+
 type Option func(*Options)
 
 // NewOptions .
@@ -195,6 +318,46 @@ func NewOptions() *Options {
 	return &Options{}
 }
 
+// clone returns a deep copy of the options, the copy shares nothing mutable with the original
+func (o *Options) clone() *Options {
+	no := *o
+
+	if o.imageSize != nil {
+		no.imageSize = o.GetImageSize()
+	}
+	if o.rangeLen != nil {
+		no.rangeLen = o.GetRangeLen()
+	}
+	if o.rangeAnglePos != nil {
+		no.rangeAnglePos = o.GetRangeAnglePos()
+	}
+	if o.rangeSize != nil {
+		no.rangeSize = o.GetRangeSize()
+	}
+	if o.shadowPoint != nil {
+		no.shadowPoint = o.GetShadowPoint()
+	}
+	if o.thumbImageSize != nil {
+		no.thumbImageSize = o.GetThumbImageSize()
+	}
+	if o.rangeVerifyLen != nil {
+		no.rangeVerifyLen = o.GetRangeVerifyLen()
+	}
+	if o.rangeThumbSize != nil {
+		no.rangeThumbSize = o.GetRangeThumbSize()
+	}
+	no.rangeColors = o.GetRangeColors()
+	no.rangeThumbColors = o.GetRangeThumbColors()
+	no.rangeThumbBgColors = o.GetRangeThumbBgColors()
+	no.perturbers = o.GetPerturbers()
+	no.masterFilters = o.GetMasterFilters()
+	no.thumbFilters = o.GetThumbFilters()
+
+	no.backgroundVariation = o.GetBackgroundVariation()
+
+	return &no
+}
+
 // WithFontHinting .
 func WithFontHinting(val font.Hinting) Option {
 	return func(opts *Options) {
@@ -202,6 +365,13 @@ func WithFontHinting(val font.Hinting) Option {
 	}
 }
 
+// WithGlyphCache sets the cache of rasterized glyphs, nil disables it
+func WithGlyphCache(cache *canvas.GlyphCache) Option {
+	return func(opts *Options) {
+		opts.glyphCache = cache
+	}
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Image
 //_______________________________________________________________________
@@ -243,11 +413,11 @@ func WithRangeSize(val option.RangeVal) Option {
 func WithRangeColors(colors []string) Option {
 	return func(opts *Options) {
 		if len(colors) > 255 {
-			logger.Logx.Warnf("withRangeColors(): %v", ColorLenErr)
+			opts.warn("WithRangeColors", ColorLenErr)
 			return
 		}
 
-		opts.rangeColors = colors
+		opts.rangeColors = append([]string(nil), colors...)
 	}
 }
 
@@ -282,6 +452,55 @@ func WithImageAlpha(val float32) Option {
 	}
 }
 
+// WithDotSpacing sets the minimum gap in pixels between the rotated bounding boxes of the dots
+func WithDotSpacing(val int) Option {
+	return func(opts *Options) {
+		if val < 0 {
+			val = 0
+		}
+		opts.dotSpacing = val
+	}
+}
+
+// WithDotMargin sets the minimum gap in pixels between the rotated bounding boxes of the dots
+// and the edges of the master image
+func WithDotMargin(val int) Option {
+	return func(opts *Options) {
+		if val < 0 {
+			val = 0
+		}
+		opts.dotMargin = val
+	}
+}
+
+// WithBackgroundCache sets the cache of pre-scaled backgrounds, nil disables it,
+// it is skipped when a background variation is set, the variation scales the full-size source
+func WithBackgroundCache(cache *bgcache.Cache) Option {
+	return func(opts *Options) {
+		opts.backgroundCache = cache
+	}
+}
+
+// WithBackgroundVariation sets the random crop, flip, hue, brightness, contrast and texture applied to
+// the backgrounds of every captcha, see bgvary.Default, nil disables it
+func WithBackgroundVariation(val *bgvary.Variation) Option {
+	return func(opts *Options) {
+		if val != nil {
+			v := *val
+			val = &v
+		}
+		opts.backgroundVariation = val
+	}
+}
+
+// WithBackgroundTracker sets the tracker warning when few distinct backgrounds are in use, it may be
+// shared by several captchas, nil disables it
+func WithBackgroundTracker(val *bgvary.Tracker) Option {
+	return func(opts *Options) {
+		opts.backgroundTracker = val
+	}
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Thumb Image
 //_______________________________________________________________________
@@ -297,7 +516,7 @@ func WithRangeThumbImageSize(val option.Size) Option {
 func WithRangeVerifyLen(val option.RangeVal) Option {
 	return func(opts *Options) {
 		if val.Max > opts.rangeLen.Min {
-			logger.Logx.Warnf("withRangeVerifyLen(): %v", RangeVerifyLenErr)
+			opts.warn("WithRangeVerifyLen", RangeVerifyLenErr)
 			return
 		}
 
@@ -323,10 +542,10 @@ func WithRangeThumbSize(val option.RangeVal) Option {
 func WithRangeThumbColors(val []string) Option {
 	return func(opts *Options) {
 		if len(val) > 255 {
-			logger.Logx.Warnf("WithRangeThumbColors(): %v", ColorLenErr)
+			opts.warn("WithRangeThumbColors", ColorLenErr)
 			return
 		}
-		opts.rangeThumbColors = val
+		opts.rangeThumbColors = append([]string(nil), val...)
 	}
 }
 
@@ -334,11 +553,11 @@ func WithRangeThumbColors(val []string) Option {
 func WithRangeThumbBgColors(val []string) Option {
 	return func(opts *Options) {
 		if len(val) > 255 {
-			logger.Logx.Warnf("withRangeThumbBgColors(): %v", ColorLenErr)
+			opts.warn("WithRangeThumbBgColors", ColorLenErr)
 			return
 		}
 
-		opts.rangeThumbBgColors = val
+		opts.rangeThumbBgColors = append([]string(nil), val...)
 	}
 }
 
@@ -374,6 +593,28 @@ func WithUseShapeOriginalColor(val bool) Option {
 	}
 }
 
+// WithPromptAttributes sets the attributes named in the prompt of the shape mode besides the shape,
+// such as "click the large red triangle". Shapes then repeat on the master image and no two shapes
+// share the named attributes, the thumbnail draws the shapes in their color and size. Naming the size
+// needs a size range wide enough for the lower and upper quarters to differ visibly, e.g. 20 to 40.
+// Naming the color with WithUseShapeOriginalColor needs shape generators only, Generate returns
+// PromptColorErr when shape images are set.
+func WithPromptAttributes(val Attribute) Option {
+	return func(opts *Options) {
+		opts.promptAttributes = val
+	}
+}
+
+// WithPromptLocale sets the words of the prompt returned by CaptchaData.GetPrompt, see LocalePrompt,
+// the default is "en"
+func WithPromptLocale(val *PromptLocale) Option {
+	return func(opts *Options) {
+		if val != nil {
+			opts.promptLocale = val.clone()
+		}
+	}
+}
+
 // WithIsThumbNonDeformAbility .
 func WithIsThumbNonDeformAbility(val bool) Option {
 	return func(opts *Options) {
@@ -381,9 +622,57 @@ func WithIsThumbNonDeformAbility(val bool) Option {
 	}
 }
 
+// WithTextDirection sets the reading direction of the thumbnail prompt
+func WithTextDirection(val TextDirection) Option {
+	return func(opts *Options) {
+		opts.textDirection = val
+	}
+}
+
 // WithThumbDisturbAlpha .
 func WithThumbDisturbAlpha(val float32) Option {
 	return func(opts *Options) {
 		opts.thumbDisturbAlpha = val
 	}
 }
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Perturbation
+//_______________________________________________________________________
+
+// WithPerturbStrength sets the built-in perturbation pipeline of the master image at a strength
+// from 0 to 1, see DefaultPerturbers, 0 turns it off
+func WithPerturbStrength(val float32) Option {
+	return func(opts *Options) {
+		opts.perturbStrength = float32(helper.ClampUnit(float64(val)))
+		opts.perturbers = DefaultPerturbers(float64(opts.perturbStrength))
+	}
+}
+
+// WithPerturbers sets the perturbers run in order over the master image once the dots are drawn,
+// it replaces the pipeline of WithPerturbStrength
+func WithPerturbers(vals ...Perturber) Option {
+	return func(opts *Options) {
+		opts.perturbStrength = 0
+		opts.perturbers = append([]Perturber(nil), vals...)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Filters
+//_______________________________________________________________________
+
+// WithMasterFilters sets the filters applied in order to the master image, after the perturbers.
+// Geometric filters such as canvas.NewSwirl move the answer, Generate returns canvas.GeometricFilterErr
+func WithMasterFilters(vals ...canvas.Filter) Option {
+	return func(opts *Options) {
+		opts.masterFilters = append([]canvas.Filter(nil), vals...)
+	}
+}
+
+// WithThumbFilters sets the filters applied in order to the thumbnail image
+func WithThumbFilters(vals ...canvas.Filter) Option {
+	return func(opts *Options) {
+		opts.thumbFilters = append([]canvas.Filter(nil), vals...)
+	}
+}
@@ -0,0 +1,292 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package click
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+// Perturber changes the master image after the dots are drawn, to degrade OCR and ML
+// solvers while people can still read the dots
+type Perturber interface {
+	// Perturb changes the image in place, the dots hold the bounds they were drawn with
+	Perturb(img *image.NRGBA, dots []*DrawDot, rnd *rand.Rand)
+}
+
+// PerturberFunc adapts a function to the Perturber interface
+type PerturberFunc func(img *image.NRGBA, dots []*DrawDot, rnd *rand.Rand)
+
+// Perturb calls f(img, dots, rnd)
+func (f PerturberFunc) Perturb(img *image.NRGBA, dots []*DrawDot, rnd *rand.Rand) {
+	f(img, dots, rnd)
+}
+
+// DefaultPerturbers returns the built-in pipeline at a strength from 0 to 1: structured noise,
+// glyph warps, overlapping strokes and texture fills, in that order
+func DefaultPerturbers(strength float64) []Perturber {
+	if strength <= 0 {
+		return nil
+	}
+	strength = math.Min(strength, 1)
+
+	return []Perturber{
+		NewStructuredNoise(strength),
+		NewElasticWarp(strength),
+		NewStrokes(2, strength),
+		NewTextureFill(strength),
+	}
+}
+
+// structuredNoise adds a few low-amplitude plane waves and a fine grain to the whole image
+type structuredNoise struct {
+	strength float64
+}
+
+// NewStructuredNoise returns a perturber adding low-amplitude waves and grain over the image,
+// the strength from 0 to 1 moves the colors by up to 24 levels
+func NewStructuredNoise(strength float64) Perturber {
+	return &structuredNoise{strength: helper.ClampUnit(strength)}
+}
+
+// Perturb .
+func (n *structuredNoise) Perturb(img *image.NRGBA, _ []*DrawDot, rnd *rand.Rand) {
+	if n.strength <= 0 {
+		return
+	}
+
+	type wave struct{ fx, fy, phase float64 }
+	waves := make([]wave, 3)
+	for i := range waves {
+		angle := rnd.Float64() * math.Pi
+		freq := 2 * math.Pi / (6 + rnd.Float64()*14)
+		waves[i] = wave{fx: math.Cos(angle) * freq, fy: math.Sin(angle) * freq, phase: rnd.Float64() * 2 * math.Pi}
+	}
+
+	amp := 16 * n.strength
+	grain := 8 * n.strength
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v := 0.0
+			for _, w := range waves {
+				v += math.Sin(float64(x)*w.fx + float64(y)*w.fy + w.phase)
+			}
+			v = v/float64(len(waves))*amp + (rnd.Float64()*2-1)*grain
+
+			i := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = helper.ClampUint8(float64(img.Pix[i+c]) + v)
+			}
+		}
+	}
+}
+
+// elasticWarp displaces the pixels around every dot along a smooth random field
+type elasticWarp struct {
+	strength float64
+}
+
+// NewElasticWarp returns a perturber bending every dot with a smooth displacement field,
+// the strength from 0 to 1 moves the pixels by up to 1.5 pixels
+func NewElasticWarp(strength float64) Perturber {
+	return &elasticWarp{strength: helper.ClampUnit(strength)}
+}
+
+// Perturb .
+func (e *elasticWarp) Perturb(img *image.NRGBA, dots []*DrawDot, rnd *rand.Rand) {
+	if e.strength <= 0 {
+		return
+	}
+
+	const margin = 4
+	amp := 1.5 * e.strength
+	for _, dot := range dots {
+		r := image.Rect(dot.X-margin, dot.Y-margin, dot.X+dot.Width+margin, dot.Y+dot.Height+margin).Intersect(img.Bounds())
+		if r.Empty() {
+			continue
+		}
+
+		src := image.NewNRGBA(r)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			copy(src.Pix[src.PixOffset(r.Min.X, y):src.PixOffset(r.Max.X, y)], img.Pix[img.PixOffset(r.Min.X, y):img.PixOffset(r.Max.X, y)])
+		}
+
+		period := math.Max(float64(helper.MaxInt(r.Dx(), r.Dy()))/1.5, 4)
+		fx := 2 * math.Pi / (period * (0.8 + rnd.Float64()*0.4))
+		fy := 2 * math.Pi / (period * (0.8 + rnd.Float64()*0.4))
+		px, py := rnd.Float64()*2*math.Pi, rnd.Float64()*2*math.Pi
+
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				// fade the field out towards the border so the warp leaves no seam
+				edge := helper.MinInt(helper.MinInt(x-r.Min.X, r.Max.X-1-x), helper.MinInt(y-r.Min.Y, r.Max.Y-1-y))
+				w := math.Min(float64(edge)/margin, 1) * amp
+				dx := math.Sin(float64(y)*fy+px) * w
+				dy := math.Sin(float64(x)*fx+py) * w
+
+				i := img.PixOffset(x, y)
+				samplePixel(src, float64(x)+dx, float64(y)+dy, img.Pix[i:i+4])
+			}
+		}
+	}
+}
+
+// strokes draws curves in the color of every dot across it
+type strokes struct {
+	count    int
+	strength float64
+}
+
+// NewStrokes returns a perturber drawing a number of thin curves across every dot in its own color,
+// the strength from 0 to 1 sets their opacity
+func NewStrokes(count int, strength float64) Perturber {
+	return &strokes{count: helper.MaxInt(count, 0), strength: helper.ClampUnit(strength)}
+}
+
+// Perturb .
+func (s *strokes) Perturb(img *image.NRGBA, dots []*DrawDot, rnd *rand.Rand) {
+	if s.strength <= 0 || s.count == 0 {
+		return
+	}
+
+	alpha := 0.5 * s.strength
+	for _, dot := range dots {
+		if dot.Width <= 0 || dot.Height <= 0 {
+			continue
+		}
+		col, ok := dotColor(dot)
+		if !ok {
+			col.R, col.G, col.B = 0x33, 0x33, 0x33
+		}
+		rgb := [3]float64{float64(col.R), float64(col.G), float64(col.B)}
+
+		w, h := float64(dot.Width), float64(dot.Height)
+		for k := 0; k < s.count; k++ {
+			// a quadratic curve entering on the left and leaving on the right of the dot
+			x0, y0 := float64(dot.X)-w*0.15, float64(dot.Y)+h*rnd.Float64()
+			x2, y2 := float64(dot.X)+w*1.15, float64(dot.Y)+h*rnd.Float64()
+			x1, y1 := float64(dot.X)+w*rnd.Float64(), float64(dot.Y)+h*(rnd.Float64()*1.6-0.3)
+			radius := 0.4 + rnd.Float64()*0.3
+
+			steps := int(w*2) + 8
+			for t := 0; t <= steps; t++ {
+				u := float64(t) / float64(steps)
+				x := (1-u)*(1-u)*x0 + 2*(1-u)*u*x1 + u*u*x2
+				y := (1-u)*(1-u)*y0 + 2*(1-u)*u*y1 + u*u*y2
+				stampDisc(img, x, y, radius, rgb, alpha)
+			}
+		}
+	}
+}
+
+// textureFill modulates the pixels of every dot with a stripe pattern
+type textureFill struct {
+	strength float64
+}
+
+// NewTextureFill returns a perturber filling the pixels of every dot that have its color with stripes,
+// the strength from 0 to 1 changes their brightness by up to 30 levels
+func NewTextureFill(strength float64) Perturber {
+	return &textureFill{strength: helper.ClampUnit(strength)}
+}
+
+// Perturb .
+func (t *textureFill) Perturb(img *image.NRGBA, dots []*DrawDot, rnd *rand.Rand) {
+	if t.strength <= 0 {
+		return
+	}
+
+	amp := 30 * t.strength
+	for _, dot := range dots {
+		col, ok := dotColor(dot)
+		if !ok {
+			continue
+		}
+
+		r := image.Rect(dot.X, dot.Y, dot.X+dot.Width, dot.Y+dot.Height).Intersect(img.Bounds())
+		angle := rnd.Float64() * math.Pi
+		freq := 2 * math.Pi / (3 + rnd.Float64()*3)
+		fx, fy := math.Cos(angle)*freq, math.Sin(angle)*freq
+
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				i := img.PixOffset(x, y)
+				dr := float64(img.Pix[i]) - float64(col.R)
+				dg := float64(img.Pix[i+1]) - float64(col.G)
+				db := float64(img.Pix[i+2]) - float64(col.B)
+				if dr*dr+dg*dg+db*db > 60*60 {
+					continue
+				}
+
+				v := math.Sin(float64(x)*fx+float64(y)*fy) * amp
+				for c := 0; c < 3; c++ {
+					img.Pix[i+c] = helper.ClampUint8(float64(img.Pix[i+c]) + v)
+				}
+			}
+		}
+	}
+}
+
+// perturb runs the perturbers over the image in order
+func perturb(img *image.NRGBA, dots []*DrawDot, perturbers []Perturber, rnd *rand.Rand) {
+	for _, p := range perturbers {
+		if p != nil {
+			p.Perturb(img, dots, rnd)
+		}
+	}
+}
+
+// dotColor returns the color the dot was drawn with, false when it keeps the colors of its image
+func dotColor(dot *DrawDot) (color.RGBA, bool) {
+	if dot.UseOriginalColor || dot.Color == "" {
+		return color.RGBA{}, false
+	}
+	col, err := helper.ParseHexColor(dot.Color)
+	return col, err == nil
+}
+
+// samplePixel writes the bilinear sample of the image at x, y into out, clamped to its bounds
+func samplePixel(img *image.NRGBA, x, y float64, out []uint8) {
+	b := img.Bounds()
+	x = math.Max(float64(b.Min.X), math.Min(x, float64(b.Max.X-1)))
+	y = math.Max(float64(b.Min.Y), math.Min(y, float64(b.Max.Y-1)))
+
+	x0, y0 := int(x), int(y)
+	x1, y1 := helper.MinInt(x0+1, b.Max.X-1), helper.MinInt(y0+1, b.Max.Y-1)
+	ax, ay := x-float64(x0), y-float64(y0)
+
+	p00, p10 := img.PixOffset(x0, y0), img.PixOffset(x1, y0)
+	p01, p11 := img.PixOffset(x0, y1), img.PixOffset(x1, y1)
+	for c := 0; c < 4; c++ {
+		top := float64(img.Pix[p00+c])*(1-ax) + float64(img.Pix[p10+c])*ax
+		bottom := float64(img.Pix[p01+c])*(1-ax) + float64(img.Pix[p11+c])*ax
+		out[c] = helper.ClampUint8(top*(1-ay) + bottom*ay)
+	}
+}
+
+// stampDisc blends an anti-aliased disc of the color into the image
+func stampDisc(img *image.NRGBA, cx, cy, radius float64, rgb [3]float64, alpha float64) {
+	r := image.Rect(int(cx-radius)-1, int(cy-radius)-1, int(cx+radius)+2, int(cy+radius)+2).Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+			cover := helper.ClampUnit(radius+0.5-d) * alpha
+			if cover <= 0 {
+				continue
+			}
+			i := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = helper.ClampUint8(float64(img.Pix[i+c])*(1-cover) + rgb[c]*cover)
+			}
+		}
+	}
+}
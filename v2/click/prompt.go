@@ -0,0 +1,329 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package click
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/base/random"
+)
+
+// Attribute is an attribute of a shape named in the prompt, besides the shape itself
+type Attribute int
+
+const (
+	AttributeColor Attribute = 1 << iota // Name the color, such as "the red triangle"
+	AttributeSize                        // Name the size, such as "the large circle"
+)
+
+// PromptLocale holds the words of the prompt in a language
+type PromptLocale struct {
+	// First formats the first target, such as "Click the %s"
+	First string
+	// Then formats each following target, such as ", then the %s"
+	Then string
+	// Text formats a character of the text mode, such as "\"%s\""
+	Text string
+	// OddOneOut is the prompt of the odd-one-out mode
+	OddOneOut string
+	// Count formats the prompt of the counting mode, such as "Click every %s"
+	Count string
+	// Separator joins the size, color and shape of a target
+	Separator string
+	Small     string
+	Large     string
+	// Colors translates the color names: red, orange, yellow, green, cyan, blue, purple, pink,
+	// brown, black, gray and white
+	Colors map[string]string
+	// Shapes translates the shape names, a missing shape is named as is
+	Shapes map[string]string
+}
+
+// Default prompt words by locale
+var promptLocales = map[string]*PromptLocale{
+	"en": {
+		First:     "Click the %s",
+		Then:      ", then the %s",
+		Text:      "\"%s\"",
+		OddOneOut: "Click the one that is different",
+		Count:     "Click every %s",
+		Separator: " ",
+		Small:     "small",
+		Large:     "large",
+	},
+	"zh": {
+		First:     "请依次点击%s",
+		Then:      "、%s",
+		Text:      "“%s”",
+		OddOneOut: "请点击与众不同的那一个",
+		Count:     "请点击所有的%s",
+		Small:     "小",
+		Large:     "大",
+		Colors: map[string]string{
+			"red": "红色", "orange": "橙色", "yellow": "黄色", "green": "绿色", "cyan": "青色", "blue": "蓝色",
+			"purple": "紫色", "pink": "粉色", "brown": "棕色", "black": "黑色", "gray": "灰色", "white": "白色",
+		},
+		Shapes: map[string]string{
+			"star": "五角星", "triangle": "三角形", "square": "正方形", "pentagon": "五边形", "hexagon": "六边形",
+			"heptagon": "七边形", "octagon": "八边形", "heart": "心形", "arrow": "箭头", "crescent": "月牙",
+			"cross": "十字", "ring": "圆环",
+		},
+	},
+}
+
+// LocalePrompt gets a copy of the prompt words of a locale, "en" or "zh",
+// region and script subtags are ignored
+// params:
+//   - locale: Locale tag
+//
+// return:
+//   - *PromptLocale: Prompt words, to pass to WithPromptLocale after any change
+//   - bool: Whether the locale is supported
+func LocalePrompt(locale string) (*PromptLocale, bool) {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	l, ok := promptLocales[lang]
+	if !ok {
+		return nil, false
+	}
+	return l.clone(), true
+}
+
+// clone .
+func (l *PromptLocale) clone() *PromptLocale {
+	nl := *l
+	nl.Colors = make(map[string]string, len(l.Colors))
+	for k, v := range l.Colors {
+		nl.Colors[k] = v
+	}
+	nl.Shapes = make(map[string]string, len(l.Shapes))
+	for k, v := range l.Shapes {
+		nl.Shapes[k] = v
+	}
+	return &nl
+}
+
+// word translates a word of a table, the word itself when it is missing
+func (l *PromptLocale) word(table map[string]string, w string) string {
+	if t, ok := table[w]; ok {
+		return t
+	}
+	return w
+}
+
+// genPrompt formats the prompt of the verification dots in order
+// params:
+//   - dots: Map of verification dot data
+//
+// return: Localized prompt
+func (c *captcha) genPrompt(dots map[int]*Dot) string {
+	l := c.opts.promptLocale
+	if l == nil {
+		l = promptLocales["en"]
+	}
+
+	switch c.mode {
+	case ModeOddOneOut:
+		return l.OddOneOut
+	case ModeCount:
+		if len(dots) == 0 {
+			return ""
+		}
+		return fmt.Sprintf(l.Count, c.describe(l, dots[0]))
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(dots); i++ {
+		if i == 0 {
+			b.WriteString(fmt.Sprintf(l.First, c.describe(l, dots[i])))
+		} else {
+			b.WriteString(fmt.Sprintf(l.Then, c.describe(l, dots[i])))
+		}
+	}
+	return b.String()
+}
+
+// describe describes a dot in the prompt, the attributes are only named in the shape mode
+// params:
+//   - l: Prompt words
+//   - dot: Dot data
+//
+// return: Description such as "large red triangle"
+func (c *captcha) describe(l *PromptLocale, dot *Dot) string {
+	if !c.useShapes() {
+		return fmt.Sprintf(l.Text, dot.Text)
+	}
+
+	var words []string
+	if c.mode == ModeShape && c.opts.promptAttributes&AttributeSize != 0 {
+		if c.isLarge(dot.Size) {
+			words = append(words, l.Large)
+		} else {
+			words = append(words, l.Small)
+		}
+	}
+	if c.mode == ModeShape && c.opts.promptAttributes&AttributeColor != 0 {
+		words = append(words, l.word(l.Colors, colorName(dot.Color)))
+	}
+	words = append(words, l.word(l.Shapes, dot.Shape))
+	return strings.Join(words, l.Separator)
+}
+
+// genAttributeShapes generates the shapes, colors and sizes of the dots of a prompt with attributes,
+// shapes repeat so that the attributes tell the dots apart, and no two dots share the named attributes
+// params:
+//   - length: Number of dots
+//
+// returns:
+//   - []string: List of shape names
+//   - []string: List of colors
+//   - []int: List of sizes, nil when the size is not named
+func (c *captcha) genAttributeShapes(length int) ([]string, []string, []int) {
+	pool := c.genRandShape((length + 1) / 2)
+	shapes := make([]string, 0, length)
+	colors := make([]string, 0, length)
+	var sizes []int
+	if c.opts.promptAttributes&AttributeSize != 0 {
+		sizes = make([]int, 0, length)
+	}
+
+	used := make(map[string]bool, length)
+	for i := 0; i < length; i++ {
+		var shape, co, key string
+		var size int
+		for try := 0; try < 20; try++ {
+			shape = pool[random.RandInt(0, len(pool)-1)]
+			co, size = c.randAttributes()
+			if key = c.attributeKey(shape, co, size); !used[key] {
+				break
+			}
+		}
+		if used[key] {
+			// a shape not used yet is always unique
+			for _, k := range random.Perm(len(c.resources.shapes)) {
+				if name := c.resources.shapes[k]; !helper.InArrayWithStr(shapes, name) {
+					shape = name
+					break
+				}
+			}
+			key = c.attributeKey(shape, co, size)
+		}
+
+		used[key] = true
+		shapes = append(shapes, shape)
+		colors = append(colors, co)
+		if sizes != nil {
+			sizes = append(sizes, size)
+		}
+	}
+	return shapes, colors, sizes
+}
+
+// randAttributes picks a random color and a size in the lower or upper quarter of the size range
+// returns:
+//   - string: Color
+//   - int: Size
+func (c *captcha) randAttributes() (string, int) {
+	colors := c.opts.rangeColors
+	if len(colors) == 0 {
+		colors = getDefaultColors()
+	}
+	co := colors[random.RandInt(0, len(colors)-1)]
+
+	quarter := (c.opts.rangeSize.Max - c.opts.rangeSize.Min) / 4
+	if random.RandInt(0, 1) == 0 {
+		return co, random.RandInt(c.opts.rangeSize.Min, c.opts.rangeSize.Min+quarter)
+	}
+	return co, random.RandInt(c.opts.rangeSize.Max-quarter, c.opts.rangeSize.Max)
+}
+
+// attributeKey returns the named attributes of a dot
+func (c *captcha) attributeKey(shape, co string, size int) string {
+	key := shape
+	if c.opts.promptAttributes&AttributeColor != 0 {
+		key += "|" + colorName(co)
+	}
+	if c.opts.promptAttributes&AttributeSize != 0 {
+		key += fmt.Sprintf("|%v", c.isLarge(size))
+	}
+	return key
+}
+
+// isLarge checks if a dot size is in the upper half of the size range
+func (c *captcha) isLarge(size int) bool {
+	return size*2 > c.opts.rangeSize.Min+c.opts.rangeSize.Max
+}
+
+// colorName names a hex color after its hue, saturation and value
+// params:
+//   - hex: Hex color
+//
+// return: One of red, orange, yellow, green, cyan, blue, purple, pink, brown, black, gray and white
+func colorName(hex string) string {
+	co, err := helper.ParseHexColor(hex)
+	if err != nil {
+		return hex
+	}
+
+	r, g, b := float64(co.R)/255, float64(co.G)/255, float64(co.B)/255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	v, d := max, max-min
+	var s float64
+	if max > 0 {
+		s = d / max
+	}
+
+	switch {
+	case v < 0.2:
+		return "black"
+	case s < 0.15 && v > 0.85:
+		return "white"
+	case s < 0.15:
+		return "gray"
+	}
+
+	var h float64
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6) * 60
+	case g:
+		h = ((b-r)/d + 2) * 60
+	default:
+		h = ((r-g)/d + 4) * 60
+	}
+	if h < 0 {
+		h += 360
+	}
+
+	switch {
+	case h < 15 || h >= 345:
+		return "red"
+	case h < 40:
+		if v < 0.6 {
+			return "brown"
+		}
+		return "orange"
+	case h < 70:
+		return "yellow"
+	case h < 165:
+		return "green"
+	case h < 190:
+		return "cyan"
+	case h < 255:
+		return "blue"
+	case h < 290:
+		return "purple"
+	}
+	return "pink"
+}
@@ -11,18 +11,28 @@ import (
 	"image"
 
 	"github.com/golang/freetype/truetype"
+	"github.com/wenlng/go-captcha/v2/base/bggen"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
 	"github.com/wenlng/go-captcha/v2/base/helper"
 	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/base/shapegen"
+	"golang.org/x/image/font/sfnt"
 )
 
 // Resources defines the resources for the CAPTCHA
 type Resources struct {
 	chars                []string
 	shapeMaps            map[string]image.Image
+	shapeGenerators      map[string]shapegen.Shape
 	shapes               []string
 	rangFonts            []*truetype.Font
+	rangSfntFonts        []*sfnt.Font
+	fallbackFonts        []canvas.Font
 	rangBackgrounds      []image.Image
 	rangThumbBackgrounds []image.Image
+	backgroundGenerators []bggen.Generator
+
+	logger logger.Logger
 }
 
 // NewResources .
@@ -30,30 +40,107 @@ func NewResources() *Resources {
 	return &Resources{}
 }
 
+// clone returns a copy of the resources, slices and maps are copied while the images and fonts they hold are shared
+func (r *Resources) clone() *Resources {
+	nr := &Resources{
+		chars:                append([]string(nil), r.chars...),
+		shapes:               append([]string(nil), r.shapes...),
+		rangFonts:            append([]*truetype.Font(nil), r.rangFonts...),
+		rangSfntFonts:        append([]*sfnt.Font(nil), r.rangSfntFonts...),
+		fallbackFonts:        append([]canvas.Font(nil), r.fallbackFonts...),
+		rangBackgrounds:      append([]image.Image(nil), r.rangBackgrounds...),
+		rangThumbBackgrounds: append([]image.Image(nil), r.rangThumbBackgrounds...),
+		backgroundGenerators: append([]bggen.Generator(nil), r.backgroundGenerators...),
+		logger:               r.logger,
+	}
+
+	if r.shapeMaps != nil {
+		nr.shapeMaps = make(map[string]image.Image, len(r.shapeMaps))
+		for name, img := range r.shapeMaps {
+			nr.shapeMaps[name] = img
+		}
+	}
+	if r.shapeGenerators != nil {
+		nr.shapeGenerators = make(map[string]shapegen.Shape, len(r.shapeGenerators))
+		for name, s := range r.shapeGenerators {
+			nr.shapeGenerators[name] = s
+		}
+	}
+
+	return nr
+}
+
+// updateShapes lists the names of the shape images and the shape generators, an image
+// takes precedence over a generator of the same name
+func (r *Resources) updateShapes() {
+	shapes := make([]string, 0, len(r.shapeMaps)+len(r.shapeGenerators))
+	for name := range r.shapeMaps {
+		shapes = append(shapes, name)
+	}
+	for name := range r.shapeGenerators {
+		if _, ok := r.shapeMaps[name]; !ok {
+			shapes = append(shapes, name)
+		}
+	}
+	r.shapes = shapes
+}
+
+// fonts returns the fonts that can be randomly selected
+func (r *Resources) fonts() []canvas.Font {
+	fonts := make([]canvas.Font, 0, len(r.rangFonts)+len(r.rangSfntFonts))
+	for _, f := range r.rangFonts {
+		fonts = append(fonts, canvas.NewTrueTypeFont(f))
+	}
+	for _, f := range r.rangSfntFonts {
+		fonts = append(fonts, canvas.NewSfntFont(f))
+	}
+	return fonts
+}
+
+// warn logs an invalid resource with the logger of the captcha
+func (r *Resources) warn(resource string, err error) {
+	l := r.logger
+	if l == nil {
+		l = logger.Default()
+	}
+	logger.With(l, logger.F("resource", resource)).Warnf("%v", err)
+}
+
 type Resource func(*Resources)
 
 var (
+	// Deprecated: As of 2.1.0, it will be removed, chars are measured by display width, see [CharLenErr].
 	ChineseCharLenErr = errors.New("the chinese char length must be equal to 1")
-	CharLenErr        = errors.New("the char length must be less than or equal to 2")
+	CharLenErr        = errors.New("the char display width must be less than or equal to 2")
+	LocaleCharsErr    = errors.New("no default chars for the locale")
 )
 
 // WithChars is to set characters
+// Every entry is measured in grapheme clusters, a wide character (CJK, Hangul, kana, emoji)
+// takes 2 cells and a narrow one takes 1, an entry may take at most 2 cells,
+// e.g. one Chinese character, two ASCII characters or one Arabic letter with its marks
 func WithChars(chars []string) Resource {
 	return func(resources *Resources) {
-		if len(chars) > 0 {
-			for _, char := range chars {
-				if helper.IsChineseChar(char) {
-					if helper.LenChineseChar(char) > 1 {
-						logger.Logx.Warnf("WithChars(): %v", ChineseCharLenErr)
-						return
-					}
-				} else if helper.LenChineseChar(char) > 2 {
-					logger.Logx.Warnf("WithChars(): %v", CharLenErr)
-					return
-				}
+		for _, char := range chars {
+			if helper.DisplayWidth(char) > 2 {
+				resources.warn("WithChars", CharLenErr)
+				return
 			}
 		}
 
+		resources.chars = append([]string(nil), chars...)
+	}
+}
+
+// WithLocaleChars is to set the default characters of a locale, such as "zh", "ja" or "ar-EG"
+func WithLocaleChars(locale string) Resource {
+	return func(resources *Resources) {
+		chars, ok := LocaleChars(locale)
+		if !ok {
+			resources.warn("WithLocaleChars", LocaleCharsErr)
+			return
+		}
+
 		resources.chars = chars
 	}
 }
@@ -61,32 +148,70 @@ func WithChars(chars []string) Resource {
 // WithShapes is to set shape
 func WithShapes(shapeMaps map[string]image.Image) Resource {
 	return func(resources *Resources) {
-		resources.shapeMaps = shapeMaps
-		var shapes = make([]string, 0, len(shapeMaps))
-		for name, _ := range shapeMaps {
-			shapes = append(shapes, name)
+		maps := make(map[string]image.Image, len(shapeMaps))
+		for name, img := range shapeMaps {
+			maps[name] = img
+		}
+		resources.shapeMaps = maps
+		resources.updateShapes()
+	}
+}
+
+// WithShapeGenerators is to set shapes rendered again for every captcha, they are selected
+// randomly together with WithShapes and named after Shape.Name, see shapegen.Defaults
+func WithShapeGenerators(shapes []shapegen.Shape) Resource {
+	return func(resources *Resources) {
+		generators := make(map[string]shapegen.Shape, len(shapes))
+		for _, s := range shapes {
+			if s != nil {
+				generators[s.Name()] = s
+			}
 		}
-		resources.shapes = shapes
+		resources.shapeGenerators = generators
+		resources.updateShapes()
 	}
 }
 
 // WithFonts is to set font
 func WithFonts(fonts []*truetype.Font) Resource {
 	return func(resources *Resources) {
-		resources.rangFonts = fonts
+		resources.rangFonts = append([]*truetype.Font(nil), fonts...)
+	}
+}
+
+// WithSfntFonts is to set OpenType/TrueType fonts parsed by sfnt, they are selected randomly together with WithFonts
+func WithSfntFonts(fonts []*sfnt.Font) Resource {
+	return func(resources *Resources) {
+		resources.rangSfntFonts = append([]*sfnt.Font(nil), fonts...)
+	}
+}
+
+// WithFallbackFonts is to set fonts that are never selected randomly,
+// they draw the characters missing from the selected font
+func WithFallbackFonts(fonts []canvas.Font) Resource {
+	return func(resources *Resources) {
+		resources.fallbackFonts = append([]canvas.Font(nil), fonts...)
 	}
 }
 
 // WithBackgrounds is to set background image
 func WithBackgrounds(images []image.Image) Resource {
 	return func(resources *Resources) {
-		resources.rangBackgrounds = images
+		resources.rangBackgrounds = append([]image.Image(nil), images...)
 	}
 }
 
 // WithThumbBackgrounds is to set thumbnail background image
 func WithThumbBackgrounds(images []image.Image) Resource {
 	return func(resources *Resources) {
-		resources.rangThumbBackgrounds = images
+		resources.rangThumbBackgrounds = append([]image.Image(nil), images...)
+	}
+}
+
+// WithBackgroundGenerators is to set generators drawing a new background for every captcha,
+// they are selected randomly together with WithBackgrounds, see bggen.Defaults
+func WithBackgroundGenerators(generators []bggen.Generator) Resource {
+	return func(resources *Resources) {
+		resources.backgroundGenerators = append([]bggen.Generator(nil), generators...)
 	}
 }
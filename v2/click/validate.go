@@ -8,9 +8,12 @@ package click
 
 import (
 	"math"
+
+	"github.com/wenlng/go-captcha/v2/base/option"
 )
 
 // Validate checks if a click point is within the specified area
+// It checks a single dot, report the outcome of the whole verification with observe.Verified("click", ok)
 // params:
 //   - sx, sy: Coordinates of the click point
 //   - dx, dy: Top-left coordinates of the target area
@@ -30,6 +33,53 @@ func Validate(sx, sy, dx, dy, width, height, padding int) bool {
 		sy <= newDy+newHeight
 }
 
+// ValidateSet checks clicks against dots in any order, as the dots of the odd-one-out and counting
+// modes, every dot must be clicked once and every click must hit a dot.
+// Like Validate it leaves the report to the caller, report the outcome with observe.Verified("click", ok)
+// params:
+//   - points: Click points
+//   - dots: Map of verification dot data
+//   - padding: Padding of the areas
+//
+// return: Whether the clicks match the dots one to one
+func ValidateSet(points []option.Point, dots map[int]*Dot, padding int) bool {
+	if len(points) != len(dots) {
+		return false
+	}
+
+	targets := make([]*Dot, 0, len(dots))
+	for _, dot := range dots {
+		targets = append(targets, dot)
+	}
+
+	// match clicks to dots by augmenting paths, a click inside two overlapping dots may take either
+	matched := make([]int, len(targets))
+	for i := range matched {
+		matched[i] = -1
+	}
+	var assign func(p int, seen []bool) bool
+	assign = func(p int, seen []bool) bool {
+		for i, dot := range targets {
+			if seen[i] || !Validate(points[p].X, points[p].Y, dot.X, dot.Y, dot.Width, dot.Height, padding) {
+				continue
+			}
+			seen[i] = true
+			if matched[i] < 0 || assign(matched[i], seen) {
+				matched[i] = p
+				return true
+			}
+		}
+		return false
+	}
+
+	for p := range points {
+		if !assign(p, make([]bool, len(targets))) {
+			return false
+		}
+	}
+	return true
+}
+
 // Deprecated: As of 2.1.0, it will be removed, please use [click.Validate]
 func CheckPoint(sx, sy, dx, dy, width, height, padding int64) bool {
 	newWidth := width + (padding * 2)
@@ -0,0 +1,425 @@
+'use strict';
+
+// go-captcha preview, every option of /api/schema is rendered as a form control,
+// the preview regenerates on each change and the config can be exported
+
+const state = {
+  kinds: {},
+  kind: '',
+  mode: '',
+  fields: [],
+  values: {},
+  answer: null,
+  exported: null,
+  seq: 0,
+  timer: 0,
+};
+
+const $ = (id) => document.getElementById(id);
+const clone = (v) => JSON.parse(JSON.stringify(v));
+const same = (a, b) => JSON.stringify(a) === JSON.stringify(b);
+
+async function api(path, body) {
+  const init = body === undefined ? {} : {
+    method: 'POST',
+    headers: {'Content-Type': 'application/json'},
+    body: JSON.stringify(body),
+  };
+  const resp = await fetch(path, init);
+  const data = await resp.json();
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+function setStatus(text, isError) {
+  $('status').textContent = text;
+  $('status').className = isError ? 'error' : '';
+}
+
+function fillSelect(select, items, selected) {
+  select.innerHTML = '';
+  for (const item of items) {
+    const opt = document.createElement('option');
+    opt.value = opt.textContent = item;
+    opt.selected = item === selected;
+    select.appendChild(opt);
+  }
+}
+
+async function init() {
+  const cfg = await api('/api/config');
+  state.kinds = cfg.kinds;
+  state.kind = cfg.kind;
+  state.mode = cfg.mode;
+  fillSelect($('kind'), Object.keys(cfg.kinds).sort(), state.kind);
+  fillSelect($('mode'), cfg.kinds[state.kind], state.mode);
+  await loadSchema(cfg.options || {});
+}
+
+// loadSchema loads the fields of the current kind and mode, preset values override the defaults
+async function loadSchema(preset) {
+  state.fields = await api(`/api/schema?kind=${state.kind}&mode=${state.mode}`);
+  state.values = {};
+  for (const f of state.fields) {
+    state.values[f.name] = clone(f.name in preset ? preset[f.name] : f.default);
+  }
+  renderForm();
+  generate();
+}
+
+function changedValues() {
+  const out = {};
+  for (const f of state.fields) {
+    if (!same(state.values[f.name], f.default)) {
+      out[f.name] = clone(state.values[f.name]);
+    }
+  }
+  return out;
+}
+
+// Form
+
+function renderForm() {
+  const form = $('options');
+  form.innerHTML = '';
+  for (const f of state.fields) {
+    const div = document.createElement('div');
+    div.className = 'field';
+    div.dataset.name = f.name;
+
+    const label = document.createElement('label');
+    label.textContent = f.name;
+    label.title = 'default: ' + JSON.stringify(f.default);
+    if (f.bounds) {
+      label.title += `, from ${f.bounds[0]} to ${f.bounds[1]}`;
+    }
+    div.appendChild(label);
+
+    renderControl(div, f);
+    form.appendChild(div);
+  }
+  markChanged();
+}
+
+function update(f, value) {
+  state.values[f.name] = value;
+  markChanged();
+  schedule();
+}
+
+function markChanged() {
+  for (const div of $('options').children) {
+    const f = state.fields.find((x) => x.name === div.dataset.name);
+    div.classList.toggle('changed', !same(state.values[f.name], f.default));
+  }
+}
+
+function numberInput(value, step, onChange, title) {
+  const input = document.createElement('input');
+  input.type = 'number';
+  input.step = step;
+  input.value = value;
+  if (title) {
+    input.title = input.placeholder = title;
+  }
+  input.addEventListener('input', () => {
+    if (input.value !== '') {
+      onChange(Number(input.value));
+    }
+  });
+  return input;
+}
+
+// bounded sets the bounds of a field on a number input, the server rejects values out of them
+function bounded(input, f) {
+  if (f.bounds) {
+    [input.min, input.max] = f.bounds;
+  }
+  return input;
+}
+
+function colorInput(value, onChange) {
+  const wrap = document.createElement('span');
+  wrap.className = 'swatch';
+  const picker = document.createElement('input');
+  picker.type = 'color';
+  const text = document.createElement('input');
+  text.type = 'text';
+  text.size = 9;
+  text.value = value;
+  const sync = () => {
+    if (/^#[0-9a-f]{6}$/i.test(text.value)) {
+      picker.value = text.value;
+    }
+  };
+  sync();
+  picker.addEventListener('input', () => {
+    text.value = picker.value;
+    onChange(picker.value);
+  });
+  text.addEventListener('change', () => {
+    sync();
+    onChange(text.value);
+  });
+  wrap.append(picker, ' ', text);
+  return wrap;
+}
+
+// pairInput edits [a, b] values of sizes, points and ranges
+function pairInput(value, names, onChange) {
+  const row = document.createElement('span');
+  row.className = 'row';
+  const pair = value.slice();
+  names.forEach((name, i) => {
+    row.append(name, numberInput(pair[i], 1, (v) => {
+      pair[i] = v;
+      onChange(pair.slice());
+    }));
+  });
+  return row;
+}
+
+// listInput edits a list of items, each rendered by itemInput
+function listInput(div, f, itemInput, newItem) {
+  const list = state.values[f.name];
+  const render = () => {
+    div.querySelectorAll('.row, .add').forEach((el) => el.remove());
+    list.forEach((item, i) => {
+      const row = document.createElement('div');
+      row.className = 'row';
+      row.appendChild(itemInput(item, (v) => {
+        list[i] = v;
+        update(f, list.slice());
+      }));
+      const remove = document.createElement('button');
+      remove.type = 'button';
+      remove.textContent = '−';
+      remove.title = 'remove';
+      remove.addEventListener('click', () => {
+        list.splice(i, 1);
+        render();
+        update(f, list.slice());
+      });
+      row.appendChild(remove);
+      div.appendChild(row);
+    });
+    const add = document.createElement('button');
+    add.type = 'button';
+    add.className = 'add';
+    add.textContent = '+';
+    add.title = 'add';
+    add.addEventListener('click', () => {
+      list.push(list.length ? clone(list[list.length - 1]) : newItem);
+      render();
+      update(f, list.slice());
+    });
+    div.appendChild(add);
+  };
+  render();
+}
+
+function renderControl(div, f) {
+  const value = state.values[f.name];
+  switch (f.type) {
+    case 'int':
+      div.appendChild(bounded(numberInput(value, 1, (v) => update(f, Math.round(v))), f));
+      break;
+    case 'float':
+      div.appendChild(bounded(numberInput(value, 0.05, (v) => update(f, v)), f));
+      break;
+    case 'bool': {
+      const input = document.createElement('input');
+      input.type = 'checkbox';
+      input.checked = value;
+      input.addEventListener('change', () => update(f, input.checked));
+      div.appendChild(input);
+      break;
+    }
+    case 'color':
+      div.appendChild(colorInput(value, (v) => update(f, v)));
+      break;
+    case 'colors':
+      listInput(div, f, (item, onChange) => colorInput(item, onChange), '#000000');
+      break;
+    case 'size':
+      div.appendChild(pairInput(value, ['width', 'height'], (v) => update(f, v)));
+      break;
+    case 'point':
+      div.appendChild(pairInput(value, ['x', 'y'], (v) => update(f, v)));
+      break;
+    case 'range':
+      div.appendChild(pairInput(value, ['min', 'max'], (v) => update(f, v)));
+      break;
+    case 'ranges':
+      listInput(div, f, (item, onChange) => pairInput(item, ['min', 'max'], onChange), [0, 0]);
+      break;
+    case 'ints':
+      listInput(div, f, (item, onChange) => numberInput(item, 1, (v) => onChange(Math.round(v))), 0);
+      break;
+    case 'select': {
+      const select = document.createElement('select');
+      fillSelect(select, f.choices, value);
+      select.addEventListener('change', () => update(f, select.value));
+      div.appendChild(select);
+      break;
+    }
+    case 'multi': {
+      const row = document.createElement('span');
+      row.className = 'row';
+      for (const choice of f.choices) {
+        const label = document.createElement('label');
+        const input = document.createElement('input');
+        input.type = 'checkbox';
+        input.checked = value.includes(choice);
+        input.addEventListener('change', () => {
+          const checked = f.choices.filter((c, i) => row.querySelectorAll('input')[i].checked);
+          update(f, checked);
+        });
+        label.append(input, ' ', choice);
+        row.appendChild(label);
+      }
+      div.appendChild(row);
+      break;
+    }
+  }
+}
+
+// Preview
+
+function schedule() {
+  clearTimeout(state.timer);
+  state.timer = setTimeout(generate, 250);
+}
+
+async function generate() {
+  const seq = ++state.seq;
+  setStatus('generating…');
+  try {
+    const data = await api('/api/generate', {kind: state.kind, mode: state.mode, options: changedValues()});
+    if (seq !== state.seq) {
+      return;
+    }
+    state.answer = data.answer;
+    $('thumb-name').textContent = data.thumb_name;
+    $('thumb').src = data.thumb;
+    $('master').onload = drawAnswer;
+    $('master').src = data.master;
+    $('answer').textContent = JSON.stringify(data.answer, null, 2);
+    setStatus(`generated in ${data.elapsed}`);
+  } catch (err) {
+    if (seq === state.seq) {
+      setStatus(err.message, true);
+    }
+  }
+}
+
+// drawAnswer draws the answer over the master image
+function drawAnswer() {
+  const img = $('master');
+  const canvas = $('answer-canvas');
+  canvas.width = img.naturalWidth;
+  canvas.height = img.naturalHeight;
+  const ctx = canvas.getContext('2d');
+  ctx.clearRect(0, 0, canvas.width, canvas.height);
+
+  const answer = state.answer;
+  if (!answer || !$('overlay').checked) {
+    return;
+  }
+
+  ctx.lineWidth = 2;
+  ctx.font = 'bold 12px sans-serif';
+  ctx.textAlign = 'center';
+  ctx.textBaseline = 'middle';
+
+  if (answer.kind === 'click') {
+    for (const key of Object.keys(answer.dots).sort((a, b) => a - b)) {
+      const dot = answer.dots[key];
+      ctx.strokeStyle = '#ff2d55';
+      ctx.strokeRect(dot.x, dot.y, dot.width, dot.height);
+      ctx.fillStyle = '#ff2d55';
+      ctx.beginPath();
+      ctx.arc(dot.x, dot.y, 9, 0, Math.PI * 2);
+      ctx.fill();
+      ctx.fillStyle = '#fff';
+      ctx.fillText(String(dot.index + 1), dot.x, dot.y + 1);
+    }
+  } else if (answer.kind === 'slide') {
+    const b = answer.block;
+    ctx.strokeStyle = '#ff2d55';
+    ctx.strokeRect(b.x, b.y, b.width, b.height);
+    ctx.setLineDash([4, 3]);
+    ctx.strokeStyle = '#0a84ff';
+    ctx.strokeRect(b.tile_x, b.tile_y, b.width, b.height);
+    ctx.beginPath();
+    ctx.moveTo(b.tile_x + b.width / 2, b.tile_y + b.height / 2);
+    ctx.lineTo(b.x + b.width / 2, b.y + b.height / 2);
+    ctx.stroke();
+    ctx.setLineDash([]);
+  } else if (answer.kind === 'rotate') {
+    // the thumb is upright after turning it clockwise by 360 - angle degrees
+    const turn = (360 - answer.block.angle) % 360;
+    const cx = canvas.width / 2;
+    const cy = canvas.height / 2;
+    const r = Math.min(cx, cy) * 0.35;
+    const start = -Math.PI / 2;
+    const end = start + turn * Math.PI / 180;
+    ctx.strokeStyle = '#ff2d55';
+    ctx.beginPath();
+    ctx.arc(cx, cy, r, start, end);
+    ctx.stroke();
+    ctx.beginPath();
+    ctx.moveTo(cx, cy);
+    ctx.lineTo(cx + r * Math.cos(end), cy + r * Math.sin(end));
+    ctx.stroke();
+    ctx.fillStyle = '#ff2d55';
+    ctx.fillText(`${turn}°`, cx, cy + r + 14);
+  }
+}
+
+// Export
+
+async function exportConfig(format) {
+  try {
+    const data = await api('/api/export', {kind: state.kind, mode: state.mode, options: changedValues()});
+    state.exported = {
+      text: format === 'go' ? data.go : data.json,
+      name: format === 'go' ? 'captcha.go' : 'captcha.json',
+    };
+    $('export-output').textContent = state.exported.text;
+    $('copy').disabled = $('download').disabled = false;
+  } catch (err) {
+    setStatus(err.message, true);
+  }
+}
+
+function download() {
+  const blob = new Blob([state.exported.text], {type: 'text/plain'});
+  const a = document.createElement('a');
+  a.href = URL.createObjectURL(blob);
+  a.download = state.exported.name;
+  a.click();
+  URL.revokeObjectURL(a.href);
+}
+
+$('kind').addEventListener('change', () => {
+  state.kind = $('kind').value;
+  state.mode = state.kinds[state.kind][0];
+  fillSelect($('mode'), state.kinds[state.kind], state.mode);
+  loadSchema({});
+});
+$('mode').addEventListener('change', () => {
+  state.mode = $('mode').value;
+  loadSchema(changedValues());
+});
+$('regenerate').addEventListener('click', generate);
+$('reset').addEventListener('click', () => loadSchema({}));
+$('overlay').addEventListener('change', drawAnswer);
+$('export-go').addEventListener('click', () => exportConfig('go'));
+$('export-json').addEventListener('click', () => exportConfig('json'));
+$('copy').addEventListener('click', () => navigator.clipboard.writeText(state.exported.text));
+$('download').addEventListener('click', download);
+
+init().catch((err) => setStatus(err.message, true));
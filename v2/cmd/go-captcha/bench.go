@@ -0,0 +1,122 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runBench generates captchas from concurrent workers and reports the throughput and latency
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	cfg := &config{}
+	cfg.register(fs)
+	n := fs.Int("n", 200, "number of captchas")
+	workers := fs.Int("c", runtime.GOMAXPROCS(0), "number of concurrent workers")
+	encode := fs.Bool("encode", true, "include encoding the images in the latency")
+	warmup := fs.Int("warmup", 4, "number of captchas generated before measuring")
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *n <= 0 || *workers <= 0 {
+		return fmt.Errorf("-n and -c must be greater than 0")
+	}
+
+	gen, err := newGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	run := func() error {
+		s, err := gen()
+		if err != nil {
+			return err
+		}
+		if *encode {
+			return s.encode()
+		}
+		return nil
+	}
+
+	for i := 0; i < *warmup; i++ {
+		if err = run(); err != nil {
+			return err
+		}
+	}
+
+	latencies := make([]time.Duration, *n)
+	var next int64 = -1
+	var firstErr error
+	var errOnce sync.Once
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddInt64(&next, 1)
+				if i >= int64(*n) {
+					return
+				}
+
+				t := time.Now()
+				if err := run(); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					return
+				}
+				latencies[i] = time.Since(t)
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+
+	fmt.Printf("%s %s: %d captchas, %d workers, encode %v\n", cfg.kind, cfg.mode, *n, *workers, *encode)
+	fmt.Printf("elapsed    %v\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("throughput %.1f/s\n", float64(*n)/elapsed.Seconds())
+	fmt.Printf("latency    min %v  mean %v  p50 %v  p90 %v  p99 %v  max %v\n",
+		round(latencies[0]),
+		round(total/time.Duration(*n)),
+		round(percentile(latencies, 50)),
+		round(percentile(latencies, 90)),
+		round(percentile(latencies, 99)),
+		round(latencies[len(latencies)-1]))
+
+	return nil
+}
+
+// percentile returns the p-th percentile of the sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return sorted[i]
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
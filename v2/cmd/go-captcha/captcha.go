@@ -0,0 +1,387 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v2 "github.com/wenlng/go-captcha/v2"
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/debug"
+	"github.com/wenlng/go-captcha/v2/rotate"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+const (
+	kindClick  = "click"
+	kindSlide  = "slide"
+	kindRotate = "rotate"
+
+	modeText      = "text"
+	modeShape     = "shape"
+	modeOddOneOut = "odd-one-out"
+	modeCount     = "count"
+	modeBasic     = "basic"
+	modeDrag      = "drag"
+)
+
+// kindModes lists the modes of every kind, the first one is the default
+var kindModes = map[string][]string{
+	kindClick:  {modeText, modeShape, modeOddOneOut, modeCount},
+	kindSlide:  {modeBasic, modeDrag},
+	kindRotate: {modeBasic},
+}
+
+// config is the captcha selection shared by the commands
+type config struct {
+	kind   string
+	mode   string
+	resDir string
+	width  int
+	height int
+	locale string
+	chars  string
+
+	configFile string
+	options    []setOption
+}
+
+// register adds the config flags to fs
+func (c *config) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.kind, "kind", kindClick, "captcha kind: click, slide or rotate")
+	fs.StringVar(&c.mode, "mode", "", "captcha mode: text, shape, odd-one-out or count for click, basic or drag for slide, basic for rotate")
+	fs.StringVar(&c.resDir, "res", "", "resource directory, see \"go-captcha validate -h\" for its layout")
+	fs.IntVar(&c.width, "width", 0, "image width, the square size for rotate, 0 for the default")
+	fs.IntVar(&c.height, "height", 0, "image height, 0 for the default")
+	fs.StringVar(&c.locale, "locale", "en", "locale of the default click chars")
+	fs.StringVar(&c.chars, "chars", "", "comma separated click chars, overrides -locale")
+	fs.StringVar(&c.configFile, "config", "", "JSON config file as exported by \"go-captcha serve\" or printed by \"go-captcha defaults\"")
+}
+
+// parse parses the flags and loads the config file, -kind and -mode take precedence over the file
+func (c *config) parse(fs *flag.FlagSet, args []string) error {
+	fs.Parse(args)
+	if c.configFile == "" {
+		return c.check()
+	}
+	return c.load(fs, c.configFile)
+}
+
+// load loads the kind, mode and options of a config file, -kind and -mode take precedence over the file
+func (c *config) load(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var file captchaConfig
+	if err = json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["kind"] && file.Kind != "" {
+		c.kind = file.Kind
+	}
+	if !set["mode"] && file.Mode != "" {
+		c.mode = file.Mode
+	}
+	if err = c.check(); err != nil {
+		return err
+	}
+
+	c.options, err = decodeOptions(c.kind, c.mode, file.Options)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// check validates the kind and mode, an empty mode is set to the default mode of the kind
+func (c *config) check() error {
+	modes, ok := kindModes[c.kind]
+	if !ok {
+		return fmt.Errorf("unknown kind %q", c.kind)
+	}
+	if c.mode == "" {
+		c.mode = modes[0]
+	}
+	for _, m := range modes {
+		if m == c.mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown mode %q of kind %s, use one of %s", c.mode, c.kind, strings.Join(modes, ", "))
+}
+
+// clickChars returns the chars of click text mode
+func (c *config) clickChars() ([]string, error) {
+	if c.chars != "" {
+		return strings.Split(c.chars, ","), nil
+	}
+	chars, ok := click.LocaleChars(c.locale)
+	if !ok {
+		return nil, fmt.Errorf("no default chars for locale %q", c.locale)
+	}
+	return chars, nil
+}
+
+// answer is the JSON written next to the generated images
+type answer struct {
+	Kind  string             `json:"kind"`
+	Mode  string             `json:"mode"`
+	Dots  map[int]*click.Dot `json:"dots,omitempty"`
+	Block interface{}        `json:"block,omitempty"`
+}
+
+// sample is one generated captcha
+type sample struct {
+	answer    *answer
+	master    image.Image
+	masterExt string
+	thumb     image.Image
+	thumbName string
+
+	masterBytes func() ([]byte, error)
+	thumbBytes  func() ([]byte, error)
+}
+
+// debugImage returns the master image with the answer drawn on top
+func (s *sample) debugImage() image.Image {
+	return drawAnswer(s.master, s.answer)
+}
+
+// drawAnswer draws an answer on top of a master image
+func drawAnswer(master image.Image, a *answer) image.Image {
+	switch block := a.Block.(type) {
+	case *slide.Block:
+		return debug.DrawSlideBlock(master, block)
+	case *rotate.Block:
+		return debug.DrawRotateBlock(master, block)
+	}
+	return debug.DrawClickDots(master, a.Dots)
+}
+
+// encode encodes the images the way a server responds with them
+func (s *sample) encode() error {
+	if _, err := s.masterBytes(); err != nil {
+		return err
+	}
+	_, err := s.thumbBytes()
+	return err
+}
+
+// generator generates a sample, it is safe for concurrent use
+type generator func() (*sample, error)
+
+// newGenerator loads the resources of the config and makes the captcha
+// params:
+//   - cfg: Parsed captcha selection
+//
+// return: Generator of the captcha, error when the resources are invalid
+func newGenerator(cfg *config) (generator, error) {
+	res, problems, err := loadResources(cfg.resDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range problems {
+		if !p.warning {
+			return nil, fmt.Errorf("%s: %v", p.path, p.err)
+		}
+	}
+
+	return makeGenerator(cfg, res)
+}
+
+// makeGenerator makes the captcha of the config from loaded resources
+func makeGenerator(cfg *config, res *resources) (generator, error) {
+	switch cfg.kind {
+	case kindClick:
+		return newClickGenerator(cfg, res)
+	case kindSlide:
+		return newSlideGenerator(cfg, res)
+	default:
+		return newRotateGenerator(cfg, res)
+	}
+}
+
+// missing is the error of a missing resource
+func missing(cfg *config, what, dir string) error {
+	if cfg.resDir == "" {
+		return fmt.Errorf("no %s, set -res to a resource directory with a %s directory", what, dir)
+	}
+	return fmt.Errorf("no %s in %s", what, filepath.Join(cfg.resDir, dir))
+}
+
+func newClickGenerator(cfg *config, res *resources) (generator, error) {
+	capt, err := makeClickCaptcha(cfg, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (*sample, error) {
+		data, err := capt.Generate()
+		if err != nil {
+			return nil, err
+		}
+		return &sample{
+			answer:      &answer{Kind: cfg.kind, Mode: cfg.mode, Dots: data.GetData()},
+			master:      data.GetMasterImage().Get(),
+			masterExt:   ".jpg",
+			thumb:       data.GetThumbImage().Get(),
+			thumbName:   "thumb",
+			masterBytes: data.GetMasterImage().ToBytes,
+			thumbBytes:  data.GetThumbImage().ToBytes,
+		}, nil
+	}, nil
+}
+
+// makeClickCaptcha makes the click captcha of the config
+func makeClickCaptcha(cfg *config, res *resources) (click.Captcha, error) {
+	builder := v2.NewClickBuilder()
+	for _, opt := range captchaOptions(cfg.options) {
+		builder.SetOptions(opt.(click.Option))
+	}
+	if cfg.width > 0 && cfg.height > 0 {
+		builder.SetOptions(click.WithImageSize(option.Size{Width: cfg.width, Height: cfg.height}))
+	}
+	if len(res.backgrounds) > 0 {
+		builder.SetResources(click.WithBackgrounds(res.backgrounds))
+	}
+	if len(res.thumbBackgrounds) > 0 {
+		builder.SetResources(click.WithThumbBackgrounds(res.thumbBackgrounds))
+	}
+
+	// the odd-one-out and counting modes draw the shapes when there are any, characters otherwise
+	if cfg.mode == modeShape && len(res.shapes) == 0 {
+		return nil, missing(cfg, "shape images", shapesDir)
+	}
+	if cfg.mode != modeText && len(res.shapes) > 0 {
+		builder.SetResources(click.WithShapes(res.shapes))
+	} else {
+		chars, err := cfg.clickChars()
+		if err != nil {
+			return nil, err
+		}
+		fonts := res.fonts
+		if len(fonts) == 0 {
+			fonts = defaultFonts()
+		}
+		builder.SetResources(click.WithChars(chars), click.WithSfntFonts(fonts))
+	}
+
+	switch cfg.mode {
+	case modeShape:
+		return builder.MakeShape(), nil
+	case modeOddOneOut:
+		return builder.MakeOddOneOut(), nil
+	case modeCount:
+		return builder.MakeCount(), nil
+	}
+	return builder.Make(), nil
+}
+
+func newSlideGenerator(cfg *config, res *resources) (generator, error) {
+	capt, err := makeSlideCaptcha(cfg, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (*sample, error) {
+		data, err := capt.Generate()
+		if err != nil {
+			return nil, err
+		}
+		return &sample{
+			answer:      &answer{Kind: cfg.kind, Mode: cfg.mode, Block: data.GetData()},
+			master:      data.GetMasterImage().Get(),
+			masterExt:   ".jpg",
+			thumb:       data.GetTileImage().Get(),
+			thumbName:   "tile",
+			masterBytes: data.GetMasterImage().ToBytes,
+			thumbBytes:  data.GetTileImage().ToBytes,
+		}, nil
+	}, nil
+}
+
+// makeSlideCaptcha makes the slide captcha of the config
+func makeSlideCaptcha(cfg *config, res *resources) (slide.Captcha, error) {
+	if len(res.backgrounds) == 0 {
+		return nil, missing(cfg, "background images", backgroundsDir)
+	}
+	if len(res.tiles) == 0 {
+		return nil, missing(cfg, "tiles", tilesDir)
+	}
+
+	builder := v2.NewSlideBuilder()
+	for _, opt := range captchaOptions(cfg.options) {
+		builder.SetOptions(opt.(slide.Option))
+	}
+	if cfg.width > 0 && cfg.height > 0 {
+		builder.SetOptions(slide.WithImageSize(option.Size{Width: cfg.width, Height: cfg.height}))
+	}
+	builder.SetResources(slide.WithBackgrounds(res.backgrounds), slide.WithGraphImages(res.tiles))
+
+	var capt slide.Captcha
+	if cfg.mode == modeDrag {
+		capt = builder.MakeDragDrop()
+	} else {
+		capt = builder.Make()
+	}
+	return capt, nil
+}
+
+func newRotateGenerator(cfg *config, res *resources) (generator, error) {
+	capt, err := makeRotateCaptcha(cfg, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (*sample, error) {
+		data, err := capt.Generate()
+		if err != nil {
+			return nil, err
+		}
+		return &sample{
+			answer:      &answer{Kind: cfg.kind, Mode: cfg.mode, Block: data.GetData()},
+			master:      data.GetMasterImage().Get(),
+			masterExt:   ".png",
+			thumb:       data.GetThumbImage().Get(),
+			thumbName:   "thumb",
+			masterBytes: data.GetMasterImage().ToBytes,
+			thumbBytes:  data.GetThumbImage().ToBytes,
+		}, nil
+	}, nil
+}
+
+// makeRotateCaptcha makes the rotate captcha of the config
+func makeRotateCaptcha(cfg *config, res *resources) (rotate.Captcha, error) {
+	images := res.images
+	if len(images) == 0 {
+		images = res.backgrounds
+	}
+	if len(images) == 0 {
+		return nil, missing(cfg, "images", imagesDir)
+	}
+
+	builder := v2.NewRotateBuilder()
+	for _, opt := range captchaOptions(cfg.options) {
+		builder.SetOptions(opt.(rotate.Option))
+	}
+	if cfg.width > 0 {
+		builder.SetOptions(rotate.WithImageSquareSize(cfg.width))
+	}
+	builder.SetResources(rotate.WithImages(images))
+	return builder.Make(), nil
+}
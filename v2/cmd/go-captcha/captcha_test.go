@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig writes a config file to a temporary directory
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// parseConfig parses args with the config flags
+func parseConfig(t *testing.T, args ...string) (*config, error) {
+	t.Helper()
+	cfg := &config{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.register(fs)
+	return cfg, cfg.parse(fs, args)
+}
+
+func TestConfigLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `{"kind": "slide", "mode": "drag", "options": {"WithDecoyNumber": 2}}`)
+
+	cases := []struct {
+		args       []string
+		kind, mode string
+		options    int
+		err        bool
+	}{
+		{args: []string{"-config", path}, kind: kindSlide, mode: modeDrag, options: 1},
+		{args: []string{"-config", path, "-mode", modeBasic}, kind: kindSlide, mode: modeBasic, options: 1},
+		{args: []string{"-kind", kindClick}, kind: kindClick, mode: modeText},
+		// -kind wins, and the slide options of the file are unknown to rotate
+		{args: []string{"-config", path, "-kind", kindRotate, "-mode", modeBasic}, err: true},
+	}
+	for _, c := range cases {
+		cfg, err := parseConfig(t, c.args...)
+		if c.err {
+			if err == nil {
+				t.Fatalf("%v: loaded as %s %s", c.args, cfg.kind, cfg.mode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", c.args, err)
+		}
+		if cfg.kind != c.kind || cfg.mode != c.mode || len(cfg.options) != c.options {
+			t.Fatalf("%v: got %s %s with %d options", c.args, cfg.kind, cfg.mode, len(cfg.options))
+		}
+	}
+}
+
+func TestConfigLoadDefaultMode(t *testing.T) {
+	path := writeConfig(t, `{"kind": "click"}`)
+	cfg, err := parseConfig(t, "-config", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.kind != kindClick || cfg.mode != modeText {
+		t.Fatalf("got %s %s", cfg.kind, cfg.mode)
+	}
+
+	// a mode of another kind is rejected
+	path = writeConfig(t, `{"kind": "rotate", "mode": "drag"}`)
+	if _, err = parseConfig(t, "-config", path); err == nil {
+		t.Fatal("loaded a drag rotate captcha")
+	}
+}
@@ -0,0 +1,92 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/slide"
+	"golang.org/x/image/font"
+)
+
+// runDefaults dumps the effective default options, read through the option getters, as a
+// config that can be edited and passed to -config
+func runDefaults(args []string) error {
+	fs := flag.NewFlagSet("defaults", flag.ExitOnError)
+	cfg := &config{}
+	fs.StringVar(&cfg.kind, "kind", kindClick, "captcha kind: click, slide or rotate")
+	fs.StringVar(&cfg.mode, "mode", "", "captcha mode: text, shape, odd-one-out or count for click, basic or drag for slide, basic for rotate")
+	fs.Parse(args)
+
+	if err := cfg.check(); err != nil {
+		return err
+	}
+
+	out := captchaConfig{Kind: cfg.kind, Mode: cfg.mode, Options: make(map[string]json.RawMessage)}
+	for _, f := range optionFields(cfg.kind, cfg.mode) {
+		raw, err := json.Marshal(f.Default)
+		if err != nil {
+			return err
+		}
+		out.Options[f.Name] = raw
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(data))
+	return err
+}
+
+func hintingName(h font.Hinting) string {
+	switch h {
+	case font.HintingNone:
+		return "none"
+	case font.HintingVertical:
+		return "vertical"
+	}
+	return "full"
+}
+
+func textDirectionName(d click.TextDirection) string {
+	switch d {
+	case click.TextDirectionLTR:
+		return "ltr"
+	case click.TextDirectionRTL:
+		return "rtl"
+	}
+	return "auto"
+}
+
+func promptAttributeName(a click.Attribute) string {
+	switch a {
+	case click.AttributeColor:
+		return "color"
+	case click.AttributeSize:
+		return "size"
+	case click.AttributeColor | click.AttributeSize:
+		return "color+size"
+	}
+	return "none"
+}
+
+func deadZoneDirectionName(d slide.DeadZoneDirectionType) string {
+	switch d {
+	case slide.DeadZoneDirectionTypeRight:
+		return "right"
+	case slide.DeadZoneDirectionTypeTop:
+		return "top"
+	case slide.DeadZoneDirectionTypeBottom:
+		return "bottom"
+	}
+	return "left"
+}
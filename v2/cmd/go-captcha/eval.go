@@ -0,0 +1,116 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/wenlng/go-captcha/v2/eval"
+)
+
+// runEval runs the baseline attackers of the eval package against one or more configs
+// and reports their success rates side by side
+func runEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	cfg := &config{}
+	cfg.register(fs)
+	n := fs.Int("n", eval.DefaultTrials, "number of challenges per config")
+	padding := fs.Int("padding", eval.DefaultPadding, "padding passed to the validators, in pixels or degrees")
+	workers := fs.Int("c", runtime.GOMAXPROCS(0), "number of concurrent workers")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-captcha eval [flags] [config.json ...]\n\n")
+		fmt.Fprintf(fs.Output(), "Every config file is evaluated with the same resources, without files the flags are.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *n <= 0 || *workers <= 0 {
+		return fmt.Errorf("-n and -c must be greater than 0")
+	}
+
+	res, problems, err := loadResources(cfg.resDir)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		if !p.warning {
+			return fmt.Errorf("%s: %v", p.path, p.err)
+		}
+	}
+
+	names := []string{"default"}
+	if cfg.configFile != "" {
+		names[0] = configName(cfg.configFile)
+	}
+	configs := []*config{cfg}
+	if fs.NArg() > 0 {
+		names, configs = nil, nil
+		modeSet := false
+		fs.Visit(func(f *flag.Flag) { modeSet = modeSet || f.Name == "mode" })
+		for _, path := range fs.Args() {
+			c := *cfg
+			c.options = nil
+			if !modeSet {
+				c.mode = ""
+			}
+			if err = c.load(fs, path); err != nil {
+				return err
+			}
+			names = append(names, configName(path))
+			configs = append(configs, &c)
+		}
+	}
+
+	opts := []eval.Option{eval.WithTrials(*n), eval.WithPadding(*padding), eval.WithWorkers(*workers)}
+	var results []*eval.Result
+	for i, c := range configs {
+		rs, err := evaluateConfig(names[i], c, res, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %v", names[i], err)
+		}
+		results = append(results, rs...)
+	}
+
+	return eval.WriteReport(os.Stdout, results)
+}
+
+// evaluateConfig makes the captcha of a config and runs the default attackers of its kind
+func evaluateConfig(name string, cfg *config, res *resources, opts []eval.Option) ([]*eval.Result, error) {
+	switch cfg.kind {
+	case kindClick:
+		capt, err := makeClickCaptcha(cfg, res)
+		if err != nil {
+			return nil, err
+		}
+		return eval.EvaluateClick(name, capt, nil, opts...)
+	case kindSlide:
+		capt, err := makeSlideCaptcha(cfg, res)
+		if err != nil {
+			return nil, err
+		}
+		return eval.EvaluateSlide(name, capt, nil, opts...)
+	default:
+		capt, err := makeRotateCaptcha(cfg, res)
+		if err != nil {
+			return nil, err
+		}
+		return eval.EvaluateRotate(name, capt, nil, opts...)
+	}
+}
+
+// configName names a config in the report after its file
+func configName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,98 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// runGenerate generates captchas to files, every captcha is written as
+// <kind>-<N>-master.<ext>, <kind>-<N>-<thumb|tile>.png and <kind>-<N>-answer.json,
+// with -debug the master image with the answer drawn on top is written as <kind>-<N>-debug.png
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	cfg := &config{}
+	cfg.register(fs)
+	n := fs.Int("n", 1, "number of captchas")
+	out := fs.String("out", ".", "output directory")
+	withDebug := fs.Bool("debug", false, "also write the master image with the answer drawn on top")
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	gen, err := newGenerator(cfg)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+
+	for i := 1; i <= *n; i++ {
+		s, err := gen()
+		if err != nil {
+			return err
+		}
+
+		prefix := filepath.Join(*out, fmt.Sprintf("%s-%03d", cfg.kind, i))
+		if err = writeSample(prefix, s); err != nil {
+			return err
+		}
+		if *withDebug {
+			if err = writePNG(prefix+"-debug.png", s.debugImage()); err != nil {
+				return err
+			}
+		}
+		fmt.Println(prefix + "-answer.json")
+	}
+
+	return nil
+}
+
+// writeSample writes the images and answer of a sample
+func writeSample(prefix string, s *sample) error {
+	master, err := s.masterBytes()
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(prefix+"-master"+s.masterExt, master, 0o644); err != nil {
+		return err
+	}
+
+	thumb, err := s.thumbBytes()
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(prefix+"-"+s.thumbName+".png", thumb, 0o644); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.answer, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(prefix+"-answer.json", append(data, '\n'), 0o644)
+}
+
+// writePNG encodes img as a PNG file
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
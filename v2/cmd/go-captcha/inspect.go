@@ -0,0 +1,86 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/rotate"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+// runInspect draws the answer of a captcha on its master image, the answer is the JSON
+// written by generate or any JSON with the click dots or the slide or rotate block
+func runInspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	masterFile := fs.String("master", "", "master image file")
+	answerFile := fs.String("answer", "", "answer JSON file")
+	out := fs.String("out", "debug.png", "output PNG file")
+	fs.Parse(args)
+
+	if *masterFile == "" || *answerFile == "" {
+		fs.Usage()
+		return errors.New("-master and -answer are required")
+	}
+
+	master, err := loadImage(*masterFile)
+	if err != nil {
+		return err
+	}
+	a, err := loadAnswer(*answerFile)
+	if err != nil {
+		return err
+	}
+
+	if err = writePNG(*out, drawAnswer(master, a)); err != nil {
+		return err
+	}
+	fmt.Println(*out)
+	return nil
+}
+
+// loadAnswer reads an answer JSON file
+func loadAnswer(path string) (*answer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Kind  string             `json:"kind"`
+		Mode  string             `json:"mode"`
+		Dots  map[int]*click.Dot `json:"dots"`
+		Block json.RawMessage    `json:"block"`
+	}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	a := &answer{Kind: raw.Kind, Mode: raw.Mode, Dots: raw.Dots}
+	switch raw.Kind {
+	case kindClick:
+	case kindSlide:
+		block := &slide.Block{}
+		err = json.Unmarshal(raw.Block, block)
+		a.Block = block
+	case kindRotate:
+		block := &rotate.Block{}
+		err = json.Unmarshal(raw.Block, block)
+		a.Block = block
+	default:
+		err = fmt.Errorf("unknown kind %q", raw.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return a, nil
+}
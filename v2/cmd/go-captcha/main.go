@@ -0,0 +1,81 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Command go-captcha generates, inspects and benchmarks captchas from the command line
+//
+// Usage:
+//
+//	go-captcha <command> [flags]
+//
+// The commands are:
+//
+//	generate   generate captchas to files with the answer as JSON
+//	sheet      render a contact sheet of samples for design review
+//	validate   validate a resource directory
+//	defaults   dump the effective default options
+//	bench      run throughput and latency benchmarks
+//	serve      serve a local preview page to tweak the options
+//	inspect    draw the answer of a reported captcha on its master image
+//	eval       report how often baseline attackers solve the captchas
+//
+// Run "go-captcha <command> -h" for the flags of a command.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a subcommand of the tool
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []*command{
+	{name: "generate", usage: "generate captchas to files with the answer as JSON", run: runGenerate},
+	{name: "sheet", usage: "render a contact sheet of samples for design review", run: runSheet},
+	{name: "validate", usage: "validate a resource directory", run: runValidate},
+	{name: "defaults", usage: "dump the effective default options", run: runDefaults},
+	{name: "bench", usage: "run throughput and latency benchmarks", run: runBench},
+	{name: "serve", usage: "serve a local preview page to tweak the options", run: runServe},
+	{name: "inspect", usage: "draw the answer of a reported captcha on its master image", run: runInspect},
+	{name: "eval", usage: "report how often baseline attackers solve the captchas", run: runEval},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "go-captcha %s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "go-captcha: unknown command %q\n\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
+
+// usage prints the list of commands
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: go-captcha <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"go-captcha <command> -h\" for the flags of a command.\n")
+}
@@ -0,0 +1,650 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	v2 "github.com/wenlng/go-captcha/v2"
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/rotate"
+	"github.com/wenlng/go-captcha/v2/slide"
+	"golang.org/x/image/font"
+)
+
+// optionType is the value type of an option, it decides the form control,
+// the JSON value and the Go literal of the option
+type optionType string
+
+const (
+	typeInt    optionType = "int"    // 1
+	typeFloat  optionType = "float"  // 0.5
+	typeBool   optionType = "bool"   // true
+	typeColor  optionType = "color"  // "#ff0000"
+	typeColors optionType = "colors" // ["#ff0000", "#00ff00"]
+	typeSize   optionType = "size"   // [width, height]
+	typePoint  optionType = "point"  // [x, y]
+	typeRange  optionType = "range"  // [min, max]
+	typeRanges optionType = "ranges" // [[min, max], [min, max]]
+	typeInts   optionType = "ints"   // [1, 2]
+	typeSelect optionType = "select" // "choice"
+	typeMulti  optionType = "multi"  // ["choice", "choice"]
+)
+
+// optionField is an option of a captcha, named after its option function
+//
+// Only options of plain values are fields, the options taking values built in code, such as
+// the master and thumb filter chains and the background cache, variation and tracker, are
+// not and can't be set by -config or the preview page, add them to the exported code
+type optionField struct {
+	Name    string      `json:"name"`
+	Type    optionType  `json:"type"`
+	Default interface{} `json:"default"`
+	Choices []string    `json:"choices,omitempty"`
+	// Bounds are the smallest and largest numbers of the option, for sizes, points,
+	// ranges and lists they bound every number
+	Bounds *[2]float64 `json:"bounds,omitempty"`
+
+	pkg     string
+	value   interface{}
+	option  func(v interface{}) interface{}
+	goNames map[string]string
+	// goImport is the package of the goNames besides the captcha kind
+	goImport string
+}
+
+// captchaConfig is the JSON of a captcha config, as exported by serve and read by -config
+type captchaConfig struct {
+	Kind    string                     `json:"kind"`
+	Mode    string                     `json:"mode"`
+	Options map[string]json.RawMessage `json:"options,omitempty"`
+}
+
+var (
+	hintingChoices = map[string]font.Hinting{
+		"none":     font.HintingNone,
+		"vertical": font.HintingVertical,
+		"full":     font.HintingFull,
+	}
+	textDirectionChoices = map[string]click.TextDirection{
+		"auto": click.TextDirectionAuto,
+		"ltr":  click.TextDirectionLTR,
+		"rtl":  click.TextDirectionRTL,
+	}
+	promptAttributeChoices = map[string]click.Attribute{
+		"none":       0,
+		"color":      click.AttributeColor,
+		"size":       click.AttributeSize,
+		"color+size": click.AttributeColor | click.AttributeSize,
+	}
+	// optionBounds bounds the numeric options by name, values out of them are rejected
+	// rather than left to fail deep in the drawing
+	optionBounds = map[string][2]float64{
+		"WithImageSize":                 {20, 2000},
+		"WithImageSquareSize":           {20, 2000},
+		"WithImageAlpha":                {0, 1},
+		"WithRangeLen":                  {1, 20},
+		"WithRangeAnglePos":             {0, 360},
+		"WithRangeSize":                 {8, 200},
+		"WithDotSpacing":                {0, 100},
+		"WithDotMargin":                 {0, 100},
+		"WithShadowPoint":               {-20, 20},
+		"WithRangeThumbImageSize":       {20, 1000},
+		"WithRangeVerifyLen":            {1, 20},
+		"WithRangeThumbSize":            {8, 200},
+		"WithRangeThumbBgDistort":       {0, 5},
+		"WithRangeThumbBgCirclesNum":    {0, 100},
+		"WithRangeThumbBgSlimLineNum":   {0, 100},
+		"WithThumbDisturbAlpha":         {0, 1},
+		"WithPerturbStrength":           {0, 1},
+		"WithRangeGraphSize":            {10, 500},
+		"WithRangeGraphAnglePos":        {0, 360},
+		"WithGenGraphNumber":            {1, 10},
+		"WithDecoyNumber":               {0, 10},
+		"WithGapFeather":                {0, 20},
+		"WithGapNoise":                  {0, 1},
+		"WithTileEdgeJitter":            {0, 1},
+		"WithRangeThumbImageSquareSize": {10, 2000},
+		"WithThumbImageAlpha":           {0, 1},
+		"WithRingGap":                   {0, 100},
+		"WithRangeRingBlur":             {0, 20},
+		"WithThumbOcclusion":            {0, 1},
+		"WithThumbScaleJitter":          {0, 1},
+		"WithThumbHueJitter":            {0, 180},
+		"WithBackgroundNoise":           {0, 1},
+	}
+	deadZoneChoices = map[string]slide.DeadZoneDirectionType{
+		"left":   slide.DeadZoneDirectionTypeLeft,
+		"right":  slide.DeadZoneDirectionTypeRight,
+		"top":    slide.DeadZoneDirectionTypeTop,
+		"bottom": slide.DeadZoneDirectionTypeBottom,
+	}
+)
+
+// optionFields returns the options of a captcha kind and mode with their defaults
+func optionFields(kind, mode string) []*optionField {
+	var fields []*optionField
+	switch kind {
+	case kindClick:
+		builder := v2.NewClickBuilder()
+		capt := builder.Make()
+		switch mode {
+		case modeShape:
+			capt = builder.MakeShape()
+		case modeOddOneOut:
+			capt = builder.MakeOddOneOut()
+		case modeCount:
+			capt = builder.MakeCount()
+		}
+		fields = clickFields(capt.GetOptions())
+	case kindSlide:
+		builder := v2.NewSlideBuilder()
+		capt := builder.Make()
+		if mode == modeDrag {
+			capt = builder.MakeDragDrop()
+		}
+		fields = slideFields(capt.GetOptions())
+	case kindRotate:
+		fields = rotateFields(v2.NewRotateBuilder().Make().GetOptions())
+	}
+
+	for _, f := range fields {
+		f.pkg = kind
+		f.Default = f.jsonValue(f.value)
+		if b, ok := optionBounds[f.Name]; ok {
+			f.Bounds = &b
+		}
+	}
+	return fields
+}
+
+func clickFields(o *click.Options) []*optionField {
+	return []*optionField{
+		{Name: "WithImageSize", Type: typeSize, value: *o.GetImageSize(), option: func(v interface{}) interface{} { return click.WithImageSize(v.(option.Size)) }},
+		{Name: "WithImageAlpha", Type: typeFloat, value: o.GetImageAlpha(), option: func(v interface{}) interface{} { return click.WithImageAlpha(v.(float32)) }},
+		{Name: "WithRangeLen", Type: typeRange, value: *o.GetRangeLen(), option: func(v interface{}) interface{} { return click.WithRangeLen(v.(option.RangeVal)) }},
+		{Name: "WithRangeAnglePos", Type: typeRanges, value: derefRanges(o.GetRangeAnglePos()), option: func(v interface{}) interface{} { return click.WithRangeAnglePos(v.([]option.RangeVal)) }},
+		{Name: "WithRangeSize", Type: typeRange, value: *o.GetRangeSize(), option: func(v interface{}) interface{} { return click.WithRangeSize(v.(option.RangeVal)) }},
+		{Name: "WithDotSpacing", Type: typeInt, value: o.GetDotSpacing(), option: func(v interface{}) interface{} { return click.WithDotSpacing(v.(int)) }},
+		{Name: "WithDotMargin", Type: typeInt, value: o.GetDotMargin(), option: func(v interface{}) interface{} { return click.WithDotMargin(v.(int)) }},
+		{Name: "WithRangeColors", Type: typeColors, value: o.GetRangeColors(), option: func(v interface{}) interface{} { return click.WithRangeColors(v.([]string)) }},
+		{Name: "WithDisplayShadow", Type: typeBool, value: o.GetDisplayShadow(), option: func(v interface{}) interface{} { return click.WithDisplayShadow(v.(bool)) }},
+		{Name: "WithShadowColor", Type: typeColor, value: o.GetShadowColor(), option: func(v interface{}) interface{} { return click.WithShadowColor(v.(string)) }},
+		{Name: "WithShadowPoint", Type: typePoint, value: *o.GetShadowPoint(), option: func(v interface{}) interface{} { return click.WithShadowPoint(v.(option.Point)) }},
+		{Name: "WithUseShapeOriginalColor", Type: typeBool, value: o.GetUseShapeOriginalColor(), option: func(v interface{}) interface{} { return click.WithUseShapeOriginalColor(v.(bool)) }},
+		{Name: "WithFontHinting", Type: typeSelect, value: hintingName(o.GetFontHinting()), Choices: []string{"none", "vertical", "full"},
+			option:   func(v interface{}) interface{} { return click.WithFontHinting(hintingChoices[v.(string)]) },
+			goNames:  map[string]string{"none": "font.HintingNone", "vertical": "font.HintingVertical", "full": "font.HintingFull"},
+			goImport: "golang.org/x/image/font"},
+		{Name: "WithTextDirection", Type: typeSelect, value: textDirectionName(o.GetTextDirection()), Choices: []string{"auto", "ltr", "rtl"},
+			option:  func(v interface{}) interface{} { return click.WithTextDirection(textDirectionChoices[v.(string)]) },
+			goNames: map[string]string{"auto": "click.TextDirectionAuto", "ltr": "click.TextDirectionLTR", "rtl": "click.TextDirectionRTL"}},
+		{Name: "WithPromptAttributes", Type: typeSelect, value: promptAttributeName(o.GetPromptAttributes()), Choices: []string{"none", "color", "size", "color+size"},
+			option:  func(v interface{}) interface{} { return click.WithPromptAttributes(promptAttributeChoices[v.(string)]) },
+			goNames: map[string]string{"none": "0", "color": "click.AttributeColor", "size": "click.AttributeSize", "color+size": "click.AttributeColor | click.AttributeSize"}},
+		{Name: "WithRangeThumbImageSize", Type: typeSize, value: *o.GetThumbImageSize(), option: func(v interface{}) interface{} { return click.WithRangeThumbImageSize(v.(option.Size)) }},
+		{Name: "WithRangeVerifyLen", Type: typeRange, value: *o.GetRangeVerifyLen(), option: func(v interface{}) interface{} { return click.WithRangeVerifyLen(v.(option.RangeVal)) }},
+		{Name: "WithDisabledRangeVerifyLen", Type: typeBool, value: o.GetDisabledRangeVerifyLen(), option: func(v interface{}) interface{} { return click.WithDisabledRangeVerifyLen(v.(bool)) }},
+		{Name: "WithRangeThumbSize", Type: typeRange, value: *o.GetRangeThumbSize(), option: func(v interface{}) interface{} { return click.WithRangeThumbSize(v.(option.RangeVal)) }},
+		{Name: "WithRangeThumbColors", Type: typeColors, value: o.GetRangeThumbColors(), option: func(v interface{}) interface{} { return click.WithRangeThumbColors(v.([]string)) }},
+		{Name: "WithRangeThumbBgColors", Type: typeColors, value: o.GetRangeThumbBgColors(), option: func(v interface{}) interface{} { return click.WithRangeThumbBgColors(v.([]string)) }},
+		{Name: "WithRangeThumbBgDistort", Type: typeInt, value: o.GetThumbBgDistort(), option: func(v interface{}) interface{} { return click.WithRangeThumbBgDistort(v.(int)) }},
+		{Name: "WithRangeThumbBgCirclesNum", Type: typeInt, value: o.GetThumbBgCirclesNum(), option: func(v interface{}) interface{} { return click.WithRangeThumbBgCirclesNum(v.(int)) }},
+		{Name: "WithRangeThumbBgSlimLineNum", Type: typeInt, value: o.GetThumbBgSlimLineNum(), option: func(v interface{}) interface{} { return click.WithRangeThumbBgSlimLineNum(v.(int)) }},
+		{Name: "WithIsThumbNonDeformAbility", Type: typeBool, value: o.GetIsThumbNonDeformAbility(), option: func(v interface{}) interface{} { return click.WithIsThumbNonDeformAbility(v.(bool)) }},
+		{Name: "WithThumbDisturbAlpha", Type: typeFloat, value: o.GetThumbDisturbAlpha(), option: func(v interface{}) interface{} { return click.WithThumbDisturbAlpha(v.(float32)) }},
+		{Name: "WithPerturbStrength", Type: typeFloat, value: o.GetPerturbStrength(), option: func(v interface{}) interface{} { return click.WithPerturbStrength(v.(float32)) }},
+	}
+}
+
+func slideFields(o *slide.Options) []*optionField {
+	directions := make([]string, 0, len(o.GetRangeDeadZoneDirections()))
+	for _, d := range o.GetRangeDeadZoneDirections() {
+		directions = append(directions, deadZoneDirectionName(d))
+	}
+
+	return []*optionField{
+		{Name: "WithImageSize", Type: typeSize, value: *o.GetImageSize(), option: func(v interface{}) interface{} { return slide.WithImageSize(v.(option.Size)) }},
+		{Name: "WithImageAlpha", Type: typeFloat, value: o.GetImageAlpha(), option: func(v interface{}) interface{} { return slide.WithImageAlpha(v.(float32)) }},
+		{Name: "WithRangeGraphSize", Type: typeRange, value: *o.GetRangeGraphSize(), option: func(v interface{}) interface{} { return slide.WithRangeGraphSize(v.(option.RangeVal)) }},
+		{Name: "WithRangeGraphAnglePos", Type: typeRanges, value: derefRanges(o.GetRangeGraphAnglePos()), option: func(v interface{}) interface{} { return slide.WithRangeGraphAnglePos(v.([]option.RangeVal)) }},
+		{Name: "WithGenGraphNumber", Type: typeInt, value: o.GetGenGraphNumber(), option: func(v interface{}) interface{} { return slide.WithGenGraphNumber(v.(int)) }},
+		{Name: "WithEnableGraphVerticalRandom", Type: typeBool, value: o.GetEnableGraphVerticalRandom(), option: func(v interface{}) interface{} { return slide.WithEnableGraphVerticalRandom(v.(bool)) }},
+		{Name: "WithRangeDeadZoneDirections", Type: typeMulti, value: directions, Choices: []string{"left", "right", "top", "bottom"},
+			option: func(v interface{}) interface{} {
+				var dirs []slide.DeadZoneDirectionType
+				for _, c := range v.([]string) {
+					dirs = append(dirs, deadZoneChoices[c])
+				}
+				return slide.WithRangeDeadZoneDirections(dirs)
+			},
+			goNames: map[string]string{
+				"left":   "slide.DeadZoneDirectionTypeLeft",
+				"right":  "slide.DeadZoneDirectionTypeRight",
+				"top":    "slide.DeadZoneDirectionTypeTop",
+				"bottom": "slide.DeadZoneDirectionTypeBottom",
+			}},
+		{Name: "WithDecoyNumber", Type: typeInt, value: o.GetDecoyNumber(), option: func(v interface{}) interface{} { return slide.WithDecoyNumber(v.(int)) }},
+		{Name: "WithGapFeather", Type: typeInt, value: o.GetGapFeather(), option: func(v interface{}) interface{} { return slide.WithGapFeather(v.(int)) }},
+		{Name: "WithGapNoise", Type: typeFloat, value: o.GetGapNoise(), option: func(v interface{}) interface{} { return slide.WithGapNoise(v.(float32)) }},
+		{Name: "WithTileEdgeJitter", Type: typeFloat, value: o.GetTileEdgeJitter(), option: func(v interface{}) interface{} { return slide.WithTileEdgeJitter(v.(float32)) }},
+		{Name: "WithTileLighting", Type: typeBool, value: o.GetTileLighting(), option: func(v interface{}) interface{} { return slide.WithTileLighting(v.(bool)) }},
+		{Name: "WithIndistinguishableDecoys", Type: typeBool, value: o.GetIndistinguishableDecoys(), option: func(v interface{}) interface{} { return slide.WithIndistinguishableDecoys(v.(bool)) }},
+	}
+}
+
+func rotateFields(o *rotate.Options) []*optionField {
+	return []*optionField{
+		{Name: "WithImageSquareSize", Type: typeInt, value: o.GetImageSize(), option: func(v interface{}) interface{} { return rotate.WithImageSquareSize(v.(int)) }},
+		{Name: "WithRangeAnglePos", Type: typeRanges, value: derefRanges(o.GetRangeAngle()), option: func(v interface{}) interface{} { return rotate.WithRangeAnglePos(v.([]option.RangeVal)) }},
+		{Name: "WithRangeThumbImageSquareSize", Type: typeInts, value: o.GetRangeThumbImageSquareSize(), option: func(v interface{}) interface{} { return rotate.WithRangeThumbImageSquareSize(v.([]int)) }},
+		{Name: "WithThumbImageAlpha", Type: typeFloat, value: o.GetThumbImageAlpha(), option: func(v interface{}) interface{} { return rotate.WithThumbImageAlpha(v.(float32)) }},
+		{Name: "WithRingGap", Type: typeInt, value: o.GetRingGap(), option: func(v interface{}) interface{} { return rotate.WithRingGap(v.(int)) }},
+		{Name: "WithRangeRingBlur", Type: typeRange, value: *o.GetRangeRingBlur(), option: func(v interface{}) interface{} { return rotate.WithRangeRingBlur(v.(option.RangeVal)) }},
+		{Name: "WithThumbOcclusion", Type: typeFloat, value: o.GetThumbOcclusion(), option: func(v interface{}) interface{} { return rotate.WithThumbOcclusion(v.(float32)) }},
+		{Name: "WithThumbScaleJitter", Type: typeFloat, value: o.GetThumbScaleJitter(), option: func(v interface{}) interface{} { return rotate.WithThumbScaleJitter(v.(float32)) }},
+		{Name: "WithThumbHueJitter", Type: typeInt, value: o.GetThumbHueJitter(), option: func(v interface{}) interface{} { return rotate.WithThumbHueJitter(v.(int)) }},
+		{Name: "WithBackgroundNoise", Type: typeFloat, value: o.GetBackgroundNoise(), option: func(v interface{}) interface{} { return rotate.WithBackgroundNoise(v.(float32)) }},
+	}
+}
+
+// decode decodes the JSON value of the option
+func (f *optionField) decode(raw json.RawMessage) (interface{}, error) {
+	var pair [2]int
+	var err error
+	var v interface{}
+
+	switch f.Type {
+	case typeInt:
+		var i int
+		err = json.Unmarshal(raw, &i)
+		v = i
+	case typeFloat:
+		var fl float32
+		err = json.Unmarshal(raw, &fl)
+		v = fl
+	case typeBool:
+		var b bool
+		err = json.Unmarshal(raw, &b)
+		v = b
+	case typeColor, typeSelect:
+		var s string
+		err = json.Unmarshal(raw, &s)
+		v = s
+	case typeColors, typeMulti:
+		var s []string
+		err = json.Unmarshal(raw, &s)
+		v = s
+	case typeInts:
+		var ints []int
+		err = json.Unmarshal(raw, &ints)
+		v = ints
+	case typeSize:
+		err = json.Unmarshal(raw, &pair)
+		v = option.Size{Width: pair[0], Height: pair[1]}
+	case typePoint:
+		err = json.Unmarshal(raw, &pair)
+		v = option.Point{X: pair[0], Y: pair[1]}
+	case typeRange:
+		err = json.Unmarshal(raw, &pair)
+		v = option.RangeVal{Min: pair[0], Max: pair[1]}
+	case typeRanges:
+		var pairs [][2]int
+		err = json.Unmarshal(raw, &pairs)
+		ranges := make([]option.RangeVal, 0, len(pairs))
+		for _, p := range pairs {
+			ranges = append(ranges, option.RangeVal{Min: p[0], Max: p[1]})
+		}
+		v = ranges
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", f.Name, err)
+	}
+
+	if err = f.check(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// check validates a decoded value, numbers must be in the bounds, colors must parse,
+// choices must be known and lists must not be empty
+func (f *optionField) check(v interface{}) error {
+	switch val := v.(type) {
+	case int:
+		return f.checkBounds(float64(val))
+	case float32:
+		return f.checkBounds(float64(val))
+	case string:
+		if f.Type == typeSelect {
+			return f.checkChoice(val)
+		}
+		return f.checkColor(val)
+	case []string:
+		if len(val) == 0 {
+			return fmt.Errorf("%s: empty list", f.Name)
+		}
+		for _, s := range val {
+			var err error
+			if f.Type == typeMulti {
+				err = f.checkChoice(s)
+			} else {
+				err = f.checkColor(s)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case []int:
+		if len(val) == 0 {
+			return fmt.Errorf("%s: empty list", f.Name)
+		}
+		for _, i := range val {
+			if err := f.checkBounds(float64(i)); err != nil {
+				return err
+			}
+		}
+	case option.Size:
+		return f.checkBounds(float64(val.Width), float64(val.Height))
+	case option.Point:
+		return f.checkBounds(float64(val.X), float64(val.Y))
+	case option.RangeVal:
+		return f.checkRange(val)
+	case []option.RangeVal:
+		if len(val) == 0 {
+			return fmt.Errorf("%s: empty list", f.Name)
+		}
+		for _, r := range val {
+			if err := f.checkRange(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *optionField) checkBounds(values ...float64) error {
+	if f.Bounds == nil {
+		return nil
+	}
+	for _, v := range values {
+		if v < f.Bounds[0] || v > f.Bounds[1] {
+			return fmt.Errorf("%s: %v is out of [%v, %v]", f.Name, v, f.Bounds[0], f.Bounds[1])
+		}
+	}
+	return nil
+}
+
+func (f *optionField) checkRange(r option.RangeVal) error {
+	if r.Min > r.Max {
+		return fmt.Errorf("%s: min %d is above max %d", f.Name, r.Min, r.Max)
+	}
+	return f.checkBounds(float64(r.Min), float64(r.Max))
+}
+
+func (f *optionField) checkColor(s string) error {
+	if s == "" {
+		return fmt.Errorf("%s: empty color", f.Name)
+	}
+	if _, err := helper.ParseHexColor(s); err != nil {
+		return fmt.Errorf("%s: color %q: %v", f.Name, s, err)
+	}
+	return nil
+}
+
+func (f *optionField) checkChoice(c string) error {
+	for _, choice := range f.Choices {
+		if c == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: unknown choice %q, use one of %s", f.Name, c, strings.Join(f.Choices, ", "))
+}
+
+// jsonValue converts a decoded value to its JSON value
+func (f *optionField) jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case option.Size:
+		return [2]int{val.Width, val.Height}
+	case option.Point:
+		return [2]int{val.X, val.Y}
+	case option.RangeVal:
+		return [2]int{val.Min, val.Max}
+	case []option.RangeVal:
+		pairs := make([][2]int, 0, len(val))
+		for _, r := range val {
+			pairs = append(pairs, [2]int{r.Min, r.Max})
+		}
+		return pairs
+	case []string:
+		if f.Type != typeMulti {
+			return val
+		}
+		// a multi choice is a set, duplicates only weight the random pick
+		set := make([]string, 0, len(f.Choices))
+		for _, choice := range f.Choices {
+			for _, c := range val {
+				if c == choice {
+					set = append(set, c)
+					break
+				}
+			}
+		}
+		return set
+	}
+	return v
+}
+
+// goLiteral returns the Go expression of a decoded value
+func (f *optionField) goLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case int:
+		return strconv.Itoa(val)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'g', -1, 32)
+	case bool:
+		return strconv.FormatBool(val)
+	case string:
+		if f.goNames != nil {
+			return f.goNames[val]
+		}
+		return strconv.Quote(val)
+	case option.Size:
+		return fmt.Sprintf("option.Size{Width: %d, Height: %d}", val.Width, val.Height)
+	case option.Point:
+		return fmt.Sprintf("option.Point{X: %d, Y: %d}", val.X, val.Y)
+	case option.RangeVal:
+		return fmt.Sprintf("option.RangeVal{Min: %d, Max: %d}", val.Min, val.Max)
+	case []option.RangeVal:
+		items := make([]string, 0, len(val))
+		for _, r := range val {
+			items = append(items, fmt.Sprintf("{Min: %d, Max: %d}", r.Min, r.Max))
+		}
+		return "[]option.RangeVal{" + strings.Join(items, ", ") + "}"
+	case []int:
+		items := make([]string, 0, len(val))
+		for _, i := range val {
+			items = append(items, strconv.Itoa(i))
+		}
+		return "[]int{" + strings.Join(items, ", ") + "}"
+	case []string:
+		items := make([]string, 0, len(val))
+		for _, s := range val {
+			if f.goNames != nil {
+				items = append(items, f.goNames[s])
+			} else {
+				items = append(items, strconv.Quote(s))
+			}
+		}
+		if f.Type == typeMulti {
+			return "[]slide.DeadZoneDirectionType{" + strings.Join(items, ", ") + "}"
+		}
+		return "[]string{" + strings.Join(items, ", ") + "}"
+	}
+	return fmt.Sprint(v)
+}
+
+// goImports returns the packages the Go literal of the option needs besides the captcha kind
+func (f *optionField) goImports() []string {
+	var paths []string
+	switch f.Type {
+	case typeSize, typePoint, typeRange, typeRanges:
+		paths = append(paths, "github.com/wenlng/go-captcha/v2/base/option")
+	}
+	if f.goImport != "" {
+		paths = append(paths, f.goImport)
+	}
+	return paths
+}
+
+// setOption is an option of a config with its decoded value
+type setOption struct {
+	field *optionField
+	value interface{}
+}
+
+// changed reports whether the value differs from the default
+func (o setOption) changed() bool {
+	a, _ := json.Marshal(o.field.jsonValue(o.value))
+	b, _ := json.Marshal(o.field.Default)
+	return !bytes.Equal(a, b)
+}
+
+// decodeOptions decodes the options of a config in field order
+// params:
+//   - kind, mode: Captcha kind and mode
+//   - values: JSON values by option name
+//
+// return: Decoded options, error on unknown options or invalid values
+func decodeOptions(kind, mode string, values map[string]json.RawMessage) ([]setOption, error) {
+	fields := optionFields(kind, mode)
+	byName := make(map[string]*optionField, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	for name := range values {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("unknown %s option %q", kind, name)
+		}
+	}
+
+	opts := make([]setOption, 0, len(values))
+	for _, f := range fields {
+		raw, ok := values[f.Name]
+		if !ok {
+			continue
+		}
+		v, err := f.decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, setOption{field: f, value: v})
+	}
+	return opts, nil
+}
+
+// exportJSON returns the config JSON with the options that differ from the defaults
+func exportJSON(kind, mode string, opts []setOption) ([]byte, error) {
+	cfg := captchaConfig{Kind: kind, Mode: mode, Options: make(map[string]json.RawMessage)}
+	for _, o := range opts {
+		if !o.changed() {
+			continue
+		}
+		raw, err := json.Marshal(o.field.jsonValue(o.value))
+		if err != nil {
+			return nil, err
+		}
+		cfg.Options[o.field.Name] = raw
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// exportGo returns Go code making the captcha with the options that differ from the defaults
+func exportGo(kind, mode string, opts []setOption) ([]byte, error) {
+	imports := map[string]bool{"github.com/wenlng/go-captcha/v2/" + kind: true}
+	var args []string
+	for _, o := range opts {
+		if !o.changed() {
+			continue
+		}
+		for _, p := range o.field.goImports() {
+			imports[p] = true
+		}
+		args = append(args, fmt.Sprintf("%s.%s(%s),", o.field.pkg, o.field.Name, o.field.goLiteral(o.value)))
+	}
+
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	makeFunc, resources := "Make", ""
+	switch kind {
+	case kindClick:
+		resources = "click.WithBackgrounds(backgrounds), click.WithFonts(fonts), click.WithThumbBackgrounds(thumbBackgrounds)"
+		switch mode {
+		case modeShape:
+			makeFunc = "MakeShape"
+			resources = "click.WithBackgrounds(backgrounds), click.WithShapes(shapes), click.WithThumbBackgrounds(thumbBackgrounds)"
+		case modeOddOneOut:
+			makeFunc = "MakeOddOneOut"
+		case modeCount:
+			makeFunc = "MakeCount"
+		}
+	case kindSlide:
+		resources = "slide.WithBackgrounds(backgrounds), slide.WithGraphImages(graphImages)"
+		if mode == modeDrag {
+			makeFunc = "MakeDragDrop"
+		}
+	case kindRotate:
+		resources = "rotate.WithImages(images)"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package main\n\nimport (\n")
+	for _, p := range paths {
+		fmt.Fprintf(&buf, "%q\n", p)
+	}
+	fmt.Fprintf(&buf, ")\n\nfunc newCaptcha() %s.Captcha {\n", kind)
+	fmt.Fprintf(&buf, "builder := %s.NewBuilder(\n%s\n)\n\n", kind, strings.Join(args, "\n"))
+	fmt.Fprintf(&buf, "// load your resources\n// builder.SetResources(%s)\n\n", resources)
+	fmt.Fprintf(&buf, "return builder.%s()\n}\n", makeFunc)
+
+	return format.Source(buf.Bytes())
+}
+
+// captchaOptions converts the options of a config to the option functions of its kind
+func captchaOptions(opts []setOption) []interface{} {
+	out := make([]interface{}, 0, len(opts))
+	for _, o := range opts {
+		out = append(out, o.field.option(o.value))
+	}
+	return out
+}
+
+func derefRanges(ranges []*option.RangeVal) []option.RangeVal {
+	out := make([]option.RangeVal, 0, len(ranges))
+	for _, r := range ranges {
+		out = append(out, *r)
+	}
+	return out
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+// changedValues returns a value differing from the default for every option of a kind and mode
+func changedValues(t *testing.T, kind, mode string) map[string]json.RawMessage {
+	t.Helper()
+	values := make(map[string]json.RawMessage)
+	for _, f := range optionFields(kind, mode) {
+		var v interface{}
+		switch f.Type {
+		case typeInt:
+			v = int(nudge(f, float64(f.value.(int)), 1))
+		case typeFloat:
+			v = float32(nudge(f, float64(f.value.(float32)), 0.25))
+		case typeBool:
+			v = !f.value.(bool)
+		case typeColor:
+			v = "#123456"
+		case typeColors:
+			v = []string{"#123456", "#abcdef"}
+		case typeSize, typePoint, typeRange:
+			pair := f.Default.([2]int)
+			v = [2]int{int(nudge(f, float64(pair[0]), 1)), int(nudge(f, float64(pair[1]), 1))}
+		case typeRanges:
+			v = [][2]int{{1, 2}, {3, 4}}
+		case typeInts:
+			v = []int{20, 30}
+		case typeSelect:
+			for _, c := range f.Choices {
+				if c != f.Default {
+					v = c
+					break
+				}
+			}
+		case typeMulti:
+			v = f.Choices[len(f.Choices)-1:]
+			if reflect.DeepEqual(f.Default, v) {
+				v = f.Choices[:1]
+			}
+		}
+
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values[f.Name] = raw
+	}
+	return values
+}
+
+// nudge moves a number by step, backwards when it would leave the bounds of the option
+func nudge(f *optionField, v, step float64) float64 {
+	if f.Bounds != nil && v+step > f.Bounds[1] {
+		return v - step
+	}
+	return v + step
+}
+
+func TestOptionsRoundTrip(t *testing.T) {
+	for kind, modes := range kindModes {
+		for _, mode := range modes {
+			values := changedValues(t, kind, mode)
+			opts, err := decodeOptions(kind, mode, values)
+			if err != nil {
+				t.Fatalf("%s %s: %v", kind, mode, err)
+			}
+			for _, o := range opts {
+				if !o.changed() {
+					t.Fatalf("%s %s: %s is the default", kind, mode, o.field.Name)
+				}
+			}
+
+			data, err := exportJSON(kind, mode, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var cfg captchaConfig
+			if err = json.Unmarshal(data, &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Kind != kind || cfg.Mode != mode || len(cfg.Options) != len(values) {
+				t.Fatalf("%s %s: exported %s", kind, mode, data)
+			}
+
+			again, err := decodeOptions(cfg.Kind, cfg.Mode, cfg.Options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, o := range again {
+				if !reflect.DeepEqual(o.value, opts[i].value) {
+					t.Fatalf("%s %s: %s decoded %v, exported %v", kind, mode, o.field.Name, opts[i].value, o.value)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeOptionsErrors(t *testing.T) {
+	cases := map[string]json.RawMessage{
+		"WithUnknown":          json.RawMessage(`1`),
+		"WithDotSpacing":       json.RawMessage(`"wide"`),
+		"WithTextDirection":    json.RawMessage(`"up"`),
+		"WithPromptAttributes": json.RawMessage(`["color"]`),
+	}
+	for name, raw := range cases {
+		if _, err := decodeOptions(kindClick, modeText, map[string]json.RawMessage{name: raw}); err == nil {
+			t.Fatalf("%s: %s decoded", name, raw)
+		}
+	}
+}
+
+func TestNumericOptionsBounded(t *testing.T) {
+	for kind, modes := range kindModes {
+		for _, mode := range modes {
+			for _, f := range optionFields(kind, mode) {
+				switch f.Type {
+				case typeInt, typeFloat, typeSize, typePoint, typeRange, typeRanges, typeInts:
+					if f.Bounds == nil {
+						t.Fatalf("%s %s: %s has no bounds", kind, mode, f.Name)
+					}
+				}
+
+				// the defaults are in the bounds
+				raw, _ := json.Marshal(f.Default)
+				if _, err := f.decode(raw); err != nil {
+					t.Fatalf("%s %s: default %v", kind, mode, err)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeOptionsChecks(t *testing.T) {
+	cases := []struct {
+		kind, name, value string
+	}{
+		{kindClick, "WithImageAlpha", `1.5`},
+		{kindClick, "WithRangeLen", `[5, 2]`},
+		{kindClick, "WithRangeLen", `[0, 2]`},
+		{kindClick, "WithImageSize", `[0, 0]`},
+		{kindClick, "WithRangeColors", `[]`},
+		{kindClick, "WithRangeColors", `[""]`},
+		{kindClick, "WithShadowColor", `"red"`},
+		{kindClick, "WithRangeAnglePos", `[]`},
+		{kindSlide, "WithRangeDeadZoneDirections", `[]`},
+		{kindRotate, "WithRangeThumbImageSquareSize", `[]`},
+		{kindRotate, "WithImageSquareSize", `-1`},
+	}
+	for _, c := range cases {
+		values := map[string]json.RawMessage{c.name: json.RawMessage(c.value)}
+		if _, err := decodeOptions(c.kind, kindModes[c.kind][0], values); err == nil {
+			t.Fatalf("%s %s: %s decoded", c.kind, c.name, c.value)
+		}
+	}
+}
+
+// sourceFset and sourceImporter type check the exported code, the importer caches the
+// packages it loaded from source
+var (
+	sourceFset     = token.NewFileSet()
+	sourceImporter = importer.ForCompiler(sourceFset, "source", nil)
+)
+
+// typeCheck parses and type checks Go code against the packages of the module
+func typeCheck(t *testing.T, code []byte) {
+	t.Helper()
+	file, err := parser.ParseFile(sourceFset, "captcha.go", code, 0)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, code)
+	}
+	conf := types.Config{Importer: sourceImporter}
+	if _, err = conf.Check("main", sourceFset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("%v\n%s", err, code)
+	}
+}
+
+func TestExportGoCompiles(t *testing.T) {
+	for kind, modes := range kindModes {
+		for _, mode := range modes {
+			opts, err := decodeOptions(kind, mode, changedValues(t, kind, mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, err := exportGo(kind, mode, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			typeCheck(t, code)
+
+			// no option changed leaves the option packages out
+			code, err = exportGo(kind, mode, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			typeCheck(t, code)
+		}
+	}
+}
+
+func TestExportGoStringImports(t *testing.T) {
+	var fields []*optionField
+	for _, f := range optionFields(kindClick, modeText) {
+		if f.Name == "WithShadowColor" {
+			fields = append(fields, f)
+		}
+	}
+	opts := []setOption{{field: fields[0], value: "option.font."}}
+	code, err := exportGo(kindClick, modeText, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeCheck(t, code)
+}
@@ -0,0 +1,284 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wenlng/go-captcha/v2/slide"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+// Resource directory layout, every sub directory is optional:
+//
+//	backgrounds/   master background images of all kinds, click draws procedural ones when missing
+//	thumbs/        thumb background images of click captchas
+//	images/        rotate images, backgrounds/ is used when missing
+//	shapes/        shape images of click shape mode, named after the file
+//	fonts/         .ttf, .otf, .ttc and .otc fonts of click text mode
+//	tiles/         slide tiles as tile-N.png, tile-shadow-N.png and tile-mask-N.png
+const (
+	backgroundsDir = "backgrounds"
+	thumbsDir      = "thumbs"
+	imagesDir      = "images"
+	shapesDir      = "shapes"
+	fontsDir       = "fonts"
+	tilesDir       = "tiles"
+)
+
+var (
+	imageExts = []string{".png", ".jpg", ".jpeg"}
+	fontExts  = []string{".ttf", ".otf", ".ttc", ".otc"}
+
+	tileNameRe = regexp.MustCompile(`^tile-(shadow-|mask-)?(\w+)\.png$`)
+)
+
+// resources holds everything loaded from a resource directory
+type resources struct {
+	backgrounds      []image.Image
+	thumbBackgrounds []image.Image
+	images           []image.Image
+	shapes           map[string]image.Image
+	fonts            []*sfnt.Font
+	tiles            []*slide.GraphImage
+
+	// files the backgrounds, rotate images and fonts are loaded from
+	backgroundFiles []string
+	imageFiles      []string
+	fontFiles       []string
+}
+
+// problem is a finding about a resource file
+type problem struct {
+	path    string
+	err     error
+	warning bool
+}
+
+func (p problem) String() string {
+	level := "error"
+	if p.warning {
+		level = "warn"
+	}
+	return fmt.Sprintf("%-5s %s: %v", level, p.path, p.err)
+}
+
+// loadResources loads the resource directory, files that fail to load are reported as problems
+// params:
+//   - dir: Resource directory, empty for none
+//
+// return: Loaded resources, problems found and an error when the directory can't be read
+func loadResources(dir string) (*resources, []problem, error) {
+	res := &resources{shapes: make(map[string]image.Image)}
+	if dir == "" {
+		return res, nil, nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var problems []problem
+	report := func(path string, err error) {
+		problems = append(problems, problem{path: path, err: err})
+	}
+
+	res.backgrounds, res.backgroundFiles = loadImages(filepath.Join(dir, backgroundsDir), report)
+	res.thumbBackgrounds, _ = loadImages(filepath.Join(dir, thumbsDir), report)
+	res.images, res.imageFiles = loadImages(filepath.Join(dir, imagesDir), report)
+
+	forEachFile(filepath.Join(dir, shapesDir), imageExts, report, func(path string) {
+		img, err := loadImage(path)
+		if err != nil {
+			report(path, err)
+			return
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		res.shapes[name] = img
+	})
+
+	forEachFile(filepath.Join(dir, fontsDir), fontExts, report, func(path string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			report(path, err)
+			return
+		}
+		fonts, err := parseFonts(data)
+		if err != nil {
+			report(path, err)
+			return
+		}
+		for _, f := range fonts {
+			res.fonts = append(res.fonts, f)
+			res.fontFiles = append(res.fontFiles, path)
+		}
+	})
+
+	tiles, tileProblems := loadTiles(filepath.Join(dir, tilesDir))
+	res.tiles = tiles
+	problems = append(problems, tileProblems...)
+
+	return res, problems, nil
+}
+
+// loadTiles loads the slide tiles, each tile needs its overlay, shadow and mask image
+func loadTiles(dir string) ([]*slide.GraphImage, []problem) {
+	var problems []problem
+	report := func(path string, err error) {
+		problems = append(problems, problem{path: path, err: err})
+	}
+
+	groups := make(map[string]*slide.GraphImage)
+	forEachFile(dir, []string{".png"}, report, func(path string) {
+		m := tileNameRe.FindStringSubmatch(filepath.Base(path))
+		if m == nil {
+			problems = append(problems, problem{path: path, err: errors.New("not named tile-N.png, tile-shadow-N.png or tile-mask-N.png"), warning: true})
+			return
+		}
+
+		img, err := loadImage(path)
+		if err != nil {
+			report(path, err)
+			return
+		}
+
+		g, ok := groups[m[2]]
+		if !ok {
+			g = &slide.GraphImage{}
+			groups[m[2]] = g
+		}
+		switch m[1] {
+		case "shadow-":
+			g.ShadowImage = img
+		case "mask-":
+			g.MaskImage = img
+		default:
+			g.OverlayImage = img
+		}
+	})
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tiles := make([]*slide.GraphImage, 0, len(groups))
+	for _, name := range names {
+		g := groups[name]
+		path := filepath.Join(dir, "tile-"+name+".png")
+		switch {
+		case g.OverlayImage == nil:
+			report(path, errors.New("missing tile image"))
+		case g.ShadowImage == nil:
+			report(path, errors.New("missing tile-shadow image"))
+		case g.MaskImage == nil:
+			report(path, errors.New("missing tile-mask image"))
+		case g.OverlayImage.Bounds().Size() != g.ShadowImage.Bounds().Size() ||
+			g.OverlayImage.Bounds().Size() != g.MaskImage.Bounds().Size():
+			report(path, errors.New("tile, shadow and mask images differ in size"))
+		default:
+			tiles = append(tiles, g)
+		}
+	}
+
+	return tiles, problems
+}
+
+// loadImages loads the images of a directory
+// return: Images and the files they are loaded from
+func loadImages(dir string, report func(path string, err error)) ([]image.Image, []string) {
+	var images []image.Image
+	var files []string
+	forEachFile(dir, imageExts, report, func(path string) {
+		img, err := loadImage(path)
+		if err != nil {
+			report(path, err)
+			return
+		}
+		images = append(images, img)
+		files = append(files, path)
+	})
+	return images, files
+}
+
+// loadImage decodes an image file
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// forEachFile calls fn for the files of dir with one of exts in name order, a missing dir is skipped
+func forEachFile(dir string, exts []string, report func(path string, err error), fn func(path string)) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			report(dir, err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		for _, e := range exts {
+			if ext == e {
+				fn(filepath.Join(dir, entry.Name()))
+				break
+			}
+		}
+	}
+}
+
+// parseFonts parses TTF, OTF, TTC or OTC data, every font of a collection is returned
+func parseFonts(data []byte) ([]*sfnt.Font, error) {
+	c, err := sfnt.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fonts := make([]*sfnt.Font, 0, c.NumFonts())
+	for i := 0; i < c.NumFonts(); i++ {
+		f, err := c.Font(i)
+		if err != nil {
+			return nil, err
+		}
+		fonts = append(fonts, f)
+	}
+	return fonts, nil
+}
+
+// defaultFonts returns the built in Go font used when no fonts are provided
+func defaultFonts() []*sfnt.Font {
+	fonts, err := parseFonts(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	return fonts
+}
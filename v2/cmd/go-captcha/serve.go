@@ -0,0 +1,224 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"time"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// maxRequestBytes limits the size of an API request body
+const maxRequestBytes = 1 << 20
+
+// previewRequest is the body of the generate and export requests
+type previewRequest struct {
+	Kind    string                     `json:"kind"`
+	Mode    string                     `json:"mode"`
+	Options map[string]json.RawMessage `json:"options"`
+}
+
+// previewResponse is the response of the generate request
+type previewResponse struct {
+	Master    string  `json:"master"`
+	Thumb     string  `json:"thumb"`
+	ThumbName string  `json:"thumb_name"`
+	Answer    *answer `json:"answer"`
+	Elapsed   string  `json:"elapsed"`
+}
+
+// exportResponse is the response of the export request
+type exportResponse struct {
+	Go   string `json:"go"`
+	JSON string `json:"json"`
+}
+
+// previewServer serves the preview page and its API
+type previewServer struct {
+	cfg *config
+	res *resources
+}
+
+// runServe serves a local preview page where every option is a form control, the preview
+// regenerates on each change and the config can be exported as Go code or JSON
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	cfg := &config{}
+	cfg.register(flags)
+	addr := flags.String("addr", "127.0.0.1:8080", "address to listen on")
+	if err := cfg.parse(flags, args); err != nil {
+		return err
+	}
+
+	res, problems, err := loadResources(cfg.resDir)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		log.Println(p)
+	}
+
+	static, err := fs.Sub(assets, "assets")
+	if err != nil {
+		return err
+	}
+
+	s := &previewServer{cfg: cfg, res: res}
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/api/config", s.handleConfig)
+	mux.HandleFunc("/api/schema", s.handleSchema)
+	mux.HandleFunc("/api/generate", s.handleGenerate)
+	mux.HandleFunc("/api/export", s.handleExport)
+
+	log.Printf("go-captcha preview on http://%s", *addr)
+	server := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return server.ListenAndServe()
+}
+
+// handleConfig responds with the config the server was started with
+func (s *previewServer) handleConfig(w http.ResponseWriter, r *http.Request) {
+	values := make(map[string]interface{}, len(s.cfg.options))
+	for _, o := range s.cfg.options {
+		values[o.field.Name] = o.field.jsonValue(o.value)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"kind":    s.cfg.kind,
+		"mode":    s.cfg.mode,
+		"kinds":   kindModes,
+		"options": values,
+	})
+}
+
+// handleSchema responds with the option fields of a kind and mode
+func (s *previewServer) handleSchema(w http.ResponseWriter, r *http.Request) {
+	cfg := &config{kind: r.URL.Query().Get("kind"), mode: r.URL.Query().Get("mode")}
+	if err := cfg.check(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, optionFields(cfg.kind, cfg.mode))
+}
+
+// handleGenerate generates a captcha with the options of the request
+func (s *previewServer) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	start := time.Now()
+	resp, err := s.generate(cfg)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp.Elapsed = time.Since(start).Round(time.Microsecond * 100).String()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleExport exports the options of the request as Go code and JSON
+func (s *previewServer) handleExport(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.requestConfig(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	code, err := exportGo(cfg.kind, cfg.mode, cfg.options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	data, err := exportJSON(cfg.kind, cfg.mode, cfg.options)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &exportResponse{Go: string(code), JSON: string(data)})
+}
+
+// requestConfig decodes the config of a generate or export request
+func (s *previewServer) requestConfig(r *http.Request) (*config, error) {
+	if r.Method != http.MethodPost {
+		return nil, fmt.Errorf("method %s not allowed", r.Method)
+	}
+
+	var req previewRequest
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		return nil, err
+	}
+
+	cfg := *s.cfg
+	cfg.kind, cfg.mode = req.Kind, req.Mode
+	cfg.width, cfg.height = 0, 0
+	if err := cfg.check(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	cfg.options, err = decodeOptions(cfg.kind, cfg.mode, req.Options)
+	return &cfg, err
+}
+
+// generate makes and generates the captcha, the options are checked by decodeOptions
+func (s *previewServer) generate(cfg *config) (*previewResponse, error) {
+	gen, err := makeGenerator(cfg, s.res)
+	if err != nil {
+		return nil, err
+	}
+	sample, err := gen()
+	if err != nil {
+		return nil, err
+	}
+
+	master, err := sample.masterBytes()
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := sample.thumbBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	masterType := "image/png"
+	if sample.masterExt == ".jpg" {
+		masterType = "image/jpeg"
+	}
+
+	return &previewResponse{
+		Master:    "data:" + masterType + ";base64," + base64.StdEncoding.EncodeToString(master),
+		Thumb:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(thumb),
+		ThumbName: sample.thumbName,
+		Answer:    sample.answer,
+	}, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+}
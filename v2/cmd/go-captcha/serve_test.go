@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+// fillImage returns an image of a size filled with a color
+func fillImage(w, h int, c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	return img
+}
+
+// testResources returns synthetic resources of every kind
+func testResources() *resources {
+	bg := image.NewNRGBA(image.Rect(0, 0, 300, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 300; x++ {
+			bg.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: 0xff})
+		}
+	}
+	// L shapes of different widths, they look different mirrored or turned
+	shapes := make(map[string]image.Image)
+	for i := 0; i < 10; i++ {
+		img := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+		draw.Draw(img, image.Rect(0, 0, 12, 40), image.Black, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(0, 28, 14+i*2, 40), image.Black, image.Point{}, draw.Src)
+		shapes[fmt.Sprintf("l%d", i)] = img
+	}
+	tile := fillImage(60, 60, color.NRGBA{R: 0xff, A: 0xff})
+	return &resources{
+		backgrounds: []image.Image{bg},
+		shapes:      shapes,
+		tiles:       []*slide.GraphImage{{OverlayImage: tile, ShadowImage: tile, MaskImage: tile}},
+	}
+}
+
+// boundValues returns the values of a numeric option at its lower and upper bounds
+func boundValues(f *optionField) []interface{} {
+	if f.Bounds == nil {
+		return nil
+	}
+	var values []interface{}
+	for _, b := range f.Bounds {
+		n := int(b)
+		switch f.Type {
+		case typeInt:
+			values = append(values, n)
+		case typeFloat:
+			values = append(values, b)
+		case typeSize, typePoint, typeRange:
+			values = append(values, [2]int{n, n})
+		case typeRanges:
+			values = append(values, [][2]int{{n, n}})
+		case typeInts:
+			values = append(values, []int{n})
+		}
+	}
+	return values
+}
+
+func TestGenerateOptionBounds(t *testing.T) {
+	s := &previewServer{cfg: &config{locale: "en"}, res: testResources()}
+	for kind, modes := range kindModes {
+		for _, mode := range modes {
+			for _, f := range optionFields(kind, mode) {
+				for _, v := range boundValues(f) {
+					raw, _ := json.Marshal(v)
+					cfg := *s.cfg
+					cfg.kind, cfg.mode = kind, mode
+					opts, err := decodeOptions(kind, mode, map[string]json.RawMessage{f.Name: raw})
+					if err != nil {
+						t.Fatalf("%s %s: %v", kind, mode, err)
+					}
+					cfg.options = opts
+
+					// errors are fine, the options must not panic
+					_, _ = s.generate(&cfg)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+const (
+	sheetGap      = 12
+	sheetLabelGap = 16
+)
+
+// runSheet renders a contact sheet of samples, each cell shows the master image,
+// the thumb or tile image below it and the sample number
+func runSheet(args []string) error {
+	fs := flag.NewFlagSet("sheet", flag.ExitOnError)
+	cfg := &config{}
+	cfg.register(fs)
+	n := fs.Int("n", 12, "number of samples")
+	cols := fs.Int("cols", 4, "number of columns")
+	out := fs.String("out", "sheet.png", "output PNG file")
+	answers := fs.Bool("answers", false, "draw the answers on top of the master images")
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *n <= 0 || *cols <= 0 {
+		return fmt.Errorf("-n and -cols must be greater than 0")
+	}
+
+	gen, err := newGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	samples := make([]*sample, 0, *n)
+	cellW, cellH := 0, 0
+	for i := 0; i < *n; i++ {
+		s, err := gen()
+		if err != nil {
+			return err
+		}
+		if *answers {
+			s.master = s.debugImage()
+		}
+		samples = append(samples, s)
+
+		mb, tb := s.master.Bounds(), s.thumb.Bounds()
+		cellW = helper.MaxInt(cellW, helper.MaxInt(mb.Dx(), tb.Dx()))
+		cellH = helper.MaxInt(cellH, mb.Dy()+sheetGap/2+tb.Dy())
+	}
+
+	if err = writePNG(*out, drawSheet(samples, *cols, cellW, cellH)); err != nil {
+		return err
+	}
+
+	fmt.Println(*out)
+	return nil
+}
+
+// drawSheet lays the samples out in a grid of cols columns
+func drawSheet(samples []*sample, cols, cellW, cellH int) *image.NRGBA {
+	if cols > len(samples) {
+		cols = len(samples)
+	}
+	rows := (len(samples) + cols - 1) / cols
+	stepW := cellW + sheetGap
+	stepH := cellH + sheetLabelGap + sheetGap
+
+	sheet := image.NewNRGBA(image.Rect(0, 0, cols*stepW+sheetGap, rows*stepH+sheetGap))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(color.RGBA{R: 0xf2, G: 0xf2, B: 0xf2, A: 0xff}), image.Point{}, draw.Src)
+
+	drawer := &font.Drawer{
+		Dst:  sheet,
+		Src:  image.NewUniform(color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}),
+		Face: basicfont.Face7x13,
+	}
+
+	for i, s := range samples {
+		x := sheetGap + (i%cols)*stepW
+		y := sheetGap + (i/cols)*stepH
+
+		drawer.Dot = fixed.P(x, y+basicfont.Face7x13.Ascent)
+		drawer.DrawString(fmt.Sprintf("#%d", i+1))
+		y += sheetLabelGap
+
+		mb := s.master.Bounds()
+		draw.Draw(sheet, image.Rect(x, y, x+mb.Dx(), y+mb.Dy()), s.master, mb.Min, draw.Over)
+		y += mb.Dy() + sheetGap/2
+
+		tb := s.thumb.Bounds()
+		draw.Draw(sheet, image.Rect(x, y, x+tb.Dx(), y+tb.Dy()), s.thumb, tb.Min, draw.Over)
+	}
+
+	return sheet
+}
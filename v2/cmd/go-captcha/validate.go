@@ -0,0 +1,187 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	v2 "github.com/wenlng/go-captcha/v2"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"golang.org/x/image/font/sfnt"
+)
+
+// runValidate checks that every file of a resource directory loads, that the images fit the
+// captcha sizes and the fonts cover the chars, then generates one captcha of every kind it can
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-captcha validate [flags] <dir>\n\n"+
+			"A resource directory may contain the sub directories:\n"+
+			"  %-12s master background images of all kinds\n"+
+			"  %-12s thumb background images of click captchas\n"+
+			"  %-12s rotate images, %s is used when missing\n"+
+			"  %-12s shape images of click shape mode, named after the file\n"+
+			"  %-12s .ttf, .otf, .ttc and .otc fonts of click text mode\n"+
+			"  %-12s slide tiles as tile-N.png, tile-shadow-N.png and tile-mask-N.png\n\nFlags:\n",
+			backgroundsDir+"/", thumbsDir+"/", imagesDir+"/", backgroundsDir+"/", shapesDir+"/", fontsDir+"/", tilesDir+"/")
+		fs.PrintDefaults()
+	}
+	cfg := &config{}
+	cfg.register(fs)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if cfg.resDir == "" && fs.NArg() > 0 {
+		cfg.resDir = fs.Arg(0)
+	}
+	if cfg.resDir == "" {
+		fs.Usage()
+		return errors.New("no resource directory")
+	}
+
+	res, problems, err := loadResources(cfg.resDir)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, checkResources(cfg, res)...)
+
+	fmt.Printf("%d backgrounds, %d thumb backgrounds, %d rotate images, %d shapes, %d fonts, %d tiles\n",
+		len(res.backgrounds), len(res.thumbBackgrounds), len(res.images), len(res.shapes), len(res.fonts), len(res.tiles))
+
+	errCount := 0
+	for _, p := range problems {
+		fmt.Println(p)
+		if !p.warning {
+			errCount++
+		}
+	}
+	if errCount > 0 {
+		return fmt.Errorf("%d errors", errCount)
+	}
+
+	for _, c := range generatable(cfg, res) {
+		gen, err := newGenerator(c)
+		if err == nil {
+			_, err = gen()
+		}
+		if err != nil {
+			errCount++
+			fmt.Printf("error %s %s: %v\n", c.kind, c.mode, err)
+			continue
+		}
+		fmt.Printf("ok    %s %s\n", c.kind, c.mode)
+	}
+	if errCount > 0 {
+		return fmt.Errorf("%d errors", errCount)
+	}
+
+	return nil
+}
+
+// checkResources reports resources that load but don't suit the captchas
+func checkResources(cfg *config, res *resources) []problem {
+	var problems []problem
+	warn := func(path string, format string, a ...interface{}) {
+		problems = append(problems, problem{path: path, err: fmt.Errorf(format, a...), warning: true})
+	}
+
+	clickOpts := v2.NewClickBuilder().Make().GetOptions()
+	size := clickOpts.GetImageSize()
+	width, height := size.Width, size.Height
+	if cfg.width > 0 && cfg.height > 0 {
+		width, height = cfg.width, cfg.height
+	}
+	for i, img := range res.backgrounds {
+		if b := img.Bounds(); b.Dx() < width || b.Dy() < height {
+			warn(res.backgroundFiles[i], "%dx%d is smaller than the %dx%d image size", b.Dx(), b.Dy(), width, height)
+		}
+	}
+
+	square := v2.NewRotateBuilder().Make().GetOptions().GetImageSize()
+	if cfg.width > 0 {
+		square = cfg.width
+	}
+	for i, img := range res.images {
+		if b := img.Bounds(); b.Dx() != b.Dy() || b.Dx() < square {
+			warn(res.imageFiles[i], "%dx%d is not a square of at least %d", b.Dx(), b.Dy(), square)
+		}
+	}
+
+	rangeLen := clickOpts.GetRangeLen()
+	if len(res.shapes) > 0 && len(res.shapes) <= rangeLen.Max {
+		warn(filepath.Join(cfg.resDir, shapesDir), "%d shapes, more than %d are needed", len(res.shapes), rangeLen.Max)
+	}
+
+	chars, err := cfg.clickChars()
+	if err != nil {
+		warn(filepath.Join(cfg.resDir, fontsDir), "%v", err)
+		return problems
+	}
+	for i, f := range res.fonts {
+		if missing := missingGlyphs(f, chars); len(missing) > 0 {
+			warn(fontName(f, res.fontFiles[i]), "no glyphs for %d of %d chars: %q", len(missing), len(chars), missing)
+		}
+	}
+
+	return problems
+}
+
+// generatable returns a config of every kind and mode the resources can generate
+func generatable(cfg *config, res *resources) []*config {
+	with := func(kind, mode string) *config {
+		c := *cfg
+		c.kind, c.mode = kind, mode
+		if kind != cfg.kind {
+			c.options = nil
+		}
+		return &c
+	}
+
+	var configs []*config
+	if len(res.backgrounds) > 0 {
+		configs = append(configs, with(kindClick, modeText), with(kindClick, modeOddOneOut), with(kindClick, modeCount))
+		if len(res.shapes) > 0 {
+			configs = append(configs, with(kindClick, modeShape))
+		}
+		if len(res.tiles) > 0 {
+			configs = append(configs, with(kindSlide, modeBasic), with(kindSlide, modeDrag))
+		}
+	}
+	if len(res.images) > 0 || len(res.backgrounds) > 0 {
+		configs = append(configs, with(kindRotate, modeBasic))
+	}
+	return configs
+}
+
+// missingGlyphs returns the chars f can't draw
+func missingGlyphs(f *sfnt.Font, chars []string) []string {
+	cf := canvas.NewSfntFont(f)
+
+	var missing []string
+	for _, char := range chars {
+		for _, r := range char {
+			if !cf.HasGlyph(r) {
+				missing = append(missing, char)
+				break
+			}
+		}
+	}
+	return missing
+}
+
+// fontName names a font in reports, the file of a collection holds several fonts
+func fontName(f *sfnt.Font, file string) string {
+	name, err := f.Name(nil, sfnt.NameIDFull)
+	if err != nil || name == "" {
+		return file
+	}
+	return fmt.Sprintf("%s (%s)", file, name)
+}
@@ -0,0 +1,169 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package debug draws the answer of a captcha on top of its master image,
+// so that reported challenges can be inspected
+package debug
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/rotate"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+var (
+	// AnswerColor is the color of the answer marks
+	AnswerColor = color.RGBA{R: 0xff, G: 0x2d, B: 0x55, A: 0xff}
+	// StartColor is the color of the slide tile start
+	StartColor = color.RGBA{R: 0x0a, G: 0x84, B: 0xff, A: 0xff}
+	// OutlineColor keeps the marks visible on any background
+	OutlineColor = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xd0}
+)
+
+const (
+	lineWidth   = 2
+	badgeRadius = 9
+)
+
+// DrawClick draws the dots of a click captcha on its master image
+// params:
+//   - data: Generated captcha data
+//
+// return: Copy of the master image with the answer
+func DrawClick(data click.CaptchaData) *image.NRGBA {
+	return DrawClickDots(data.GetMasterImage().Get(), data.GetData())
+}
+
+// DrawClickDots draws the bounding box of every dot with its Index + 1,
+// the order in which the dots are to be clicked
+// params:
+//   - master: Master image
+//   - dots: Dots of the answer
+//
+// return: Copy of the master image with the answer
+func DrawClickDots(master image.Image, dots map[int]*click.Dot) *image.NRGBA {
+	dst := cloneImage(master)
+
+	keys := make([]int, 0, len(dots))
+	for k := range dots {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	for _, k := range keys {
+		dot := dots[k]
+		rect := image.Rect(dot.X, dot.Y, dot.X+dot.Width, dot.Y+dot.Height)
+		strokeRect(dst, rect, lineWidth+2, OutlineColor)
+		strokeRect(dst, rect, lineWidth, AnswerColor)
+	}
+
+	// badges go last so that boxes never cover them
+	for _, k := range keys {
+		dot := dots[k]
+		badge(dst, image.Pt(dot.X, dot.Y), strconv.Itoa(dot.Index+1), AnswerColor)
+	}
+
+	return dst
+}
+
+// DrawSlide draws the block of a slide captcha on its master image
+// params:
+//   - data: Generated captcha data
+//
+// return: Copy of the master image with the answer
+func DrawSlide(data slide.CaptchaData) *image.NRGBA {
+	return DrawSlideBlock(data.GetMasterImage().Get(), data.GetData())
+}
+
+// DrawSlideBlock draws the target rectangle, the dashed rectangle where the tile
+// starts and the path between them
+// params:
+//   - master: Master image
+//   - block: Block of the answer
+//
+// return: Copy of the master image with the answer
+func DrawSlideBlock(master image.Image, block *slide.Block) *image.NRGBA {
+	dst := cloneImage(master)
+	if block == nil {
+		return dst
+	}
+
+	target := image.Rect(block.X, block.Y, block.X+block.Width, block.Y+block.Height)
+	start := image.Rect(block.DX, block.DY, block.DX+block.Width, block.DY+block.Height)
+
+	from := center(start)
+	to := center(target)
+	line(dst, from, to, lineWidth+2, OutlineColor)
+	line(dst, from, to, lineWidth, StartColor)
+
+	dashedRect(dst, start, lineWidth, StartColor)
+	strokeRect(dst, target, lineWidth+2, OutlineColor)
+	strokeRect(dst, target, lineWidth, AnswerColor)
+	badge(dst, image.Pt(target.Min.X, target.Min.Y), fmt.Sprintf("%d,%d", block.X, block.Y), AnswerColor)
+
+	return dst
+}
+
+// DrawRotate draws the angle of a rotate captcha on its master image
+// params:
+//   - data: Generated captcha data
+//
+// return: Copy of the master image with the answer
+func DrawRotate(data rotate.CaptchaData) *image.NRGBA {
+	return DrawRotateBlock(data.GetMasterImage().Get(), data.GetData())
+}
+
+// DrawRotateBlock draws the clockwise turn that makes the thumb upright, an arc from
+// twelve o'clock to the target angle labeled with the degrees
+// params:
+//   - master: Master image
+//   - block: Block of the answer
+//
+// return: Copy of the master image with the answer
+func DrawRotateBlock(master image.Image, block *rotate.Block) *image.NRGBA {
+	dst := cloneImage(master)
+	if block == nil {
+		return dst
+	}
+
+	b := dst.Bounds()
+	c := center(b)
+	radius := float64(helper.MinInt(b.Dx(), b.Dy())) * 0.35
+	if block.Width > 0 {
+		radius = float64(block.Width) / 2 * 0.8
+	}
+
+	turn := ((360-block.Angle)%360 + 360) % 360
+	from := -math.Pi / 2
+	to := from + float64(turn)*math.Pi/180
+	end := pointf{c.x + radius*math.Cos(to), c.y + radius*math.Sin(to)}
+
+	arc(dst, c, radius, from, to, lineWidth+2, OutlineColor)
+	arc(dst, c, radius, from, to, lineWidth, AnswerColor)
+	line(dst, pointf{c.x, c.y - radius}, c, lineWidth, OutlineColor)
+	line(dst, c, end, lineWidth+2, OutlineColor)
+	line(dst, c, end, lineWidth, AnswerColor)
+	badge(dst, image.Pt(int(c.x), int(c.y+radius)), fmt.Sprintf("%d deg", turn), AnswerColor)
+
+	return dst
+}
+
+// cloneImage copies src into a new NRGBA image at the origin
+func cloneImage(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
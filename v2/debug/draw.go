@@ -0,0 +1,188 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package debug
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+const (
+	dashLen = 5
+	gapLen  = 3
+)
+
+// pointf is a point in image coordinates
+type pointf struct {
+	x, y float64
+}
+
+func center(r image.Rectangle) pointf {
+	return pointf{float64(r.Min.X+r.Max.X) / 2, float64(r.Min.Y+r.Max.Y) / 2}
+}
+
+// line strokes the segment p0-p1 with round caps
+func line(dst *image.NRGBA, p0, p1 pointf, width float64, c color.Color) {
+	polyline(dst, []pointf{p0, p1}, width, c)
+}
+
+// arc strokes the arc from angle a0 to a1, angles are clockwise from three o'clock
+func arc(dst *image.NRGBA, c pointf, r, a0, a1, width float64, col color.Color) {
+	steps := int(math.Ceil(math.Abs(a1-a0) * r / 4))
+	if steps < 1 {
+		return
+	}
+
+	pts := make([]pointf, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		a := a0 + (a1-a0)*float64(i)/float64(steps)
+		pts = append(pts, pointf{c.x + r*math.Cos(a), c.y + r*math.Sin(a)})
+	}
+	polyline(dst, pts, width, col)
+}
+
+// polyline strokes the connected segments with round joins and caps in one pass,
+// so that translucent colors are not blended twice where the pieces overlap
+func polyline(dst *image.NRGBA, pts []pointf, width float64, c color.Color) {
+	b := dst.Bounds()
+	z := vector.NewRasterizer(b.Dx(), b.Dy())
+	hw := width / 2
+
+	for i, p := range pts {
+		circlePath(z, p, hw)
+		if i == 0 {
+			continue
+		}
+
+		q := pts[i-1]
+		dx, dy := p.x-q.x, p.y-q.y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -dy/length*hw, dx/length*hw
+		polygonPath(z, []pointf{{q.x + nx, q.y + ny}, {p.x + nx, p.y + ny}, {p.x - nx, p.y - ny}, {q.x - nx, q.y - ny}})
+	}
+
+	z.Draw(dst, b, image.NewUniform(c), image.Point{})
+}
+
+// circlePath adds a closed circle to the path
+func circlePath(z *vector.Rasterizer, c pointf, r float64) {
+	const steps = 24
+	pts := make([]pointf, steps)
+	for i := range pts {
+		a := float64(i) * 2 * math.Pi / steps
+		pts[i] = pointf{c.x + r*math.Cos(a), c.y + r*math.Sin(a)}
+	}
+	polygonPath(z, pts)
+}
+
+// polygonPath adds a closed polygon to the path, every polygon is wound the same
+// way so that overlapping ones add up instead of cutting holes into each other
+func polygonPath(z *vector.Rasterizer, pts []pointf) {
+	area := 0.0
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		area += p.x*q.y - q.x*p.y
+	}
+
+	at := func(i int) pointf {
+		if area < 0 {
+			return pts[len(pts)-1-i]
+		}
+		return pts[i]
+	}
+
+	p := at(0)
+	z.MoveTo(float32(p.x), float32(p.y))
+	for i := 1; i < len(pts); i++ {
+		p = at(i)
+		z.LineTo(float32(p.x), float32(p.y))
+	}
+	z.ClosePath()
+}
+
+// strokeRect strokes the inside edge of r
+func strokeRect(dst *image.NRGBA, r image.Rectangle, width int, c color.Color) {
+	src := image.NewUniform(c)
+	for _, edge := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width),
+		image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y+width, r.Min.X+width, r.Max.Y-width),
+		image.Rect(r.Max.X-width, r.Min.Y+width, r.Max.X, r.Max.Y-width),
+	} {
+		draw.Draw(dst, edge, src, image.Point{}, draw.Over)
+	}
+}
+
+// dashedRect strokes the edge of r with dashes
+func dashedRect(dst *image.NRGBA, r image.Rectangle, width int, c color.Color) {
+	src := image.NewUniform(c)
+	dash := func(x0, y0, x1, y1 int) {
+		draw.Draw(dst, image.Rect(x0, y0, x1, y1), src, image.Point{}, draw.Over)
+	}
+
+	for x := r.Min.X; x < r.Max.X; x += dashLen + gapLen {
+		x1 := helper.MinInt(x+dashLen, r.Max.X)
+		dash(x, r.Min.Y, x1, r.Min.Y+width)
+		dash(x, r.Max.Y-width, x1, r.Max.Y)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y += dashLen + gapLen {
+		y1 := helper.MinInt(y+dashLen, r.Max.Y)
+		dash(r.Min.X, y, r.Min.X+width, y1)
+		dash(r.Max.X-width, y, r.Max.X, y1)
+	}
+}
+
+// badge draws text in white on a rounded label centered on pt, kept inside the image
+func badge(dst *image.NRGBA, pt image.Point, text string, c color.Color) {
+	face := basicfont.Face7x13
+	w := font.MeasureString(face, text).Ceil()
+	h := face.Ascent + face.Descent
+
+	bw := helper.MaxInt(w+8, badgeRadius*2)
+	bh := helper.MaxInt(h+4, badgeRadius*2)
+	r := image.Rect(pt.X-bw/2, pt.Y-bh/2, pt.X-bw/2+bw, pt.Y-bh/2+bh)
+
+	b := dst.Bounds()
+	if d := b.Min.X - r.Min.X; d > 0 {
+		r = r.Add(image.Pt(d, 0))
+	}
+	if d := r.Max.X - b.Max.X; d > 0 {
+		r = r.Sub(image.Pt(d, 0))
+	}
+	if d := b.Min.Y - r.Min.Y; d > 0 {
+		r = r.Add(image.Pt(0, d))
+	}
+	if d := r.Max.Y - b.Max.Y; d > 0 {
+		r = r.Sub(image.Pt(0, d))
+	}
+
+	radius := float64(bh) / 2
+	left := pointf{float64(r.Min.X) + radius, float64(r.Min.Y) + radius}
+	right := pointf{float64(r.Max.X) - radius, left.y}
+	line(dst, left, right, float64(bh)+2, OutlineColor)
+	line(dst, left, right, float64(bh), c)
+
+	drawer := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+		Dot:  fixed.P(r.Min.X+(r.Dx()-w)/2, r.Min.Y+(r.Dy()-h)/2+face.Ascent),
+	}
+	drawer.DrawString(text)
+}
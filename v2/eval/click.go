@@ -0,0 +1,268 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package eval
+
+import (
+	"errors"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/click"
+)
+
+// NoTargetsErr .
+var NoTargetsErr = errors.New("no targets found in the image")
+
+// ClickChallenge is what a client sees of a click captcha
+type ClickChallenge struct {
+	Master image.Image
+	Thumb  image.Image
+	// Number of targets shown on the thumb
+	Count int
+}
+
+// ClickAttacker finds the targets of a click captcha
+type ClickAttacker interface {
+	Name() string
+	// SolveClick returns the click points in the order of the thumb
+	SolveClick(c *ClickChallenge) ([]image.Point, error)
+}
+
+// DefaultClickAttackers returns the built-in click attackers
+func DefaultClickAttackers() []ClickAttacker {
+	return []ClickAttacker{ColorHistogram{}, ContourMatch{}}
+}
+
+// EvaluateClick runs the attackers against click challenges generated by the captcha
+// params:
+//   - config: Name of the configuration in the results
+//   - capt: Captcha
+//   - attackers: Attackers, the default ones when empty
+//   - opts: Evaluation options
+//
+// return: One result per attacker, error when a challenge can't be generated
+func EvaluateClick(config string, capt click.Captcha, attackers []ClickAttacker, opts ...Option) ([]*Result, error) {
+	if len(attackers) == 0 {
+		attackers = DefaultClickAttackers()
+	}
+	names := make([]string, len(attackers))
+	for i, a := range attackers {
+		names[i] = a.Name()
+	}
+
+	o := newOptions(opts)
+	return evaluate(config, "click", names, o, func() (challenge, error) {
+		data, err := capt.Generate()
+		if err != nil {
+			return nil, err
+		}
+
+		dots := data.GetData()
+		c := &ClickChallenge{
+			Master: data.GetMasterImage().Get(),
+			Thumb:  data.GetThumbImage().Get(),
+			Count:  len(dots),
+		}
+		return func(i int) (bool, error) {
+			points, err := attackers[i].SolveClick(c)
+			if err != nil {
+				return false, err
+			}
+			if len(points) != len(dots) {
+				return false, nil
+			}
+			// the dots of the odd-one-out and counting modes are clicked in any order
+			if !capt.GetMode().Ordered() {
+				set := make([]option.Point, len(points))
+				for j, p := range points {
+					set[j] = option.Point{X: p.X, Y: p.Y}
+				}
+				return click.ValidateSet(set, dots, o.padding), nil
+			}
+			for j, p := range points {
+				dot := dots[j]
+				if !click.Validate(p.X, p.Y, dot.X, dot.Y, dot.Width, dot.Height, o.padding) {
+					return false, nil
+				}
+			}
+			return true, nil
+		}, nil
+	})
+}
+
+// ColorHistogram matches every target of the thumb with the object of the master image
+// whose color histogram is the closest
+type ColorHistogram struct{}
+
+// Name .
+func (ColorHistogram) Name() string {
+	return "color-histogram"
+}
+
+// SolveClick .
+func (ColorHistogram) SolveClick(c *ClickChallenge) ([]image.Point, error) {
+	return matchTargets(c, func(a, b *object) float64 {
+		return a.histogramDistance(b)
+	})
+}
+
+// ContourMatch matches every target of the thumb with the object of the master image
+// whose outline is the closest, compared by their Hu moments
+type ContourMatch struct{}
+
+// Name .
+func (ContourMatch) Name() string {
+	return "contour-match"
+}
+
+// SolveClick .
+func (ContourMatch) SolveClick(c *ClickChallenge) ([]image.Point, error) {
+	return matchTargets(c, func(a, b *object) float64 {
+		return a.momentDistance(b)
+	})
+}
+
+// histogramBins are the hue bins, plus one for dark and one for pale pixels
+const histogramBins = 14
+
+// object is a segmented region with its features
+type object struct {
+	center    image.Point
+	area      int
+	histogram [histogramBins]float64
+	moments   [7]float64
+}
+
+func (o *object) histogramDistance(other *object) float64 {
+	same := 0.0
+	for i := range o.histogram {
+		same += math.Min(o.histogram[i], other.histogram[i])
+	}
+	return 1 - same
+}
+
+func (o *object) momentDistance(other *object) float64 {
+	sum := 0.0
+	for i := 0; i < 4; i++ {
+		sum += math.Abs(o.moments[i] - other.moments[i])
+	}
+	return sum
+}
+
+// matchTargets segments both images and pairs every thumb target, in order, with the
+// closest unused object of the master image
+func matchTargets(c *ClickChallenge, distance func(a, b *object) float64) ([]image.Point, error) {
+	targets := segment(splitImage(c.Thumb), 20)
+	if len(targets) > c.Count {
+		targets = targets[:c.Count]
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].center.X < targets[j].center.X
+	})
+
+	objects := segment(splitImage(c.Master), 60)
+	if len(targets) == 0 || len(objects) < len(targets) {
+		return nil, NoTargetsErr
+	}
+
+	used := make([]bool, len(objects))
+	points := make([]image.Point, 0, len(targets))
+	for _, t := range targets {
+		best, bestDist := -1, math.Inf(1)
+		for i, o := range objects {
+			if used[i] {
+				continue
+			}
+			if d := distance(t, o); d < bestDist {
+				best, bestDist = i, d
+			}
+		}
+		used[best] = true
+		points = append(points, objects[best].center)
+	}
+	return points, nil
+}
+
+// segment finds the objects that stand out of the smoothed image, largest first
+// params:
+//   - p: Image planes
+//   - minArea: Smallest object area in pixels
+//
+// return: Objects
+func segment(p *planes, minArea int) []*object {
+	w, h := p.gray.w, p.gray.h
+	radius := helper.MaxInt(w, h) / 6
+	r, g, b := p.r.boxBlur(radius), p.g.boxBlur(radius), p.b.boxBlur(radius)
+
+	salient := newPlane(w, h)
+	for i := range salient.pix {
+		dr, dg, db := p.r.pix[i]-r.pix[i], p.g.pix[i]-g.pix[i], p.b.pix[i]-b.pix[i]
+		if p.alpha.pix[i] > 0.5 && math.Sqrt(dr*dr+dg*dg+db*db) > 0.12 {
+			salient.pix[i] = 1
+		}
+	}
+
+	// Erode away thin strokes such as the distortion lines of the thumb
+	eroded := salient.boxBlur(1)
+	mask := make([]bool, w*h)
+	for i, v := range eroded.pix {
+		mask[i] = v > 0.999
+	}
+
+	var objects []*object
+	for _, reg := range regions(mask, w, h) {
+		if reg.area() < minArea {
+			continue
+		}
+		o := &object{
+			center:  reg.center(w),
+			area:    reg.area(),
+			moments: huMoments(reg, w),
+		}
+		for _, i := range reg.pixels {
+			o.histogram[hueBin(p.r.pix[i], p.g.pix[i], p.b.pix[i])]++
+		}
+		for i := range o.histogram {
+			o.histogram[i] /= float64(o.area)
+		}
+		objects = append(objects, o)
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].area > objects[j].area
+	})
+	return objects
+}
+
+// hueBin returns the histogram bin of a color
+func hueBin(r, g, b float64) int {
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	if hi < 0.2 {
+		return histogramBins - 2
+	}
+	if hi-lo < 0.1 {
+		return histogramBins - 1
+	}
+
+	var hue float64
+	switch hi {
+	case r:
+		hue = math.Mod((g-b)/(hi-lo), 6)
+	case g:
+		hue = (b-r)/(hi-lo) + 2
+	default:
+		hue = (r-g)/(hi-lo) + 4
+	}
+	if hue < 0 {
+		hue += 6
+	}
+	return helper.MinInt(int(hue/6*(histogramBins-2)), histogramBins-3)
+}
@@ -0,0 +1,190 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package eval measures how hard captchas are for bots, it runs baseline attackers
+// against generated challenges and reports their success rates per configuration.
+//
+// The attackers only see what a client sees: the images, the tile start of slide
+// captchas and the number of targets of click captchas.
+package eval
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// DefaultTrials is the default number of challenges per configuration
+	DefaultTrials = 100
+	// DefaultPadding is the default padding passed to the validators
+	DefaultPadding = 5
+)
+
+// Options .
+type Options struct {
+	trials  int
+	padding int
+	workers int
+}
+
+// Option .
+type Option func(*Options)
+
+// WithTrials sets the number of challenges per configuration
+func WithTrials(val int) Option {
+	return func(opts *Options) {
+		if val > 0 {
+			opts.trials = val
+		}
+	}
+}
+
+// WithPadding sets the padding passed to the validators, in pixels or degrees
+func WithPadding(val int) Option {
+	return func(opts *Options) {
+		if val >= 0 {
+			opts.padding = val
+		}
+	}
+}
+
+// WithWorkers sets the number of challenges generated and attacked at once
+func WithWorkers(val int) Option {
+	return func(opts *Options) {
+		if val > 0 {
+			opts.workers = val
+		}
+	}
+}
+
+func newOptions(opts []Option) *Options {
+	o := &Options{
+		trials:  DefaultTrials,
+		padding: DefaultPadding,
+		workers: runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Result is the outcome of an attacker against a captcha configuration
+type Result struct {
+	Config   string
+	Kind     string
+	Attacker string
+	Trials   int
+	Solved   int
+	Errors   int
+	Elapsed  time.Duration
+}
+
+// SuccessRate is the share of the trials the attacker solved
+func (r *Result) SuccessRate() float64 {
+	if r.Trials == 0 {
+		return 0
+	}
+	return float64(r.Solved) / float64(r.Trials)
+}
+
+// MeanTime is the mean time the attacker took per challenge
+func (r *Result) MeanTime() time.Duration {
+	if r.Trials == 0 {
+		return 0
+	}
+	return r.Elapsed / time.Duration(r.Trials)
+}
+
+// WriteReport writes the results as a table
+// params:
+//   - w: Writer
+//   - results: Results of one or more configurations
+//
+// return: Write error
+func WriteReport(w io.Writer, results []*Result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONFIG\tKIND\tATTACKER\tTRIALS\tSOLVED\tRATE\tERRORS\tTIME")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%.1f%%\t%d\t%v\n",
+			r.Config, r.Kind, r.Attacker, r.Trials, r.Solved, r.SuccessRate()*100, r.Errors,
+			r.MeanTime().Round(10*time.Microsecond))
+	}
+	return tw.Flush()
+}
+
+// challenge runs the i-th attacker against one generated challenge
+type challenge func(i int) (solved bool, err error)
+
+// evaluate generates the challenges and runs every attacker against each of them
+// params:
+//   - config, kind: Names in the results
+//   - attackers: Attacker names
+//   - o: Evaluation options
+//   - generate: Generates a challenge
+//
+// return: One result per attacker, error when a challenge can't be generated
+func evaluate(config, kind string, attackers []string, o *Options, generate func() (challenge, error)) ([]*Result, error) {
+	results := make([]*Result, len(attackers))
+	for i, name := range attackers {
+		results[i] = &Result{Config: config, Kind: kind, Attacker: name}
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	next := make(chan struct{})
+
+	for w := 0; w < o.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range next {
+				ch, err := generate()
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					continue
+				}
+
+				for i := range attackers {
+					start := time.Now()
+					solved, err := ch(i)
+					elapsed := time.Since(start)
+
+					mu.Lock()
+					r := results[i]
+					r.Trials++
+					r.Elapsed += elapsed
+					if err != nil {
+						r.Errors++
+					} else if solved {
+						r.Solved++
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for t := 0; t < o.trials; t++ {
+		next <- struct{}{}
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
@@ -0,0 +1,254 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package eval
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+// plane is a single channel float image
+type plane struct {
+	w, h int
+	pix  []float64
+}
+
+func newPlane(w, h int) *plane {
+	return &plane{w: w, h: h, pix: make([]float64, w*h)}
+}
+
+func (p *plane) at(x, y int) float64 {
+	return p.pix[y*p.w+x]
+}
+
+// sample returns the bilinear interpolation at x, y, clamped to the plane
+func (p *plane) sample(x, y float64) float64 {
+	x = math.Max(0, math.Min(x, float64(p.w-1)))
+	y = math.Max(0, math.Min(y, float64(p.h-1)))
+	x0, y0 := int(x), int(y)
+	x1, y1 := helper.MinInt(x0+1, p.w-1), helper.MinInt(y0+1, p.h-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	top := p.at(x0, y0)*(1-fx) + p.at(x1, y0)*fx
+	bottom := p.at(x0, y1)*(1-fx) + p.at(x1, y1)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// planes splits an image into luma, alpha and RGB planes, all in 0..1
+type planes struct {
+	gray, alpha *plane
+	r, g, b     *plane
+}
+
+func splitImage(img image.Image) *planes {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	ps := &planes{gray: newPlane(w, h), alpha: newPlane(w, h), r: newPlane(w, h), g: newPlane(w, h), b: newPlane(w, h)}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			i := y*w + x
+			ps.r.pix[i] = float64(c.R) / 255
+			ps.g.pix[i] = float64(c.G) / 255
+			ps.b.pix[i] = float64(c.B) / 255
+			ps.alpha.pix[i] = float64(c.A) / 255
+			ps.gray.pix[i] = 0.299*ps.r.pix[i] + 0.587*ps.g.pix[i] + 0.114*ps.b.pix[i]
+		}
+	}
+	return ps
+}
+
+// half downsamples the plane by two
+func (p *plane) half() *plane {
+	out := newPlane(p.w/2, p.h/2)
+	for y := 0; y < out.h; y++ {
+		for x := 0; x < out.w; x++ {
+			out.pix[y*out.w+x] = (p.at(2*x, 2*y) + p.at(2*x+1, 2*y) + p.at(2*x, 2*y+1) + p.at(2*x+1, 2*y+1)) / 4
+		}
+	}
+	return out
+}
+
+// sobel returns the gradient magnitude of the plane
+func (p *plane) sobel() *plane {
+	out := newPlane(p.w, p.h)
+	for y := 1; y < p.h-1; y++ {
+		for x := 1; x < p.w-1; x++ {
+			gx := -p.at(x-1, y-1) - 2*p.at(x-1, y) - p.at(x-1, y+1) +
+				p.at(x+1, y-1) + 2*p.at(x+1, y) + p.at(x+1, y+1)
+			gy := -p.at(x-1, y-1) - 2*p.at(x, y-1) - p.at(x+1, y-1) +
+				p.at(x-1, y+1) + 2*p.at(x, y+1) + p.at(x+1, y+1)
+			out.pix[y*p.w+x] = math.Hypot(gx, gy)
+		}
+	}
+	return out
+}
+
+// boxBlur returns the mean of the (2r+1)^2 window around every pixel, the plane is
+// extended past its borders by point reflection so that gradients stay unbiased
+func (p *plane) boxBlur(r int) *plane {
+	tmp := newPlane(p.w, p.h)
+	for y := 0; y < p.h; y++ {
+		blurLine(p.pix[y*p.w:(y+1)*p.w], tmp.pix[y*p.w:(y+1)*p.w], r)
+	}
+
+	out := newPlane(p.w, p.h)
+	col := make([]float64, p.h)
+	blurred := make([]float64, p.h)
+	for x := 0; x < p.w; x++ {
+		for y := 0; y < p.h; y++ {
+			col[y] = tmp.pix[y*p.w+x]
+		}
+		blurLine(col, blurred, r)
+		for y := 0; y < p.h; y++ {
+			out.pix[y*p.w+x] = blurred[y]
+		}
+	}
+	return out
+}
+
+// blurLine writes the running mean of the (2r+1) window of src to dst
+func blurLine(src, dst []float64, r int) {
+	n := len(src)
+	at := func(i int) float64 {
+		switch {
+		case i < 0:
+			return 2*src[0] - src[helper.MinInt(-i, n-1)]
+		case i >= n:
+			return 2*src[n-1] - src[helper.MaxInt(2*(n-1)-i, 0)]
+		}
+		return src[i]
+	}
+
+	sum := 0.0
+	for k := -r; k <= r; k++ {
+		sum += at(k)
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = sum / float64(2*r+1)
+		sum += at(i+r+1) - at(i-r)
+	}
+}
+
+// region is a connected set of pixels
+type region struct {
+	pixels []int
+	minX   int
+	minY   int
+	maxX   int
+	maxY   int
+}
+
+func (r *region) area() int {
+	return len(r.pixels)
+}
+
+func (r *region) center(w int) image.Point {
+	sx, sy := 0, 0
+	for _, i := range r.pixels {
+		sx += i % w
+		sy += i / w
+	}
+	n := len(r.pixels)
+	return image.Pt(sx/n, sy/n)
+}
+
+// regions labels the 8-connected regions of the mask
+func regions(mask []bool, w, h int) []*region {
+	seen := make([]bool, len(mask))
+	var out []*region
+	var stack []int
+
+	for start, on := range mask {
+		if !on || seen[start] {
+			continue
+		}
+
+		r := &region{minX: w, minY: h, maxX: -1, maxY: -1}
+		seen[start] = true
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			r.pixels = append(r.pixels, i)
+
+			x, y := i%w, i/w
+			r.minX, r.maxX = helper.MinInt(r.minX, x), helper.MaxInt(r.maxX, x)
+			r.minY, r.maxY = helper.MinInt(r.minY, y), helper.MaxInt(r.maxY, y)
+
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					j := ny*w + nx
+					if mask[j] && !seen[j] {
+						seen[j] = true
+						stack = append(stack, j)
+					}
+				}
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// huMoments returns the log scaled Hu moments of a region, they are invariant
+// to translation, scale and rotation
+func huMoments(r *region, w int) [7]float64 {
+	var m00, m10, m01 float64
+	for _, i := range r.pixels {
+		x, y := float64(i%w), float64(i/w)
+		m00++
+		m10 += x
+		m01 += y
+	}
+	cx, cy := m10/m00, m01/m00
+
+	var mu20, mu02, mu11, mu30, mu03, mu21, mu12 float64
+	for _, i := range r.pixels {
+		x, y := float64(i%w)-cx, float64(i/w)-cy
+		mu20 += x * x
+		mu02 += y * y
+		mu11 += x * y
+		mu30 += x * x * x
+		mu03 += y * y * y
+		mu21 += x * x * y
+		mu12 += x * y * y
+	}
+
+	norm := func(mu float64, p, q int) float64 {
+		return mu / math.Pow(m00, 1+float64(p+q)/2)
+	}
+	n20, n02, n11 := norm(mu20, 2, 0), norm(mu02, 0, 2), norm(mu11, 1, 1)
+	n30, n03, n21, n12 := norm(mu30, 3, 0), norm(mu03, 0, 3), norm(mu21, 2, 1), norm(mu12, 1, 2)
+
+	h := [7]float64{
+		n20 + n02,
+		(n20-n02)*(n20-n02) + 4*n11*n11,
+		(n30-3*n12)*(n30-3*n12) + (3*n21-n03)*(3*n21-n03),
+		(n30+n12)*(n30+n12) + (n21+n03)*(n21+n03),
+		(n30-3*n12)*(n30+n12)*((n30+n12)*(n30+n12)-3*(n21+n03)*(n21+n03)) +
+			(3*n21-n03)*(n21+n03)*(3*(n30+n12)*(n30+n12)-(n21+n03)*(n21+n03)),
+		(n20-n02)*((n30+n12)*(n30+n12)-(n21+n03)*(n21+n03)) + 4*n11*(n30+n12)*(n21+n03),
+		(3*n21-n03)*(n30+n12)*((n30+n12)*(n30+n12)-3*(n21+n03)*(n21+n03)) -
+			(n30-3*n12)*(n21+n03)*(3*(n30+n12)*(n30+n12)-(n21+n03)*(n21+n03)),
+	}
+
+	for i, v := range h {
+		if v != 0 {
+			h[i] = -math.Copysign(1, v) * math.Log10(math.Abs(v))
+		}
+	}
+	return h
+}
@@ -0,0 +1,115 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package eval
+
+import (
+	"image"
+	"math"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/rotate"
+)
+
+// RotateChallenge is what a client sees of a rotate captcha
+type RotateChallenge struct {
+	Master image.Image
+	Thumb  image.Image
+}
+
+// RotateAttacker finds the angle of a rotate captcha
+type RotateAttacker interface {
+	Name() string
+	// SolveRotate returns the angle the thumb is turned by, in degrees
+	SolveRotate(c *RotateChallenge) (angle int, err error)
+}
+
+// DefaultRotateAttackers returns the built-in rotate attackers
+func DefaultRotateAttackers() []RotateAttacker {
+	return []RotateAttacker{EdgeContinuity{}}
+}
+
+// EvaluateRotate runs the attackers against rotate challenges generated by the captcha
+// params:
+//   - config: Name of the configuration in the results
+//   - capt: Captcha
+//   - attackers: Attackers, the default ones when empty
+//   - opts: Evaluation options
+//
+// return: One result per attacker, error when a challenge can't be generated
+func EvaluateRotate(config string, capt rotate.Captcha, attackers []RotateAttacker, opts ...Option) ([]*Result, error) {
+	if len(attackers) == 0 {
+		attackers = DefaultRotateAttackers()
+	}
+	names := make([]string, len(attackers))
+	for i, a := range attackers {
+		names[i] = a.Name()
+	}
+
+	o := newOptions(opts)
+	return evaluate(config, "rotate", names, o, func() (challenge, error) {
+		data, err := capt.Generate()
+		if err != nil {
+			return nil, err
+		}
+
+		block := data.GetData()
+		c := &RotateChallenge{
+			Master: data.GetMasterImage().Get(),
+			Thumb:  data.GetThumbImage().Get(),
+		}
+		return func(i int) (bool, error) {
+			angle, err := attackers[i].SolveRotate(c)
+			if err != nil {
+				return false, err
+			}
+			return rotate.Validate(angle, block.Angle, o.padding), nil
+		}, nil
+	})
+}
+
+// EdgeContinuity compares the rim of the thumb with the ring of the master image just
+// outside of it and picks the turn where the colors continue across the seam
+type EdgeContinuity struct{}
+
+// Name .
+func (EdgeContinuity) Name() string {
+	return "edge-continuity"
+}
+
+// SolveRotate .
+func (EdgeContinuity) SolveRotate(c *RotateChallenge) (int, error) {
+	master, thumb := splitImage(c.Master), splitImage(c.Thumb)
+
+	size := float64(helper.MinInt(thumb.gray.w, thumb.gray.h))
+	inner := ring(thumb, float64(thumb.gray.w)/2, float64(thumb.gray.h)/2, size/2-3)
+	outer := ring(master, float64(master.gray.w)/2, float64(master.gray.h)/2, size/2+2)
+
+	best, bestDiff := 0, math.Inf(1)
+	for turn := 0; turn < 360; turn++ {
+		diff := 0.0
+		for i := range outer {
+			a, b := outer[i], inner[(i+360-turn)%360]
+			diff += (a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1]) + (a[2]-b[2])*(a[2]-b[2])
+		}
+		if diff < bestDiff {
+			best, bestDiff = turn, diff
+		}
+	}
+	return best, nil
+}
+
+// ring samples the colors on a circle, one per degree clockwise from 12 o'clock
+func ring(p *planes, cx, cy, radius float64) [][3]float64 {
+	out := make([][3]float64, 360)
+	for deg := range out {
+		rad := float64(deg) * math.Pi / 180
+		x := cx + radius*math.Sin(rad)
+		y := cy - radius*math.Cos(rad)
+		out[deg] = [3]float64{p.r.sample(x, y), p.g.sample(x, y), p.b.sample(x, y)}
+	}
+	return out
+}
@@ -0,0 +1,259 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package eval
+
+import (
+	"errors"
+	"image"
+	"math"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+// EmptyTileErr .
+var EmptyTileErr = errors.New("the tile image has no opaque pixels")
+
+// SlideChallenge is what a client sees of a slide captcha
+type SlideChallenge struct {
+	Master image.Image
+	Tile   image.Image
+	// Start position of the tile
+	TileX int
+	TileY int
+	// The tile only moves along its row, as in the basic mode
+	Horizontal bool
+}
+
+// SlideAttacker finds the gap of a slide captcha
+type SlideAttacker interface {
+	Name() string
+	// SolveSlide returns the position the tile is dropped at
+	SolveSlide(c *SlideChallenge) (x, y int, err error)
+}
+
+// DefaultSlideAttackers returns the built-in slide attackers
+func DefaultSlideAttackers() []SlideAttacker {
+	return []SlideAttacker{TemplateMatch{}, EdgeMatch{}}
+}
+
+// EvaluateSlide runs the attackers against slide challenges generated by the captcha
+// params:
+//   - config: Name of the configuration in the results
+//   - capt: Captcha
+//   - attackers: Attackers, the default ones when empty
+//   - opts: Evaluation options
+//
+// return: One result per attacker, error when a challenge can't be generated
+func EvaluateSlide(config string, capt slide.Captcha, attackers []SlideAttacker, opts ...Option) ([]*Result, error) {
+	if len(attackers) == 0 {
+		attackers = DefaultSlideAttackers()
+	}
+	names := make([]string, len(attackers))
+	for i, a := range attackers {
+		names[i] = a.Name()
+	}
+
+	o := newOptions(opts)
+	return evaluate(config, "slide", names, o, func() (challenge, error) {
+		data, err := capt.Generate()
+		if err != nil {
+			return nil, err
+		}
+
+		block := data.GetData()
+		c := &SlideChallenge{
+			Master:     data.GetMasterImage().Get(),
+			Tile:       data.GetTileImage().Get(),
+			TileX:      block.DX,
+			TileY:      block.DY,
+			Horizontal: capt.GetMode() == slide.ModeBasic,
+		}
+		return func(i int) (bool, error) {
+			x, y, err := attackers[i].SolveSlide(c)
+			if err != nil {
+				return false, err
+			}
+			return slide.Validate(x, y, block.X, block.Y, o.padding), nil
+		}, nil
+	})
+}
+
+// slideSearch holds the positions a slide attacker tries
+type slideSearch struct {
+	minX, maxX int
+	minY, maxY int
+}
+
+func newSlideSearch(c *SlideChallenge, w, h, tw, th int) slideSearch {
+	s := slideSearch{maxX: w - tw, minY: 0, maxY: h - th}
+	if c.Horizontal {
+		s.minY, s.maxY = c.TileY, c.TileY
+	}
+	return s
+}
+
+// best returns the position with the highest score
+func (s slideSearch) best(score func(x, y int) float64) (int, int) {
+	bx, by, bs := s.minX, s.minY, math.Inf(-1)
+	for y := s.minY; y <= s.maxY; y++ {
+		for x := s.minX; x <= s.maxX; x++ {
+			if v := score(x, y); v > bs {
+				bx, by, bs = x, y, v
+			}
+		}
+	}
+	return bx, by
+}
+
+// TemplateMatch slides the tile over the master image and picks the position where
+// the normalized cross correlation with the tile content is the highest, a coarse
+// search at half size is refined at full size
+type TemplateMatch struct{}
+
+// Name .
+func (TemplateMatch) Name() string {
+	return "template-match"
+}
+
+// SolveSlide .
+func (TemplateMatch) SolveSlide(c *SlideChallenge) (int, int, error) {
+	master, tile := splitImage(c.Master), splitImage(c.Tile)
+
+	// Only the inside of the tile, its outline is drawn over by the overlay
+	inside := tile.alpha.boxBlur(2)
+	mask := make([]bool, len(inside.pix))
+	for i, v := range inside.pix {
+		mask[i] = v > 0.99
+	}
+
+	coarse := newTemplate(tile.gray.half(), halfMask(mask, tile.gray.w, tile.gray.h))
+	fine := newTemplate(tile.gray, mask)
+	if coarse == nil || fine == nil {
+		return 0, 0, EmptyTileErr
+	}
+
+	half := master.gray.half()
+	s := newSlideSearch(c, half.w*2, half.h*2, tile.gray.w, tile.gray.h)
+	hs := slideSearch{maxX: s.maxX / 2, minY: s.minY / 2, maxY: s.maxY / 2}
+	cx, cy := hs.best(func(x, y int) float64 {
+		return coarse.ncc(half, x, y)
+	})
+
+	fs := slideSearch{
+		minX: helper.MaxInt(0, cx*2-2),
+		maxX: helper.MinInt(s.maxX, cx*2+2),
+		minY: helper.MaxInt(s.minY, cy*2-2),
+		maxY: helper.MinInt(s.maxY, cy*2+2),
+	}
+	x, y := fs.best(func(x, y int) float64 {
+		return fine.ncc(master.gray, x, y)
+	})
+	return x, y, nil
+}
+
+// EdgeMatch follows the outline of the tile over the gradient magnitude of the master
+// image and picks the position where the outline sits on the strongest edges
+type EdgeMatch struct{}
+
+// Name .
+func (EdgeMatch) Name() string {
+	return "edge-match"
+}
+
+// SolveSlide .
+func (EdgeMatch) SolveSlide(c *SlideChallenge) (int, int, error) {
+	master, tile := splitImage(c.Master), splitImage(c.Tile)
+	edges := master.gray.sobel()
+
+	tw, th := tile.alpha.w, tile.alpha.h
+	var outline []image.Point
+	for y := 0; y < th; y++ {
+		for x := 0; x < tw; x++ {
+			if tile.alpha.at(x, y) < 0.5 {
+				continue
+			}
+			if x == 0 || y == 0 || x == tw-1 || y == th-1 ||
+				tile.alpha.at(x-1, y) < 0.5 || tile.alpha.at(x+1, y) < 0.5 ||
+				tile.alpha.at(x, y-1) < 0.5 || tile.alpha.at(x, y+1) < 0.5 {
+				outline = append(outline, image.Pt(x, y))
+			}
+		}
+	}
+	if len(outline) == 0 {
+		return 0, 0, EmptyTileErr
+	}
+
+	s := newSlideSearch(c, edges.w, edges.h, tw, th)
+	x, y := s.best(func(x, y int) float64 {
+		sum := 0.0
+		for _, p := range outline {
+			sum += edges.at(x+p.X, y+p.Y)
+		}
+		return sum
+	})
+	return x, y, nil
+}
+
+// template is a zero mean template over the masked pixels
+type template struct {
+	offsets []image.Point
+	values  []float64
+	norm    float64
+}
+
+func newTemplate(p *plane, mask []bool) *template {
+	t := &template{}
+	mean := 0.0
+	for i, on := range mask {
+		if on {
+			t.offsets = append(t.offsets, image.Pt(i%p.w, i/p.w))
+			t.values = append(t.values, p.pix[i])
+			mean += p.pix[i]
+		}
+	}
+	if len(t.values) == 0 {
+		return nil
+	}
+
+	mean /= float64(len(t.values))
+	for i := range t.values {
+		t.values[i] -= mean
+		t.norm += t.values[i] * t.values[i]
+	}
+	t.norm = math.Sqrt(t.norm)
+	return t
+}
+
+// ncc returns the normalized cross correlation of the template with the plane at x, y
+func (t *template) ncc(p *plane, x, y int) float64 {
+	var cross, sum, sq float64
+	for i, o := range t.offsets {
+		v := p.at(x+o.X, y+o.Y)
+		cross += t.values[i] * v
+		sum += v
+		sq += v * v
+	}
+	n := float64(len(t.offsets))
+	variance := sq - sum*sum/n
+	if variance <= 1e-9 || t.norm <= 1e-9 {
+		return 0
+	}
+	return cross / (t.norm * math.Sqrt(variance))
+}
+
+// halfMask downsamples a mask by two, a pixel is kept when all four are set
+func halfMask(mask []bool, w, h int) []bool {
+	hw, hh := w/2, h/2
+	out := make([]bool, hw*hh)
+	for y := 0; y < hh; y++ {
+		for x := 0; x < hw; x++ {
+			out[y*hw+x] = mask[2*y*w+2*x] && mask[2*y*w+2*x+1] && mask[(2*y+1)*w+2*x] && mask[(2*y+1)*w+2*x+1]
+		}
+	}
+	return out
+}
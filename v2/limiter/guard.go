@@ -0,0 +1,118 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package limiter
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wenlng/go-captcha/v2/store"
+)
+
+var (
+	GenerateLimitErr = errors.New("too many captcha generations")
+	VerifyLimitErr   = errors.New("too many captcha verifications")
+	EmptyStoreErr    = errors.New("no challenge store")
+)
+
+// GuardOptions .
+type GuardOptions struct {
+	generate Limiter
+	verify   Limiter
+	store    store.Store
+}
+
+// GuardOption .
+type GuardOption func(*GuardOptions)
+
+// WithGenerateLimiter sets the limiter of the generations of a key, nil does not limit them
+func WithGenerateLimiter(l Limiter) GuardOption {
+	return func(opts *GuardOptions) {
+		opts.generate = l
+	}
+}
+
+// WithVerifyLimiter sets the limiter of the verifications of a key, nil does not limit them
+func WithVerifyLimiter(l Limiter) GuardOption {
+	return func(opts *GuardOptions) {
+		opts.verify = l
+	}
+}
+
+// WithStore sets the challenge store, it enforces the attempts per challenge, see store.WithMaxAttempts
+func WithStore(s store.Store) GuardOption {
+	return func(opts *GuardOptions) {
+		opts.store = s
+	}
+}
+
+// Guard budgets the generations and verifications of each client key and the verify attempts of
+// each challenge, a challenge is invalidated by its store after too many failed attempts
+type Guard struct {
+	opts *GuardOptions
+}
+
+// NewGuard creates a guard, without options it allows 10 generations per key and second
+// with bursts of 20, 30 verifications per key and minute, and keeps the challenges in a store.Memory
+// params:
+//   - opts: Options
+//
+// return: Guard instance
+func NewGuard(opts ...GuardOption) *Guard {
+	o := &GuardOptions{
+		generate: NewTokenBucket(10, 20),
+		verify:   NewSlidingWindow(30, time.Minute),
+		store:    store.NewMemory(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Guard{opts: o}
+}
+
+// Store returns the challenge store of the guard
+func (g *Guard) Store() store.Store {
+	return g.opts.store
+}
+
+// Issue counts a generation of the key and stores its challenge
+// params:
+//   - key: Client key
+//   - c: Challenge, an empty ID gets store.NewID
+//
+// return: Error information, GenerateLimitErr when the key is over its budget
+func (g *Guard) Issue(key string, c *store.Challenge) error {
+	if g.opts.generate != nil && !g.opts.generate.Allow(key) {
+		return GenerateLimitErr
+	}
+	if g.opts.store == nil {
+		return EmptyStoreErr
+	}
+	if c.ID == "" {
+		c.ID = store.NewID()
+	}
+	return g.opts.store.Put(c)
+}
+
+// Verify counts a verification of the key and an attempt on the challenge, then runs check
+// params:
+//   - key: Client key
+//   - id: Challenge ID
+//   - check: Validation of the answer against the stored challenge
+//
+// returns:
+//   - bool: Whether the answer passed
+//   - error: VerifyLimitErr when the key is over its budget, or an error of the store such as store.InvalidatedErr
+func (g *Guard) Verify(key, id string, check func(c *store.Challenge) bool) (bool, error) {
+	if g.opts.verify != nil && !g.opts.verify.Allow(key) {
+		return false, VerifyLimitErr
+	}
+	if g.opts.store == nil {
+		return false, EmptyStoreErr
+	}
+	return g.opts.store.Verify(id, check)
+}
@@ -0,0 +1,183 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package limiter limits the generations and verifications of each client key, such as an IP
+// address or a session, so challenges cannot be brute-forced by retrying answers.
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepEvery is the number of calls between two removals of the idle keys
+const sweepEvery = 1024
+
+// Limiter decides whether a key may do one more action
+type Limiter interface {
+	// Allow reports whether the key may act now and counts the action when it may
+	Allow(key string) bool
+}
+
+var (
+	_ Limiter = (*TokenBucket)(nil)
+	_ Limiter = (*SlidingWindow)(nil)
+)
+
+// TokenBucket gives each key a bucket of burst tokens refilled at rate tokens per second,
+// every allowed action takes a token. A TokenBucket is safe for concurrent use.
+type TokenBucket struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	calls   int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a token bucket limiter
+// params:
+//   - rate: Tokens refilled per second
+//   - burst: Size of the bucket, values less than 1 use 1
+//
+// return: TokenBucket instance
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:    math.Max(rate, 0),
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow .
+func (t *TokenBucket) Allow(key string) bool {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.calls++; t.calls%sweepEvery == 0 {
+		t.sweep(now)
+	}
+
+	b, ok := t.buckets[key]
+	if !ok {
+		b = &bucket{tokens: t.burst, last: now}
+		t.buckets[key] = b
+	}
+	b.tokens = t.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill returns the tokens of a bucket at a time
+func (t *TokenBucket) refill(b *bucket, now time.Time) float64 {
+	return math.Min(t.burst, b.tokens+now.Sub(b.last).Seconds()*t.rate)
+}
+
+// sweep removes the full buckets, t.mu must be held
+func (t *TokenBucket) sweep(now time.Time) {
+	for key, b := range t.buckets {
+		if t.refill(b, now) >= t.burst {
+			delete(t.buckets, key)
+		}
+	}
+}
+
+// SlidingWindow allows each key limit actions in any window of the given length, it weights
+// the count of the previous fixed window by the part of it still in the sliding window.
+// A SlidingWindow is safe for concurrent use.
+type SlidingWindow struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*window
+	calls   int
+}
+
+type window struct {
+	start time.Time
+	prev  int
+	curr  int
+}
+
+// NewSlidingWindow creates a sliding window limiter
+// params:
+//   - limit: Actions allowed in a window, values less than 1 use 1
+//   - length: Length of the window, values less than or equal to 0 use a second
+//
+// return: SlidingWindow instance
+func NewSlidingWindow(limit int, length time.Duration) *SlidingWindow {
+	if limit < 1 {
+		limit = 1
+	}
+	if length <= 0 {
+		length = time.Second
+	}
+	return &SlidingWindow{limit: limit, window: length, windows: make(map[string]*window)}
+}
+
+// Allow .
+func (s *SlidingWindow) Allow(key string) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.calls++; s.calls%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	w, ok := s.windows[key]
+	if !ok {
+		w = &window{start: now}
+		s.windows[key] = w
+	}
+	s.advance(w, now)
+
+	elapsed := float64(now.Sub(w.start)) / float64(s.window)
+	if float64(w.prev)*(1-elapsed)+float64(w.curr) >= float64(s.limit) {
+		return false
+	}
+	w.curr++
+	return true
+}
+
+// advance moves the fixed windows of a key up to a time
+func (s *SlidingWindow) advance(w *window, now time.Time) {
+	passed := now.Sub(w.start) / s.window
+	if passed <= 0 {
+		return
+	}
+	if passed == 1 {
+		w.prev = w.curr
+	} else {
+		w.prev = 0
+	}
+	w.curr = 0
+	w.start = w.start.Add(passed * s.window)
+}
+
+// sweep removes the keys without action in the last two windows, s.mu must be held
+func (s *SlidingWindow) sweep(now time.Time) {
+	for key, w := range s.windows {
+		if now.Sub(w.start) >= 2*s.window {
+			delete(s.windows, key)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package passive verifies users without showing a captcha, the page gets a signed challenge,
+// collects telemetry while the user interacts with it and submits both, the server scores the
+// telemetry with a risk.Scorer and only escalates to a click, slide or rotate captcha when the
+// score is too high, or denies the client.
+package passive
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/wenlng/go-captcha/v2/risk"
+	"github.com/wenlng/go-captcha/v2/store"
+)
+
+const (
+	// DefaultTTL is the default lifetime of a challenge
+	DefaultTTL = 10 * time.Minute
+	// DefaultChallengeThreshold is the default score from which a client is escalated to a captcha
+	DefaultChallengeThreshold = 0.4
+	// DefaultDenyThreshold is the default score from which a client is denied
+	DefaultDenyThreshold = 0.85
+)
+
+var (
+	EmptySecretErr = errors.New("passive secret is empty")
+	SignatureErr   = errors.New("passive challenge signature is invalid")
+	ExpiredErr     = errors.New("passive challenge expired")
+)
+
+// Challenge is a passive challenge, it is sent to the page as JSON and sent back in the telemetry
+type Challenge struct {
+	ID        string `json:"id"`
+	IssuedAt  int64  `json:"issued_at"`
+	ExpiresAt int64  `json:"expires_at"`
+	Signature string `json:"signature"`
+}
+
+// Options .
+type Options struct {
+	ttl    time.Duration
+	scorer *risk.Scorer
+	store  store.Store
+}
+
+// Option .
+type Option func(*Options)
+
+// WithTTL sets the lifetime of the challenges
+func WithTTL(val time.Duration) Option {
+	return func(opts *Options) {
+		if val > 0 {
+			opts.ttl = val
+		}
+	}
+}
+
+// WithScorer sets the scorer of the telemetry, see DefaultScorer
+func WithScorer(val *risk.Scorer) Option {
+	return func(opts *Options) {
+		if val != nil {
+			opts.scorer = val
+		}
+	}
+}
+
+// WithStore sets the store that lets each challenge be submitted once, nil makes the challenges
+// stateless and replayable until they expire
+func WithStore(val store.Store) Option {
+	return func(opts *Options) {
+		opts.store = val
+	}
+}
+
+// DefaultScorer returns the scorer used without WithScorer, it weights the solve time, the
+// fingerprint, the pointer trajectory, the keystrokes and the interaction
+func DefaultScorer() *risk.Scorer {
+	return risk.NewScorer(
+		risk.WithSignal(risk.NewSolveTime(300*time.Millisecond, DefaultTTL), 1),
+		risk.WithSignal(risk.NewFingerprint(risk.DefaultFingerprintLimit, risk.DefaultFingerprintWindow), 1),
+		risk.WithSignal(risk.NewTrajectory(), 2),
+		risk.WithSignal(NewKeystrokes(), 1),
+		risk.WithSignal(NewInteraction(), 2),
+		risk.WithThresholds(DefaultChallengeThreshold, DefaultDenyThreshold),
+	)
+}
+
+// Issuer issues and verifies passive challenges, it is safe for concurrent use
+type Issuer struct {
+	secret []byte
+	opts   *Options
+}
+
+// NewIssuer creates an issuer, without WithStore the challenges are kept in a store.Memory
+// params:
+//   - secret: HMAC key of the signatures, shared by the servers that verify
+//   - opts: Options
+//
+// returns:
+//   - *Issuer: Issuer instance
+//   - error: EmptySecretErr when the secret is empty
+func NewIssuer(secret []byte, opts ...Option) (*Issuer, error) {
+	if len(secret) == 0 {
+		return nil, EmptySecretErr
+	}
+
+	o := &Options{
+		ttl:   DefaultTTL,
+		store: store.NewMemory(store.WithTTL(DefaultTTL), store.WithMaxAttempts(1)),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.scorer == nil {
+		o.scorer = DefaultScorer()
+	}
+	return &Issuer{secret: append([]byte(nil), secret...), opts: o}, nil
+}
+
+// Issue issues a challenge
+// returns:
+//   - *Challenge: Signed challenge
+//   - error: Error of the store
+func (i *Issuer) Issue() (*Challenge, error) {
+	now := time.Now()
+	c := &Challenge{
+		ID:        store.NewID(),
+		IssuedAt:  now.UnixMilli(),
+		ExpiresAt: now.Add(i.opts.ttl).UnixMilli(),
+	}
+	c.Signature = hex.EncodeToString(i.sign(c))
+
+	if i.opts.store != nil {
+		err := i.opts.store.Put(&store.Challenge{
+			ID:        c.ID,
+			Kind:      "passive",
+			IssuedAt:  now,
+			ExpiresAt: now.Add(i.opts.ttl),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Verify checks the challenge of the telemetry and scores it
+// params:
+//   - t: Telemetry submitted by the page
+//
+// returns:
+//   - *risk.Result: risk.Allow passes the client, risk.Challenge escalates it to a captcha, risk.Deny denies it
+//   - error: SignatureErr, ExpiredErr or an error of the store such as store.NotFoundErr for a replayed challenge
+func (i *Issuer) Verify(t *Telemetry) (*risk.Result, error) {
+	c := &t.Challenge
+	sig, err := hex.DecodeString(c.Signature)
+	if err != nil || !hmac.Equal(sig, i.sign(c)) {
+		return nil, SignatureErr
+	}
+	now := time.Now()
+	if now.UnixMilli() > c.ExpiresAt {
+		return nil, ExpiredErr
+	}
+
+	if i.opts.store != nil {
+		// the challenge is consumed whatever the decision
+		consume := func(*store.Challenge) bool { return true }
+		if _, err = i.opts.store.Verify(c.ID, consume); err != nil {
+			return nil, err
+		}
+	}
+
+	return i.opts.scorer.Score(&risk.Attempt{
+		Kind:        "passive",
+		ChallengeID: c.ID,
+		IssuedAt:    time.UnixMilli(c.IssuedAt),
+		SubmittedAt: now,
+		Fingerprint: t.Fingerprint,
+		Trajectory:  t.track(),
+		Extra:       t,
+	}), nil
+}
+
+// sign returns the HMAC of the fields of a challenge
+func (i *Issuer) sign(c *Challenge) []byte {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(c.ID + "\x00"))
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[0:], uint64(c.IssuedAt))
+	binary.BigEndian.PutUint64(buf[8:], uint64(c.ExpiresAt))
+	mac.Write(buf[:])
+	return mac.Sum(nil)
+}
@@ -0,0 +1,191 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package passive
+
+import (
+	"math"
+
+	"github.com/wenlng/go-captcha/v2/risk"
+)
+
+// Telemetry is what the client collects between loading the challenge and submitting it, all times
+// are in milliseconds since the challenge was loaded:
+//
+//	{
+//	  "challenge": { ...the challenge as issued... },
+//	  "fingerprint": "opaque client fingerprint",
+//	  "pointer": [{"x": 12, "y": 40, "t": 16}, ...],
+//	  "keys": [{"down": 820, "up": 905}, ...],
+//	  "focus": [{"type": "focus", "t": 0}, {"type": "blur", "t": 3100}, ...],
+//	  "duration": 4200
+//	}
+//
+// Pointer samples come from pointermove and pointerdown, keys only carry their timing, never the
+// key, and focus types are "focus", "blur", "visible" and "hidden".
+type Telemetry struct {
+	Challenge   Challenge      `json:"challenge"`
+	Fingerprint string         `json:"fingerprint,omitempty"`
+	Pointer     []PointerEvent `json:"pointer,omitempty"`
+	Keys        []KeyEvent     `json:"keys,omitempty"`
+	Focus       []FocusEvent   `json:"focus,omitempty"`
+	Duration    int64          `json:"duration"`
+}
+
+// PointerEvent is a pointer sample
+type PointerEvent struct {
+	X int   `json:"x"`
+	Y int   `json:"y"`
+	T int64 `json:"t"`
+}
+
+// KeyEvent is the timing of a keystroke
+type KeyEvent struct {
+	Down int64 `json:"down"`
+	Up   int64 `json:"up"`
+}
+
+// FocusEvent is a change of focus or visibility of the page
+type FocusEvent struct {
+	Type string `json:"type"`
+	T    int64  `json:"t"`
+}
+
+// track returns the pointer samples as a risk trajectory
+func (t *Telemetry) track() []risk.TrackPoint {
+	track := make([]risk.TrackPoint, 0, len(t.Pointer))
+	for _, p := range t.Pointer {
+		track = append(track, risk.TrackPoint{X: p.X, Y: p.Y, T: p.T})
+	}
+	return track
+}
+
+// lastEvent returns the time of the last pointer sample, key or focus event
+func (t *Telemetry) lastEvent() int64 {
+	times := make([]int64, 0, len(t.Pointer)+len(t.Keys)+len(t.Focus))
+	for _, p := range t.Pointer {
+		times = append(times, p.T)
+	}
+	for _, k := range t.Keys {
+		times = append(times, k.Up)
+	}
+	for _, f := range t.Focus {
+		times = append(times, f.T)
+	}
+
+	var last int64
+	for _, v := range times {
+		if v > last {
+			last = v
+		}
+	}
+	return last
+}
+
+// telemetry returns the telemetry of an attempt
+func telemetry(a *risk.Attempt) *Telemetry {
+	t, _ := a.Extra.(*Telemetry)
+	return t
+}
+
+var (
+	_ risk.Signal = (*Keystrokes)(nil)
+	_ risk.Signal = (*Interaction)(nil)
+)
+
+// Keystrokes scores the timing of the keystrokes, scripted typing has no dwell time
+// or perfectly regular intervals. Telemetry with fewer than 3 keys is skipped.
+type Keystrokes struct{}
+
+// NewKeystrokes creates a keystrokes signal
+func NewKeystrokes() *Keystrokes {
+	return &Keystrokes{}
+}
+
+// Name .
+func (k *Keystrokes) Name() string {
+	return "keystrokes"
+}
+
+// Evaluate .
+func (k *Keystrokes) Evaluate(a *risk.Attempt) (float64, bool) {
+	t := telemetry(a)
+	if t == nil || len(t.Keys) < 3 {
+		return 0, false
+	}
+
+	dwells := make([]float64, 0, len(t.Keys))
+	flights := make([]float64, 0, len(t.Keys)-1)
+	for i, key := range t.Keys {
+		if key.Up < key.Down {
+			return 1, true
+		}
+		dwells = append(dwells, float64(key.Up-key.Down))
+		if i > 0 {
+			flights = append(flights, float64(key.Down-t.Keys[i-1].Down))
+		}
+	}
+
+	dwell := 0.0
+	if mean(dwells) < 5 {
+		dwell = 1
+	}
+	return math.Max(dwell, risk.LinearDown(risk.Variation(flights), 0.05, 0.2)), true
+}
+
+// Interaction scores the absence of human interaction: telemetry without pointer samples, keys or
+// focus events scores 1, as does a page that was hidden the whole time or a duration that is missing
+// or ends before the last event
+type Interaction struct{}
+
+// NewInteraction creates an interaction signal
+func NewInteraction() *Interaction {
+	return &Interaction{}
+}
+
+// Name .
+func (n *Interaction) Name() string {
+	return "interaction"
+}
+
+// Evaluate .
+func (n *Interaction) Evaluate(a *risk.Attempt) (float64, bool) {
+	t := telemetry(a)
+	if t == nil {
+		return 0, false
+	}
+	if len(t.Pointer) == 0 && len(t.Keys) == 0 && len(t.Focus) == 0 {
+		return 1, true
+	}
+	// the page measures the duration and the events alike, events after it are made up
+	if t.Duration <= 0 || t.lastEvent() > t.Duration {
+		return 1, true
+	}
+
+	visible := len(t.Focus) == 0
+	for _, f := range t.Focus {
+		if f.Type == "focus" || f.Type == "visible" {
+			visible = true
+			break
+		}
+	}
+	if !visible {
+		return 1, true
+	}
+	if len(t.Pointer) == 0 && len(t.Keys) == 0 {
+		return 0.5, true
+	}
+	return 0, true
+}
+
+// mean .
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,298 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package pow issues proof-of-work challenges bound to captcha challenges, a client must find a
+// nonce whose hash has enough leading zero bits before its captcha answer is accepted, which
+// raises the cost of bots without more friction for users.
+//
+// The hash input is the captcha challenge ID, the salt and the nonce in decimal joined by ":".
+// AlgorithmSHA256 hashes it once with SHA-256. AlgorithmMemory hashes it into a seed, fills
+// Memory KiB of 32-byte blocks with V[0] = seed and V[i] = SHA-256(V[i-1]), then starting with
+// X = V[n-1] it mixes n times X = SHA-256(X xor V[j]) where j is the first 4 bytes of X as a big
+// endian integer modulo n, the result is X.
+//
+// Challenges are signed with HMAC-SHA256, the server keeps no state, a challenge is as reusable as
+// the captcha challenge it is bound to, so bind it to an ID that passes once such as a store.Challenge.
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"time"
+)
+
+// Algorithm is the hash of a challenge
+type Algorithm string
+
+const (
+	AlgorithmSHA256 Algorithm = "sha256"
+	AlgorithmMemory Algorithm = "memory"
+)
+
+const (
+	// DefaultMinDifficulty is the default difficulty in leading zero bits for a score of 0
+	DefaultMinDifficulty = 12
+	// DefaultMaxDifficulty is the default difficulty in leading zero bits for a score of 1
+	DefaultMaxDifficulty = 20
+	// DefaultMemoryMinDifficulty is the default difficulty of AlgorithmMemory for a score of 0
+	DefaultMemoryMinDifficulty = 4
+	// DefaultMemoryMaxDifficulty is the default difficulty of AlgorithmMemory for a score of 1
+	DefaultMemoryMaxDifficulty = 10
+	// DefaultMemory is the default memory of AlgorithmMemory in KiB
+	DefaultMemory = 256
+	// DefaultTTL is the default lifetime of a challenge
+	DefaultTTL = 5 * time.Minute
+)
+
+var (
+	EmptySecretErr      = errors.New("pow secret is empty")
+	SignatureErr        = errors.New("pow challenge signature is invalid")
+	ExpiredErr          = errors.New("pow challenge expired")
+	ChallengeIDErr      = errors.New("pow challenge is bound to another captcha challenge")
+	InsufficientWorkErr = errors.New("pow nonce does not meet the difficulty")
+)
+
+// Challenge is a proof-of-work challenge, it is sent to the client as JSON and sent back with the nonce
+type Challenge struct {
+	ID         string    `json:"id"`
+	Algorithm  Algorithm `json:"algorithm"`
+	Difficulty int       `json:"difficulty"`
+	Memory     int       `json:"memory,omitempty"`
+	Salt       string    `json:"salt"`
+	ExpiresAt  int64     `json:"expires_at"`
+	Signature  string    `json:"signature"`
+}
+
+// Options .
+type Options struct {
+	algorithm     Algorithm
+	minDifficulty int
+	maxDifficulty int
+	difficultySet bool
+	memory        int
+	ttl           time.Duration
+}
+
+// Option .
+type Option func(*Options)
+
+// WithAlgorithm sets the hash of the challenges
+func WithAlgorithm(val Algorithm) Option {
+	return func(opts *Options) {
+		if val == AlgorithmSHA256 || val == AlgorithmMemory {
+			opts.algorithm = val
+		}
+	}
+}
+
+// WithDifficulty sets the difficulties in leading zero bits for the risk scores 0 and 1,
+// the scores in between are interpolated, every bit doubles the mean work
+func WithDifficulty(min, max int) Option {
+	return func(opts *Options) {
+		if min < 0 || max < min || max > 64 {
+			return
+		}
+		opts.minDifficulty = min
+		opts.maxDifficulty = max
+		opts.difficultySet = true
+	}
+}
+
+// WithMemory sets the memory of AlgorithmMemory in KiB
+func WithMemory(val int) Option {
+	return func(opts *Options) {
+		if val > 0 {
+			opts.memory = val
+		}
+	}
+}
+
+// WithTTL sets the lifetime of the challenges
+func WithTTL(val time.Duration) Option {
+	return func(opts *Options) {
+		if val > 0 {
+			opts.ttl = val
+		}
+	}
+}
+
+// Issuer issues and verifies the challenges signed with its secret, it is safe for concurrent use
+type Issuer struct {
+	secret []byte
+	opts   *Options
+}
+
+// NewIssuer creates an issuer
+// params:
+//   - secret: HMAC key of the signatures, shared by the servers that verify
+//   - opts: Options
+//
+// returns:
+//   - *Issuer: Issuer instance
+//   - error: EmptySecretErr when the secret is empty
+func NewIssuer(secret []byte, opts ...Option) (*Issuer, error) {
+	if len(secret) == 0 {
+		return nil, EmptySecretErr
+	}
+
+	o := &Options{
+		algorithm:     AlgorithmSHA256,
+		minDifficulty: DefaultMinDifficulty,
+		maxDifficulty: DefaultMaxDifficulty,
+		memory:        DefaultMemory,
+		ttl:           DefaultTTL,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.algorithm == AlgorithmMemory && !o.difficultySet {
+		o.minDifficulty = DefaultMemoryMinDifficulty
+		o.maxDifficulty = DefaultMemoryMaxDifficulty
+	}
+	return &Issuer{secret: append([]byte(nil), secret...), opts: o}, nil
+}
+
+// Difficulty returns the difficulty for a risk score
+// params:
+//   - score: Risk score between 0 and 1, see risk.Result
+//
+// return: Leading zero bits
+func (i *Issuer) Difficulty(score float64) int {
+	score = math.Max(0, math.Min(1, score))
+	return i.opts.minDifficulty + int(math.Round(score*float64(i.opts.maxDifficulty-i.opts.minDifficulty)))
+}
+
+// Issue issues a challenge bound to a captcha challenge
+// params:
+//   - id: Captcha challenge ID
+//   - score: Risk score between 0 and 1 scaling the difficulty
+//
+// return: Signed challenge
+func (i *Issuer) Issue(id string, score float64) *Challenge {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		panic(err)
+	}
+
+	c := &Challenge{
+		ID:         id,
+		Algorithm:  i.opts.algorithm,
+		Difficulty: i.Difficulty(score),
+		Salt:       hex.EncodeToString(salt),
+		ExpiresAt:  time.Now().Add(i.opts.ttl).Unix(),
+	}
+	if c.Algorithm == AlgorithmMemory {
+		c.Memory = i.opts.memory
+	}
+	c.Signature = hex.EncodeToString(i.sign(c))
+	return c
+}
+
+// Verify verifies the nonce of a challenge sent back by a client
+// params:
+//   - c: Challenge sent back
+//   - id: Captcha challenge ID being verified
+//   - nonce: Nonce found by the client
+//
+// return: Error information, nil when the work is valid
+func (i *Issuer) Verify(c *Challenge, id string, nonce uint64) error {
+	sig, err := hex.DecodeString(c.Signature)
+	if err != nil || !hmac.Equal(sig, i.sign(c)) {
+		return SignatureErr
+	}
+	if c.ID != id {
+		return ChallengeIDErr
+	}
+	if time.Now().Unix() > c.ExpiresAt {
+		return ExpiredErr
+	}
+	if LeadingZeros(Hash(c, nonce)) < c.Difficulty {
+		return InsufficientWorkErr
+	}
+	return nil
+}
+
+// sign returns the HMAC of the fields of a challenge
+func (i *Issuer) sign(c *Challenge) []byte {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(c.ID + "\x00" + string(c.Algorithm) + "\x00" + c.Salt + "\x00"))
+	var buf [24]byte
+	binary.BigEndian.PutUint64(buf[0:], uint64(c.Difficulty))
+	binary.BigEndian.PutUint64(buf[8:], uint64(c.Memory))
+	binary.BigEndian.PutUint64(buf[16:], uint64(c.ExpiresAt))
+	mac.Write(buf[:])
+	return mac.Sum(nil)
+}
+
+// Solve finds a nonce meeting the difficulty of a challenge, for clients written in Go and tests
+// params:
+//   - c: Challenge
+//
+// return: Nonce
+func Solve(c *Challenge) uint64 {
+	for nonce := uint64(0); ; nonce++ {
+		if LeadingZeros(Hash(c, nonce)) >= c.Difficulty {
+			return nonce
+		}
+	}
+}
+
+// Hash returns the hash of a nonce for a challenge
+// params:
+//   - c: Challenge
+//   - nonce: Nonce
+//
+// return: 32-byte hash
+func Hash(c *Challenge, nonce uint64) []byte {
+	sum := sha256.Sum256([]byte(c.ID + ":" + c.Salt + ":" + strconv.FormatUint(nonce, 10)))
+	if c.Algorithm != AlgorithmMemory {
+		return sum[:]
+	}
+	return memoryHash(sum, c.Memory)
+}
+
+// memoryHash fills memory KiB of blocks from a seed and mixes them in a data dependent order
+func memoryHash(seed [32]byte, memory int) []byte {
+	n := memory * 1024 / sha256.Size
+	if n < 1 {
+		n = 1
+	}
+
+	v := make([][32]byte, n)
+	v[0] = seed
+	for i := 1; i < n; i++ {
+		v[i] = sha256.Sum256(v[i-1][:])
+	}
+
+	x := v[n-1]
+	for i := 0; i < n; i++ {
+		j := binary.BigEndian.Uint32(x[:4]) % uint32(n)
+		for k := range x {
+			x[k] ^= v[j][k]
+		}
+		x = sha256.Sum256(x[:])
+	}
+	return x[:]
+}
+
+// LeadingZeros returns the number of leading zero bits of a hash
+func LeadingZeros(h []byte) int {
+	n := 0
+	for _, b := range h {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
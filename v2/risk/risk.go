@@ -0,0 +1,245 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package risk scores verifications, signals such as the solve time, the precision of the
+// answer, the attempts on the challenge, the client fingerprint and the pointer trajectory
+// are weighted into a score between 0 (human) and 1 (bot) that decides whether to allow
+// the client, challenge it again or deny it.
+//
+// Every signal is a plug-in implementing Signal, the built-in ones are in this package.
+package risk
+
+import (
+	"time"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+const (
+	// DefaultChallengeThreshold is the default score from which a verification is challenged again
+	DefaultChallengeThreshold = 0.5
+	// DefaultDenyThreshold is the default score from which a verification is denied
+	DefaultDenyThreshold = 0.8
+)
+
+// Decision is the outcome of a scored verification
+type Decision int
+
+const (
+	Allow     Decision = iota // Allow the client
+	Challenge                 // Challenge the client again
+	Deny                      // Deny the client
+)
+
+// String returns the name of the decision
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case Challenge:
+		return "challenge"
+	case Deny:
+		return "deny"
+	}
+	return "unknown"
+}
+
+// Point is a point of an answer, rotate answers use X for the angle
+type Point struct {
+	X int
+	Y int
+}
+
+// Target is the expected area of an answer, rotate targets use X for the angle and no size
+type Target struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// TrackPoint is a pointer sample, T is the time in milliseconds since the start of the track
+type TrackPoint struct {
+	X int
+	Y int
+	T int64
+}
+
+// Attempt is what the server knows about a verification, signals skip the fields left empty
+type Attempt struct {
+	Kind        string
+	ChallengeID string
+	IssuedAt    time.Time
+	SubmittedAt time.Time
+	// Attempts on the challenge, this one included
+	Attempts    int
+	Fingerprint string
+	// Points answered, in the order of the Targets
+	Points     []Point
+	Targets    []Target
+	Trajectory []TrackPoint
+	// Extra carries data for custom signals, such as the telemetry of the passive package
+	Extra interface{}
+}
+
+// submitted returns the submission time, now when it is not set
+func (a *Attempt) submitted() time.Time {
+	if a.SubmittedAt.IsZero() {
+		return time.Now()
+	}
+	return a.SubmittedAt
+}
+
+// Signal is a plug-in scoring one aspect of an attempt
+type Signal interface {
+	// Name returns the name of the signal
+	Name() string
+	// Evaluate returns a score between 0 (human) and 1 (bot), ok is false when the attempt
+	// has not what the signal needs and the score is ignored
+	Evaluate(a *Attempt) (score float64, ok bool)
+}
+
+// SignalScore is the score of one signal
+type SignalScore struct {
+	Name   string
+	Weight float64
+	Score  float64
+}
+
+// Result is the outcome of a scored attempt
+type Result struct {
+	// Score is the weighted mean of the scores of the signals that applied, 0 when none did
+	Score    float64
+	Decision Decision
+	Signals  []SignalScore
+}
+
+// weighted is a signal with its weight
+type weighted struct {
+	signal Signal
+	weight float64
+}
+
+// Options .
+type Options struct {
+	signals   []weighted
+	challenge float64
+	deny      float64
+}
+
+// Option .
+type Option func(*Options)
+
+// WithSignal adds a signal with a weight, signals with a weight less than or equal to 0 are ignored
+func WithSignal(s Signal, weight float64) Option {
+	return func(opts *Options) {
+		if s != nil && weight > 0 {
+			opts.signals = append(opts.signals, weighted{signal: s, weight: weight})
+		}
+	}
+}
+
+// WithThresholds sets the scores from which a verification is challenged again and denied,
+// the deny threshold is raised to the challenge one when lower
+func WithThresholds(challenge, deny float64) Option {
+	return func(opts *Options) {
+		opts.challenge = helper.ClampUnit(challenge)
+		opts.deny = helper.ClampUnit(deny)
+		if opts.deny < opts.challenge {
+			opts.deny = opts.challenge
+		}
+	}
+}
+
+// Scorer weights the signals of an attempt into a decision, it is safe for concurrent use
+// when its signals are
+type Scorer struct {
+	opts *Options
+}
+
+// NewScorer creates a scorer, without WithSignal it uses the signals of DefaultSignals
+// params:
+//   - opts: Options
+//
+// return: Scorer instance
+func NewScorer(opts ...Option) *Scorer {
+	o := &Options{
+		challenge: DefaultChallengeThreshold,
+		deny:      DefaultDenyThreshold,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if len(o.signals) == 0 {
+		for _, opt := range DefaultSignals() {
+			opt(o)
+		}
+	}
+	return &Scorer{opts: o}
+}
+
+// DefaultSignals returns the built-in signals with their default settings and weights
+func DefaultSignals() []Option {
+	return []Option{
+		WithSignal(NewSolveTime(DefaultMinSolveTime, DefaultMaxSolveTime), 1),
+		WithSignal(NewPrecision(DefaultPerfectOffset, DefaultHumanOffset), 1),
+		WithSignal(NewAttempts(DefaultMaxAttempts), 1),
+		WithSignal(NewFingerprint(DefaultFingerprintLimit, DefaultFingerprintWindow), 1),
+		WithSignal(NewTrajectory(), 2),
+	}
+}
+
+// Score scores an attempt
+// params:
+//   - a: Attempt
+//
+// return: Result
+func (s *Scorer) Score(a *Attempt) *Result {
+	res := &Result{Signals: make([]SignalScore, 0, len(s.opts.signals))}
+
+	var sum, weights float64
+	for _, w := range s.opts.signals {
+		score, ok := w.signal.Evaluate(a)
+		if !ok {
+			continue
+		}
+		score = helper.ClampUnit(score)
+		res.Signals = append(res.Signals, SignalScore{Name: w.signal.Name(), Weight: w.weight, Score: score})
+		sum += score * w.weight
+		weights += w.weight
+	}
+	if weights > 0 {
+		res.Score = sum / weights
+	}
+
+	res.Decision = s.decide(res.Score)
+	return res
+}
+
+// Verify scores an attempt with the outcome of its validation, a failed validation is never allowed
+// params:
+//   - ok: Outcome of click.Validate, slide.Validate or rotate.Validate
+//   - a: Attempt
+//
+// return: Result
+func (s *Scorer) Verify(ok bool, a *Attempt) *Result {
+	res := s.Score(a)
+	if !ok && res.Decision == Allow {
+		res.Decision = Challenge
+	}
+	return res
+}
+
+// decide returns the decision of a score
+func (s *Scorer) decide(score float64) Decision {
+	switch {
+	case score >= s.opts.deny:
+		return Deny
+	case score >= s.opts.challenge:
+		return Challenge
+	}
+	return Allow
+}
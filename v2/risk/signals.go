@@ -0,0 +1,251 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package risk
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+const (
+	// DefaultMinSolveTime is the default solve time under which an attempt is too fast for a human
+	DefaultMinSolveTime = 800 * time.Millisecond
+	// DefaultMaxSolveTime is the default solve time over which an attempt is stale
+	DefaultMaxSolveTime = 10 * time.Minute
+	// DefaultPerfectOffset is the default mean offset from the target centers, in pixels or degrees,
+	// under which an answer is too precise for a human
+	DefaultPerfectOffset = 0.5
+	// DefaultHumanOffset is the default mean offset from which an answer is as imprecise as a human one
+	DefaultHumanOffset = 2.0
+	// DefaultMaxAttempts is the default number of attempts on a challenge that scores 1
+	DefaultMaxAttempts = 5
+	// DefaultFingerprintLimit is the default number of verifications of a fingerprint in the window that scores 1
+	DefaultFingerprintLimit = 30
+	// DefaultFingerprintWindow is the default window of the fingerprint signal
+	DefaultFingerprintWindow = 10 * time.Minute
+)
+
+var (
+	_ Signal = (*SolveTime)(nil)
+	_ Signal = (*Precision)(nil)
+	_ Signal = (*Attempts)(nil)
+	_ Signal = (*Fingerprint)(nil)
+)
+
+// SolveTime scores the time between the issue and the submission of a challenge,
+// answers faster than min score 1 falling to 0 at twice min, answers slower than max score 1
+type SolveTime struct {
+	min time.Duration
+	max time.Duration
+}
+
+// NewSolveTime creates a solve time signal
+// params:
+//   - min: Time under which an answer is too fast, values less than or equal to 0 use DefaultMinSolveTime
+//   - max: Time over which an answer is stale, values less than or equal to min use DefaultMaxSolveTime
+//
+// return: Signal
+func NewSolveTime(min, max time.Duration) *SolveTime {
+	if min <= 0 {
+		min = DefaultMinSolveTime
+	}
+	if max <= min {
+		max = DefaultMaxSolveTime
+	}
+	return &SolveTime{min: min, max: max}
+}
+
+// Name .
+func (s *SolveTime) Name() string {
+	return "solve_time"
+}
+
+// Evaluate .
+func (s *SolveTime) Evaluate(a *Attempt) (float64, bool) {
+	if a.IssuedAt.IsZero() {
+		return 0, false
+	}
+
+	d := a.submitted().Sub(a.IssuedAt)
+	switch {
+	case d < s.min:
+		return 1, true
+	case d > s.max:
+		return 1, true
+	}
+	return LinearDown(float64(d), float64(s.min), float64(2*s.min)), true
+}
+
+// Precision scores how close the answered points are to the target centers, too perfect answers
+// are scripted. The center of a target is its position plus half its size, slide targets are
+// given without size so the center is the expected position. The score is lowered for few points,
+// a single exact answer may be luck.
+type Precision struct {
+	perfect float64
+	human   float64
+}
+
+// NewPrecision creates a precision signal
+// params:
+//   - perfect: Mean offset under which an answer scores 1, values less than 0 use DefaultPerfectOffset
+//   - human: Mean offset from which an answer scores 0, values less than or equal to perfect use DefaultHumanOffset
+//
+// return: Signal
+func NewPrecision(perfect, human float64) *Precision {
+	if perfect < 0 {
+		perfect = DefaultPerfectOffset
+	}
+	if human <= perfect {
+		human = math.Max(DefaultHumanOffset, perfect+1)
+	}
+	return &Precision{perfect: perfect, human: human}
+}
+
+// Name .
+func (p *Precision) Name() string {
+	return "precision"
+}
+
+// Evaluate .
+func (p *Precision) Evaluate(a *Attempt) (float64, bool) {
+	n := len(a.Points)
+	if n == 0 || n != len(a.Targets) {
+		return 0, false
+	}
+
+	var sum float64
+	for i, pt := range a.Points {
+		t := a.Targets[i]
+		cx := float64(t.X) + float64(t.Width)/2
+		cy := float64(t.Y) + float64(t.Height)/2
+		sum += math.Hypot(float64(pt.X)-cx, float64(pt.Y)-cy)
+	}
+	mean := sum / float64(n)
+
+	score := 1.0
+	if mean > p.perfect {
+		score = LinearDown(mean, p.perfect, p.human)
+	}
+	return score * (1 - math.Pow(0.5, float64(n))), true
+}
+
+// Attempts scores the attempts on a challenge, the first scores 0 and max scores 1
+type Attempts struct {
+	max int
+}
+
+// NewAttempts creates an attempts signal
+// params:
+//   - max: Attempts that score 1, values less than 2 use DefaultMaxAttempts
+//
+// return: Signal
+func NewAttempts(max int) *Attempts {
+	if max < 2 {
+		max = DefaultMaxAttempts
+	}
+	return &Attempts{max: max}
+}
+
+// Name .
+func (t *Attempts) Name() string {
+	return "attempts"
+}
+
+// Evaluate .
+func (t *Attempts) Evaluate(a *Attempt) (float64, bool) {
+	if a.Attempts <= 0 {
+		return 0, false
+	}
+	return helper.ClampUnit(float64(a.Attempts-1) / float64(t.max-1)), true
+}
+
+// Fingerprint scores how many verifications a client fingerprint made in a sliding window,
+// limit verifications score 1 and clients sending no fingerprint score 1.
+// A Fingerprint is safe for concurrent use.
+type Fingerprint struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	seen   map[string][]time.Time
+	calls  int
+}
+
+// NewFingerprint creates a fingerprint signal
+// params:
+//   - limit: Verifications in the window that score 1, values less than or equal to 0 use DefaultFingerprintLimit
+//   - window: Window, values less than or equal to 0 use DefaultFingerprintWindow
+//
+// return: Signal
+func NewFingerprint(limit int, window time.Duration) *Fingerprint {
+	if limit <= 0 {
+		limit = DefaultFingerprintLimit
+	}
+	if window <= 0 {
+		window = DefaultFingerprintWindow
+	}
+	return &Fingerprint{limit: limit, window: window, seen: make(map[string][]time.Time)}
+}
+
+// Name .
+func (f *Fingerprint) Name() string {
+	return "fingerprint"
+}
+
+// Evaluate records the verification and scores its fingerprint
+func (f *Fingerprint) Evaluate(a *Attempt) (float64, bool) {
+	if a.Fingerprint == "" {
+		return 1, true
+	}
+	now := a.submitted()
+	since := now.Add(-f.window)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.calls%1024 == 0 {
+		f.sweep(since)
+	}
+
+	times := append(prune(f.seen[a.Fingerprint], since), now)
+	f.seen[a.Fingerprint] = times
+	return helper.ClampUnit(float64(len(times)) / float64(f.limit)), true
+}
+
+// sweep drops the fingerprints without verification since a time, f.mu must be held
+func (f *Fingerprint) sweep(since time.Time) {
+	for fp, times := range f.seen {
+		if times = prune(times, since); len(times) == 0 {
+			delete(f.seen, fp)
+		} else {
+			f.seen[fp] = times
+		}
+	}
+}
+
+// prune drops the times before since, the times are in order
+func prune(times []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(since) {
+		i++
+	}
+	return times[i:]
+}
+
+// LinearDown returns 1 at or under lo falling linearly to 0 at or over hi
+func LinearDown(v, lo, hi float64) float64 {
+	if v <= lo {
+		return 1
+	}
+	if v >= hi {
+		return 0
+	}
+	return (hi - v) / (hi - lo)
+}
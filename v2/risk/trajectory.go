@@ -0,0 +1,111 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package risk
+
+import (
+	"math"
+)
+
+// DefaultMinTrackPoints is the default number of pointer samples under which a trajectory scores 1
+const DefaultMinTrackPoints = 5
+
+var _ Signal = (*Trajectory)(nil)
+
+// Trajectory scores the pointer track of an answer, scripted tracks are too straight,
+// move at a constant speed or are sampled at perfectly regular intervals.
+// Tracks with fewer samples than the minimum or going back in time score 1.
+type Trajectory struct {
+	minPoints int
+}
+
+// NewTrajectory creates a trajectory signal with DefaultMinTrackPoints
+// return: Signal
+func NewTrajectory() *Trajectory {
+	return NewTrajectoryWithMin(DefaultMinTrackPoints)
+}
+
+// NewTrajectoryWithMin creates a trajectory signal
+// params:
+//   - minPoints: Samples under which a track scores 1, values less than 3 use 3
+//
+// return: Signal
+func NewTrajectoryWithMin(minPoints int) *Trajectory {
+	if minPoints < 3 {
+		minPoints = 3
+	}
+	return &Trajectory{minPoints: minPoints}
+}
+
+// Name .
+func (t *Trajectory) Name() string {
+	return "trajectory"
+}
+
+// Evaluate .
+func (t *Trajectory) Evaluate(a *Attempt) (float64, bool) {
+	track := a.Trajectory
+	if len(track) == 0 {
+		return 0, false
+	}
+	if len(track) < t.minPoints {
+		return 1, true
+	}
+
+	var path float64
+	speeds := make([]float64, 0, len(track)-1)
+	intervals := make([]float64, 0, len(track)-1)
+	for i := 1; i < len(track); i++ {
+		dt := track[i].T - track[i-1].T
+		if dt < 0 {
+			return 1, true
+		}
+		d := math.Hypot(float64(track[i].X-track[i-1].X), float64(track[i].Y-track[i-1].Y))
+		path += d
+		intervals = append(intervals, float64(dt))
+		if dt > 0 {
+			speeds = append(speeds, d/float64(dt))
+		}
+	}
+
+	scores := make([]float64, 0, 3)
+	first, last := track[0], track[len(track)-1]
+	if disp := math.Hypot(float64(last.X-first.X), float64(last.Y-first.Y)); disp >= 1 {
+		// a path barely longer than the displacement is a straight line
+		scores = append(scores, LinearDown(path/disp, 1.01, 1.1))
+	}
+	if len(speeds) >= 2 {
+		scores = append(scores, LinearDown(Variation(speeds), 0.1, 0.5))
+	} else {
+		// every sample at the same time
+		scores = append(scores, 1)
+	}
+	scores = append(scores, LinearDown(Variation(intervals), 0.01, 0.1))
+
+	var sum float64
+	for _, s := range scores {
+		sum += s
+	}
+	return sum / float64(len(scores)), true
+}
+
+// Variation returns the coefficient of variation of values, 0 when their mean is 0
+func Variation(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sq/float64(len(values))) / mean
+}
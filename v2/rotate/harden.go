@@ -0,0 +1,220 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package rotate
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/base/random"
+	"golang.org/x/image/draw"
+)
+
+// hardening holds the random choices shared by the master and the thumb of one challenge
+type hardening struct {
+	blur int
+	rnd  *rand.Rand
+}
+
+// hardened reports whether any hardening option is set
+func (c *captcha) hardened() bool {
+	o := c.opts
+	return o.ringGap > 0 || (o.rangeRingBlur != nil && o.rangeRingBlur.Max > 0) || o.thumbOcclusion > 0 ||
+		o.thumbScaleJitter > 0 || o.thumbHueJitter > 0 || o.backgroundNoise > 0
+}
+
+// newHardening picks the random choices of a challenge
+func (c *captcha) newHardening() *hardening {
+	h := &hardening{rnd: random.New()}
+	if r := c.opts.rangeRingBlur; r != nil && r.Max > 0 {
+		h.blur = random.RandInt(int(math.Max(float64(r.Min), 0)), r.Max)
+	}
+	return h
+}
+
+// hardenMaster clears the ring gap, blurs the ring outside the thumb and adds the background noise
+// params:
+//   - img: Master image
+//   - thumbSize: Thumb size
+//   - h: Choices of the challenge
+//
+// return: Hardened master image
+func (c *captcha) hardenMaster(img image.Image, thumbSize int, h *hardening) image.Image {
+	dst := toNRGBA(img)
+	b := dst.Bounds()
+	cx, cy := float64(b.Min.X+b.Max.X)/2, float64(b.Min.Y+b.Max.Y)/2
+	inner := float64(thumbSize)/2 + float64(c.opts.ringGap)
+
+	if h.blur > 0 {
+		band := float64(3 * h.blur)
+		blendRing(dst, blurNRGBA(dst, h.blur), cx, cy, func(d float64) float64 {
+			return 1 - (d-inner)/band
+		})
+	}
+
+	if c.opts.backgroundNoise > 0 {
+		amount := float64(c.opts.backgroundNoise) * 128
+		canvas.EachPixel(dst, func(x, y, o int) {
+			d := distance(x, y, cx, cy)
+			if d <= inner || dst.Pix[o+3] == 0 {
+				return
+			}
+			v := (h.rnd.Float64()*2 - 1) * amount
+			for k := 0; k < 3; k++ {
+				dst.Pix[o+k] = helper.ClampUint8(float64(dst.Pix[o+k]) + v)
+			}
+		})
+	}
+
+	if c.opts.ringGap > 0 {
+		canvas.EachPixel(dst, func(x, y, o int) {
+			d := distance(x, y, cx, cy)
+			if d < inner {
+				dst.Pix[o], dst.Pix[o+1], dst.Pix[o+2], dst.Pix[o+3] = 0, 0, 0, 0
+			}
+		})
+	}
+
+	return dst
+}
+
+// hardenThumb zooms, shifts the hue, blurs the rim and occludes parts of the thumb
+// params:
+//   - img: Thumb image
+//   - h: Choices of the challenge
+//
+// return: Hardened thumb image
+func (c *captcha) hardenThumb(img image.Image, h *hardening) image.Image {
+	dst := toNRGBA(img)
+	b := dst.Bounds()
+	cx, cy := float64(b.Min.X+b.Max.X)/2, float64(b.Min.Y+b.Max.Y)/2
+	radius := math.Min(float64(b.Dx()), float64(b.Dy())) / 2
+
+	if c.opts.thumbScaleJitter > 0 {
+		dst = zoomNRGBA(dst, 1+h.rnd.Float64()*float64(c.opts.thumbScaleJitter))
+	}
+
+	if c.opts.thumbHueJitter > 0 {
+		deg := (h.rnd.Float64()*2 - 1) * float64(c.opts.thumbHueJitter)
+		canvas.ShiftHue(dst, deg*math.Pi/180)
+	}
+
+	if h.blur > 0 {
+		band := float64(3 * h.blur)
+		blendRing(dst, blurNRGBA(dst, h.blur), cx, cy, func(d float64) float64 {
+			return 1 - (radius-d)/band
+		})
+	}
+
+	if c.opts.thumbOcclusion > 0 {
+		occludeRim(dst, cx, cy, radius, float64(c.opts.thumbOcclusion), h.rnd)
+	}
+
+	return dst
+}
+
+// toNRGBA returns a copy of the image as NRGBA
+func toNRGBA(img image.Image) *image.NRGBA {
+	dst := image.NewNRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	return dst
+}
+
+// distance returns the distance of the center of a pixel to a point
+func distance(x, y int, cx, cy float64) float64 {
+	return math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+}
+
+// blendRing mixes the blurred image into the image, weight maps the distance to the center
+// to the share of the blurred pixel and is clamped to 0..1
+func blendRing(dst, blurred *image.NRGBA, cx, cy float64, weight func(d float64) float64) {
+	canvas.EachPixel(dst, func(x, y, o int) {
+		d := distance(x, y, cx, cy)
+		w := helper.ClampUnit(weight(d))
+		if w == 0 || dst.Pix[o+3] == 0 {
+			return
+		}
+		for k := 0; k < 3; k++ {
+			dst.Pix[o+k] = helper.ClampUint8(float64(dst.Pix[o+k])*(1-w) + float64(blurred.Pix[o+k])*w)
+		}
+	})
+}
+
+// blurNRGBA returns a copy of the image blurred by the gaussian of the canvas filters, as wide as
+// a box blur of the radius, the colors are weighted by alpha
+func blurNRGBA(img *image.NRGBA, r int) *image.NRGBA {
+	dst := toNRGBA(img)
+	canvas.NewGaussianBlur(canvas.RadiusSigma(r)).Apply(dst, nil)
+	return dst
+}
+
+// zoomNRGBA scales the content up about the center, the size and the alpha are kept
+func zoomNRGBA(img *image.NRGBA, factor float64) *image.NRGBA {
+	b := img.Bounds()
+	w, h := float64(b.Dx())/factor, float64(b.Dy())/factor
+	x0, y0 := float64(b.Min.X)+(float64(b.Dx())-w)/2, float64(b.Min.Y)+(float64(b.Dy())-h)/2
+	src := image.Rect(int(math.Round(x0)), int(math.Round(y0)), int(math.Round(x0+w)), int(math.Round(y0+h)))
+
+	dst := image.NewNRGBA(b)
+	draw.BiLinear.Scale(dst, b, img, src, draw.Src, nil)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := dst.PixOffset(x, y)
+			dst.Pix[o+3] = img.Pix[o+3]
+		}
+	}
+	return dst
+}
+
+// occludeRim paints spots of the mean thumb color over the rim until the share of it is covered
+// params:
+//   - img: Thumb image, changed in place
+//   - cx, cy, radius: Thumb circle
+//   - share: Share of the rim to cover, from 0 to 1
+//   - rnd: Random generator
+func occludeRim(img *image.NRGBA, cx, cy, radius, share float64, rnd *rand.Rand) {
+	var mean [3]float64
+	n := 0.0
+	canvas.EachPixel(img, func(_, _, o int) {
+		if a := float64(img.Pix[o+3]) / 255; a > 0 {
+			for k := 0; k < 3; k++ {
+				mean[k] += float64(img.Pix[o+k]) * a
+			}
+			n += a
+		}
+	})
+	if n == 0 {
+		return
+	}
+	for k := range mean {
+		mean[k] /= n
+	}
+
+	spot := math.Max(3, radius/6)
+	arc := 2 * math.Asin(math.Min(spot/radius, 1))
+	count := int(math.Ceil(share * 2 * math.Pi / arc))
+	for i := 0; i < count; i++ {
+		angle := rnd.Float64() * 2 * math.Pi
+		sx := cx + (radius-spot*0.6)*math.Sin(angle)
+		sy := cy - (radius-spot*0.6)*math.Cos(angle)
+		tint := (rnd.Float64()*2 - 1) * 24
+
+		canvas.EachPixel(img, func(x, y, o int) {
+			d := distance(x, y, sx, sy)
+			w := helper.ClampUnit(spot - d)
+			if w == 0 || img.Pix[o+3] == 0 {
+				return
+			}
+			for k := 0; k < 3; k++ {
+				img.Pix[o+k] = helper.ClampUint8(float64(img.Pix[o+k])*(1-w) + (mean[k]+tint)*w)
+			}
+		})
+	}
+}
@@ -7,6 +7,12 @@
 package rotate
 
 import (
+	"math"
+
+	"github.com/wenlng/go-captcha/v2/base/bgcache"
+	"github.com/wenlng/go-captcha/v2/base/bgvary"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/helper"
 	"github.com/wenlng/go-captcha/v2/base/option"
 )
 
@@ -16,6 +22,20 @@ type Options struct {
 
 	rangeThumbImageSquareSize []int
 	thumbImageAlpha           float32
+
+	ringGap          int
+	rangeRingBlur    *option.RangeVal
+	thumbOcclusion   float32
+	thumbScaleJitter float32
+	thumbHueJitter   int
+	backgroundNoise  float32
+
+	backgroundCache     *bgcache.Cache
+	backgroundVariation *bgvary.Variation
+	backgroundTracker   *bgvary.Tracker
+
+	masterFilters []canvas.Filter
+	thumbFilters  []canvas.Filter
 }
 
 // GetImageSize .
@@ -45,6 +65,71 @@ func (o *Options) GetRangeThumbImageSquareSize() []int {
 	return o.rangeThumbImageSquareSize
 }
 
+// GetRingGap .
+func (o *Options) GetRingGap() int {
+	return o.ringGap
+}
+
+// GetRangeRingBlur .
+func (o *Options) GetRangeRingBlur() *option.RangeVal {
+	if o.rangeRingBlur == nil {
+		return &option.RangeVal{}
+	}
+	return &option.RangeVal{
+		Min: o.rangeRingBlur.Min,
+		Max: o.rangeRingBlur.Max,
+	}
+}
+
+// GetThumbOcclusion .
+func (o *Options) GetThumbOcclusion() float32 {
+	return o.thumbOcclusion
+}
+
+// GetThumbScaleJitter .
+func (o *Options) GetThumbScaleJitter() float32 {
+	return o.thumbScaleJitter
+}
+
+// GetThumbHueJitter .
+func (o *Options) GetThumbHueJitter() int {
+	return o.thumbHueJitter
+}
+
+// GetBackgroundNoise .
+func (o *Options) GetBackgroundNoise() float32 {
+	return o.backgroundNoise
+}
+
+// GetBackgroundCache .
+func (o *Options) GetBackgroundCache() *bgcache.Cache {
+	return o.backgroundCache
+}
+
+// GetBackgroundVariation .
+func (o *Options) GetBackgroundVariation() *bgvary.Variation {
+	if o.backgroundVariation == nil {
+		return nil
+	}
+	v := *o.backgroundVariation
+	return &v
+}
+
+// GetBackgroundTracker .
+func (o *Options) GetBackgroundTracker() *bgvary.Tracker {
+	return o.backgroundTracker
+}
+
+// GetMasterFilters .
+func (o *Options) GetMasterFilters() []canvas.Filter {
+	return append([]canvas.Filter(nil), o.masterFilters...)
+}
+
+// GetThumbFilters .
+func (o *Options) GetThumbFilters() []canvas.Filter {
+	return append([]canvas.Filter(nil), o.thumbFilters...)
+}
+
 type Option func(*Options)
 
 // NewOptions .
@@ -52,6 +137,25 @@ func NewOptions() *Options {
 	return &Options{}
 }
 
+// clone returns a deep copy of the options, the copy shares nothing mutable with the original
+func (o *Options) clone() *Options {
+	no := *o
+
+	if o.rangeAnglePos != nil {
+		no.rangeAnglePos = o.GetRangeAngle()
+	}
+	no.rangeThumbImageSquareSize = append([]int(nil), o.rangeThumbImageSquareSize...)
+	if o.rangeRingBlur != nil {
+		no.rangeRingBlur = o.GetRangeRingBlur()
+	}
+	no.masterFilters = o.GetMasterFilters()
+	no.thumbFilters = o.GetThumbFilters()
+
+	no.backgroundVariation = o.GetBackgroundVariation()
+
+	return &no
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Image
 //_______________________________________________________________________
@@ -75,6 +179,34 @@ func WithRangeAnglePos(vals []option.RangeVal) Option {
 	}
 }
 
+// WithBackgroundCache sets the cache of pre-scaled images, nil disables it,
+// it is skipped when a background variation is set, the variation scales the full-size source
+func WithBackgroundCache(cache *bgcache.Cache) Option {
+	return func(opts *Options) {
+		opts.backgroundCache = cache
+	}
+}
+
+// WithBackgroundVariation sets the random crop, flip, hue, brightness, contrast and texture applied to
+// the images of every captcha, see bgvary.Default, nil disables it
+func WithBackgroundVariation(val *bgvary.Variation) Option {
+	return func(opts *Options) {
+		if val != nil {
+			v := *val
+			val = &v
+		}
+		opts.backgroundVariation = val
+	}
+}
+
+// WithBackgroundTracker sets the tracker warning when few distinct images are in use, it may be
+// shared by several captchas, nil disables it
+func WithBackgroundTracker(val *bgvary.Tracker) Option {
+	return func(opts *Options) {
+		opts.backgroundTracker = val
+	}
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Thumb Image
 //_______________________________________________________________________
@@ -82,7 +214,7 @@ func WithRangeAnglePos(vals []option.RangeVal) Option {
 // WithRangeThumbImageSquareSize .
 func WithRangeThumbImageSquareSize(val []int) Option {
 	return func(opts *Options) {
-		opts.rangeThumbImageSquareSize = val
+		opts.rangeThumbImageSquareSize = append([]int(nil), val...)
 	}
 }
 
@@ -92,3 +224,76 @@ func WithThumbImageAlpha(val float32) Option {
 		opts.thumbImageAlpha = val
 	}
 }
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Hardening
+//_______________________________________________________________________
+
+// WithRingGap sets the width in pixels of the empty ring between the thumb and the master image,
+// the master image is cleared under the thumb and the ring
+func WithRingGap(val int) Option {
+	return func(opts *Options) {
+		if val < 0 {
+			val = 0
+		}
+		opts.ringGap = val
+	}
+}
+
+// WithRangeRingBlur sets the range of the random blur radius applied on both sides of the thumb edge
+func WithRangeRingBlur(val option.RangeVal) Option {
+	return func(opts *Options) {
+		opts.rangeRingBlur = &option.RangeVal{Min: val.Min, Max: val.Max}
+	}
+}
+
+// WithThumbOcclusion sets the share of the thumb rim covered by occluding spots, from 0 to 1
+func WithThumbOcclusion(val float32) Option {
+	return func(opts *Options) {
+		opts.thumbOcclusion = float32(helper.ClampUnit(float64(val)))
+	}
+}
+
+// WithThumbScaleJitter sets the largest random zoom of the thumb content, 0.05 zooms in by up to 5%
+func WithThumbScaleJitter(val float32) Option {
+	return func(opts *Options) {
+		opts.thumbScaleJitter = float32(math.Max(0, math.Min(float64(val), 0.5)))
+	}
+}
+
+// WithThumbHueJitter sets the largest random hue shift of the thumb in degrees
+func WithThumbHueJitter(val int) Option {
+	return func(opts *Options) {
+		if val < 0 {
+			val = 0
+		}
+		opts.thumbHueJitter = val
+	}
+}
+
+// WithBackgroundNoise sets the strength of the noise added to the master image outside the thumb, from 0 to 1
+func WithBackgroundNoise(val float32) Option {
+	return func(opts *Options) {
+		opts.backgroundNoise = float32(helper.ClampUnit(float64(val)))
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Filters
+//_______________________________________________________________________
+
+// WithMasterFilters sets the filters applied in order to the master image, the thumbnail is cut before
+// they run, give it the same chain with WithThumbFilters to keep them alike
+// Geometric filters such as canvas.NewSwirl move the answer, Generate returns canvas.GeometricFilterErr
+func WithMasterFilters(vals ...canvas.Filter) Option {
+	return func(opts *Options) {
+		opts.masterFilters = append([]canvas.Filter(nil), vals...)
+	}
+}
+
+// WithThumbFilters sets the filters applied in order to the thumbnail image
+func WithThumbFilters(vals ...canvas.Filter) Option {
+	return func(opts *Options) {
+		opts.thumbFilters = append([]canvas.Filter(nil), vals...)
+	}
+}
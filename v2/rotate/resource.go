@@ -8,11 +8,14 @@ package rotate
 
 import (
 	"image"
+
+	"github.com/wenlng/go-captcha/v2/base/bggen"
 )
 
 // Resources defines the resources for the rotate CAPTCHA
 type Resources struct {
-	rangImages []image.Image
+	rangImages      []image.Image
+	imageGenerators []bggen.Generator
 }
 
 // NewResources .
@@ -20,11 +23,27 @@ func NewResources() *Resources {
 	return &Resources{}
 }
 
+// clone returns a copy of the resources, the slice is copied while the images it holds are shared
+func (r *Resources) clone() *Resources {
+	return &Resources{
+		rangImages:      append([]image.Image(nil), r.rangImages...),
+		imageGenerators: append([]bggen.Generator(nil), r.imageGenerators...),
+	}
+}
+
 type Resource func(*Resources)
 
 // WithImages is to set image
 func WithImages(images []image.Image) Resource {
 	return func(resources *Resources) {
-		resources.rangImages = images
+		resources.rangImages = append([]image.Image(nil), images...)
+	}
+}
+
+// WithImageGenerators is to set generators drawing a new image for every captcha,
+// they are selected randomly together with WithImages, see bggen.Defaults
+func WithImageGenerators(generators []bggen.Generator) Resource {
+	return func(resources *Resources) {
+		resources.imageGenerators = append([]bggen.Generator(nil), generators...)
 	}
 }
@@ -8,21 +8,33 @@ package rotate
 
 import (
 	"errors"
+	"fmt"
 	"image"
 
+	"github.com/wenlng/go-captcha/v2/base/canvas"
 	"github.com/wenlng/go-captcha/v2/base/helper"
 	"github.com/wenlng/go-captcha/v2/base/imagedata"
 	"github.com/wenlng/go-captcha/v2/base/logger"
-	"github.com/wenlng/go-captcha/v2/base/randgen"
+	"github.com/wenlng/go-captcha/v2/base/observe"
 	"github.com/wenlng/go-captcha/v2/base/random"
 )
 
 // Captcha defines the interface for rotate CAPTCHA
+//
+// A Captcha is safe for concurrent use by multiple goroutines. Its options and
+// resources are copied when it is made and never change afterwards, the images and
+// fonts they hold are shared and must be left untouched, every call of Generate works
+// on its own blocks and canvases. To reconfigure at runtime,
+// call With or WithResources, they return a new instance and leave the receiver untouched.
 type Captcha interface {
 	setOptions(opts ...Option)
 	setResources(resources ...Resource)
+	setLogger(l logger.Logger)
+	setObserver(o observe.Observer)
 	GetOptions() *Options
 	Generate() (CaptchaData, error)
+	With(opts ...Option) Captcha
+	WithResources(resources ...Resource) Captcha
 }
 
 var _ Captcha = (*captcha)(nil)
@@ -36,6 +48,7 @@ var (
 type captcha struct {
 	version   string
 	logger    logger.Logger
+	observer  observe.Observer
 	drawImage DrawImage
 	opts      *Options
 	resources *Resources
@@ -48,7 +61,6 @@ type captcha struct {
 // return: Captcha interface instance
 func newRotate(opts ...Option) Captcha {
 	capt := &captcha{
-		logger:    logger.New(),
 		drawImage: NewDrawImage(),
 		opts:      NewOptions(),
 		resources: NewResources(),
@@ -69,6 +81,7 @@ func (c *captcha) setOptions(opts ...Option) {
 	for _, opt := range opts {
 		opt(c.opts)
 	}
+	c.preloadImages()
 }
 
 // setResources sets the CAPTCHA resources
@@ -78,6 +91,77 @@ func (c *captcha) setResources(resources ...Resource) {
 	for _, resource := range resources {
 		resource(c.resources)
 	}
+	c.preloadImages()
+}
+
+// setLogger sets the logger of the captcha, nil uses the default logger
+func (c *captcha) setLogger(l logger.Logger) {
+	c.logger = l
+}
+
+// setObserver sets the observer of the captcha, nil uses the default observer
+func (c *captcha) setObserver(o observe.Observer) {
+	c.observer = o
+}
+
+// log returns the logger of the captcha with its kind as fields
+func (c *captcha) log() logger.Logger {
+	l := c.logger
+	if l == nil {
+		l = logger.Default()
+	}
+	return logger.With(l, logger.F("kind", "rotate"))
+}
+
+// preloadImages prepares the background cache for the configured image size, the cache is
+// left out with a background variation
+func (c *captcha) preloadImages() {
+	if c.opts.backgroundCache == nil || c.opts.backgroundVariation != nil || c.opts.imageSquareSize <= 0 {
+		return
+	}
+
+	c.opts.backgroundCache.Preload(c.resources.rangImages, c.opts.imageSquareSize, c.opts.imageSquareSize)
+}
+
+// randImage randomly selects an image or generator, images get the background variation when it is set,
+// otherwise they are served from the background cache when it is set
+// params:
+//   - size: Image square size
+//   - rec: Recorder of the generation
+//
+// return: Image
+func (c *captcha) randImage(size int, rec *observe.Recorder) image.Image {
+	images, generators := c.resources.rangImages, c.resources.imageGenerators
+	i := random.RandInt(0, len(images)+len(generators)-1)
+	if i >= len(images) && len(generators) > 0 {
+		rec.Resource("image", fmt.Sprintf("generator#%d", i-len(images)))
+		img := generators[i-len(images)].Generate(size, size, random.New())
+		if c.opts.backgroundTracker != nil {
+			c.opts.backgroundTracker.Observe(img, c.log())
+		}
+		return img
+	}
+
+	if i < 0 || i >= len(images) {
+		return nil
+	}
+	rec.ResourceIndex("image", i)
+	img := images[i]
+	if img == nil {
+		return nil
+	}
+	if c.opts.backgroundTracker != nil {
+		c.opts.backgroundTracker.Observe(img, c.log())
+	}
+
+	// the variation crops the full-size source, the few candidates of the cache would leave it little to vary
+	if c.opts.backgroundVariation != nil {
+		return c.opts.backgroundVariation.Apply(img, size, size, random.New())
+	}
+	if c.opts.backgroundCache != nil {
+		img = c.opts.backgroundCache.Get(img, size, size)
+	}
+	return img
 }
 
 // GetOptions gets the CAPTCHA options
@@ -86,11 +170,60 @@ func (c *captcha) GetOptions() *Options {
 	return c.opts
 }
 
+// With creates a new CAPTCHA from a copy of the current options with opts applied
+// params:
+//   - opts: Options to apply on the copy
+//
+// return: New Captcha instance, the receiver is not modified
+func (c *captcha) With(opts ...Option) Captcha {
+	capt := c.clone()
+	capt.setOptions(opts...)
+	return capt
+}
+
+// WithResources creates a new CAPTCHA from a copy of the current resources with resources applied
+// params:
+//   - resources: Resources to apply on the copy
+//
+// return: New Captcha instance, the receiver is not modified
+func (c *captcha) WithResources(resources ...Resource) Captcha {
+	capt := c.clone()
+	capt.setResources(resources...)
+	return capt
+}
+
+// clone copies the CAPTCHA with its own options and resources
+// return: Copied captcha
+func (c *captcha) clone() *captcha {
+	return &captcha{
+		version:   c.version,
+		logger:    c.logger,
+		observer:  c.observer,
+		drawImage: c.drawImage,
+		opts:      c.opts.clone(),
+		resources: c.resources.clone(),
+	}
+}
+
 // Generate generates rotate CAPTCHA data
 // returns:
 //   - CaptchaData: Generated CAPTCHA data
 //   - error: Error information
 func (c *captcha) Generate() (CaptchaData, error) {
+	rec := observe.Start(c.observer, "rotate", "")
+	data, err := c.generate(rec)
+	rec.End(err)
+	return data, err
+}
+
+// generate generates rotate CAPTCHA data
+// params:
+//   - rec: Recorder of the generation
+//
+// returns:
+//   - CaptchaData: Generated CAPTCHA data
+//   - error: Error information
+func (c *captcha) generate(rec *observe.Recorder) (CaptchaData, error) {
 	if err := c.check(); err != nil {
 		return nil, err
 	}
@@ -99,21 +232,37 @@ func (c *captcha) Generate() (CaptchaData, error) {
 	block := c.genBlock(c.opts.imageSquareSize, thumbImageSquareSize)
 	var masterImage, tileImage image.Image
 	var err error
+	rec.Mark(observe.StageLayout)
 
-	masterImage, err = c.genMasterImage(c.opts.imageSquareSize, block)
+	masterImage, err = c.genMasterImage(c.opts.imageSquareSize, block, rec)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageMaster)
 
 	tileImage, err = c.genThumbImage(masterImage, block, thumbImageSquareSize)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageThumb)
+
+	if c.hardened() {
+		h := c.newHardening()
+		masterImage = c.hardenMaster(masterImage, thumbImageSquareSize, h)
+		rec.Mark(observe.StageMaster)
+		tileImage = c.hardenThumb(tileImage, h)
+		rec.Mark(observe.StageThumb)
+	}
+
+	masterImage = canvas.ApplyFilters(masterImage, c.opts.masterFilters)
+	rec.Mark(observe.StageMaster)
+	tileImage = canvas.ApplyFilters(tileImage, c.opts.thumbFilters)
+	rec.Mark(observe.StageThumb)
 
 	return &CaptData{
 		block:       block,
-		masterImage: imagedata.NewPNGImageData(masterImage),
-		thumbImage:  imagedata.NewPNGImageData(tileImage),
+		masterImage: imagedata.NewPNGImageDataWithHook(masterImage, rec.EncodeHook("master")),
+		thumbImage:  imagedata.NewPNGImageDataWithHook(tileImage, rec.EncodeHook("thumb")),
 	}, nil
 }
 
@@ -121,15 +270,16 @@ func (c *captcha) Generate() (CaptchaData, error) {
 // params:
 //   - size: Image size
 //   - block: Block data
+//   - rec: Recorder of the generation
 //
 // returns:
 //   - image.Image: Generated master image
 //   - error: Error information
-func (c *captcha) genMasterImage(size int, block *Block) (image.Image, error) {
+func (c *captcha) genMasterImage(size int, block *Block, rec *observe.Recorder) (image.Image, error) {
 	return c.drawImage.DrawWithNRGBA(&DrawImageParams{
 		Rotate:     block.Angle,
 		SquareSize: size,
-		Background: randgen.RandImage(c.resources.rangImages),
+		Background: c.randImage(size, rec),
 	})
 }
 
@@ -205,7 +355,7 @@ func (c *captcha) genBlock(imageSize int, thumbImageSquareSize int) *Block {
 // check checks the CAPTCHA parameters
 // return: Error information
 func (c *captcha) check() error {
-	if len(c.resources.rangImages) == 0 {
+	if len(c.resources.rangImages) == 0 && len(c.resources.imageGenerators) == 0 {
 		return EmptyImageErr
 	}
 	for _, img := range c.resources.rangImages {
@@ -213,5 +363,8 @@ func (c *captcha) check() error {
 			return ImageTypeErr
 		}
 	}
+	if canvas.HasGeometric(c.opts.masterFilters) {
+		return canvas.GeometricFilterErr
+	}
 	return nil
 }
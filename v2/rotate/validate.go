@@ -7,6 +7,7 @@
 package rotate
 
 // Validate checks if the rotation angle is within the specified range
+// It leaves the report to the caller, report the outcome of the whole verification with observe.Verified("rotate", ok)
 // params:
 //   - angle: Current angle
 //   - dAngle: Target angle
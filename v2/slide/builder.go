@@ -6,10 +6,18 @@
 
 package slide
 
+import (
+	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/base/observe"
+)
+
 // Builder defines the interface for building slide CAPTCHAs
+// A Builder is not safe for concurrent use, the Captcha instances it makes are
 type Builder interface {
 	SetOptions(opts ...Option)
 	SetResources(resources ...Resource)
+	SetLogger(l logger.Logger)
+	SetObserver(o observe.Observer)
 	Clear()
 	Make() Captcha
 	MakeDragDrop() Captcha
@@ -23,6 +31,8 @@ var _ Builder = (*builder)(nil)
 type builder struct {
 	opts      []Option
 	resources []Resource
+	logger    logger.Logger
+	observer  observe.Observer
 }
 
 // NewBuilder creates a new Builder instance
@@ -67,10 +77,22 @@ func (b *builder) SetResources(resources ...Resource) {
 	}
 }
 
+// SetLogger sets the logger of the captchas made afterwards, nil uses the default logger, see logger.SetDefault
+func (b *builder) SetLogger(l logger.Logger) {
+	b.logger = l
+}
+
+// SetObserver sets the observer of the captchas made afterwards, nil uses the default observer, see observe.SetDefault
+func (b *builder) SetObserver(o observe.Observer) {
+	b.observer = o
+}
+
 // Make generates a slide CAPTCHA in basic mode
 // params: Captcha interface instance
 func (b *builder) Make() Captcha {
 	capt := newWithMode(ModeBasic)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
 	capt.setOptions(b.opts...)
 	capt.setResources(b.resources...)
 	return capt
@@ -80,6 +102,8 @@ func (b *builder) Make() Captcha {
 // return: Captcha interface instance
 func (b *builder) MakeWithRegion() Captcha {
 	capt := newWithMode(ModeDrag)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
 	capt.setOptions(b.opts...)
 	capt.setResources(b.resources...)
 	return capt
@@ -89,6 +113,8 @@ func (b *builder) MakeWithRegion() Captcha {
 // return: Captcha interface instance
 func (b *builder) MakeDragDrop() Captcha {
 	capt := newWithMode(ModeDrag)
+	capt.setLogger(b.logger)
+	capt.setObserver(b.observer)
 	capt.setOptions(b.opts...)
 	capt.setResources(b.resources...)
 	return capt
@@ -0,0 +1,228 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+package slide
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/helper"
+)
+
+// alphaPlane returns the alpha of every pixel of the image, from 0 to 1
+func alphaPlane(img *image.NRGBA) []float64 {
+	b := img.Bounds()
+	out := make([]float64, b.Dx()*b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			out[y*b.Dx()+x] = float64(img.Pix[img.PixOffset(b.Min.X+x, b.Min.Y+y)+3]) / 255
+		}
+	}
+	return out
+}
+
+// shapePlane returns the alpha of the image scaled so its most opaque pixel is 1,
+// a translucent shadow then covers its shape fully
+func shapePlane(img *image.NRGBA) []float64 {
+	p := alphaPlane(img)
+	peak := 0.0
+	for _, v := range p {
+		peak = math.Max(peak, v)
+	}
+	if peak > 0 {
+		for i := range p {
+			p[i] /= peak
+		}
+	}
+	return p
+}
+
+// blurPlane returns the plane blurred by the gaussian of the canvas filters, as wide as a box
+// blur of the radius, outside is 0
+func blurPlane(p []float64, w, h, r int) []float64 {
+	// pad by the reach of the kernel so the values past the borders are 0 rather than repeated
+	sigma := canvas.RadiusSigma(r)
+	pad := int(math.Ceil(sigma * 3))
+	pw, ph := w+2*pad, h+2*pad
+	padded := make([]float64, pw*ph)
+	for y := 0; y < h; y++ {
+		copy(padded[(y+pad)*pw+pad:], p[y*w:(y+1)*w])
+	}
+	canvas.BlurPlane(padded, pw, ph, 1, sigma)
+
+	out := make([]float64, len(p))
+	for y := 0; y < h; y++ {
+		copy(out[y*w:(y+1)*w], padded[(y+pad)*pw+pad:])
+	}
+	return out
+}
+
+// featherAlpha blurs the alpha of the image so its edges fade out
+// params:
+//   - img: Image, changed in place
+//   - radius: Blur radius
+func featherAlpha(img *image.NRGBA, radius int) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	blurred := blurPlane(alphaPlane(img), w, h, radius)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Pix[img.PixOffset(b.Min.X+x, b.Min.Y+y)+3] = uint8(math.Round(blurred[y*w+x] * 255))
+		}
+	}
+}
+
+// flattenGap replaces the inside of a gap with the mean color around it
+// params:
+//   - dst: Master image, changed in place
+//   - pt: Position of the gap
+//   - shape: Gap shape, its alpha is used
+func flattenGap(dst *image.NRGBA, pt image.Point, shape *image.NRGBA) {
+	b := shape.Bounds()
+	w, h := b.Dx(), b.Dy()
+	alpha := shapePlane(shape)
+	near := blurPlane(alpha, w, h, 3)
+
+	var sum [3]float64
+	n := 0.0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			if alpha[i] > 0.05 || near[i] < 0.01 || !image.Pt(pt.X+x, pt.Y+y).In(dst.Bounds()) {
+				continue
+			}
+			o := dst.PixOffset(pt.X+x, pt.Y+y)
+			for c := 0; c < 3; c++ {
+				sum[c] += float64(dst.Pix[o+c])
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		return
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a := alpha[y*w+x]
+			if a == 0 || !image.Pt(pt.X+x, pt.Y+y).In(dst.Bounds()) {
+				continue
+			}
+			o := dst.PixOffset(pt.X+x, pt.Y+y)
+			for c := 0; c < 3; c++ {
+				dst.Pix[o+c] = uint8(math.Round(float64(dst.Pix[o+c])*(1-a) + sum[c]/n*a))
+			}
+		}
+	}
+}
+
+// addGapNoise adds gray noise to the band along the edge of a gap
+// params:
+//   - dst: Master image, changed in place
+//   - pt: Position of the gap
+//   - shape: Gap shape, its alpha is used
+//   - amount: Noise strength, from 0 to 1
+//   - band: Half width of the band in pixels
+//   - rnd: Random generator
+func addGapNoise(dst *image.NRGBA, pt image.Point, shape *image.NRGBA, amount float64, band int, rnd *rand.Rand) {
+	b := shape.Bounds()
+	w, h := b.Dx(), b.Dy()
+	edge := blurPlane(shapePlane(shape), w, h, band)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			e := edge[y*w+x]
+			if e < 0.02 || e > 0.98 || !image.Pt(pt.X+x, pt.Y+y).In(dst.Bounds()) {
+				continue
+			}
+			// Strongest on the edge itself, fading towards both sides of the band
+			strength := 1 - math.Abs(e-0.5)*2
+			v := (rnd.Float64()*2 - 1) * amount * 128 * strength
+			o := dst.PixOffset(pt.X+x, pt.Y+y)
+			for c := 0; c < 3; c++ {
+				dst.Pix[o+c] = helper.ClampUint8(float64(dst.Pix[o+c]) + v)
+			}
+		}
+	}
+}
+
+// jitterColors shifts the colors of the visible pixels by a random tint and per pixel noise
+// params:
+//   - img: Image, changed in place
+//   - amount: Jitter strength, from 0 to 1
+//   - rnd: Random generator
+func jitterColors(img *image.NRGBA, amount float64, rnd *rand.Rand) {
+	var tint [3]float64
+	for c := range tint {
+		tint[c] = (rnd.Float64()*2 - 1) * amount * 64
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := img.PixOffset(x, y)
+			if img.Pix[o+3] == 0 {
+				continue
+			}
+			v := (rnd.Float64()*2 - 1) * amount * 96
+			for c := 0; c < 3; c++ {
+				img.Pix[o+c] = helper.ClampUint8(float64(img.Pix[o+c]) + tint[c] + v)
+			}
+		}
+	}
+}
+
+// matchLighting scales the colors of the overlay so its brightness matches the background under it
+// params:
+//   - overlay: Overlay image, changed in place
+//   - bg: Background under the tile, the same size as the overlay
+func matchLighting(overlay *image.NRGBA, bg *image.NRGBA) {
+	overlayLuma, overlayN := 0.0, 0.0
+	bgLuma, bgN := 0.0, 0.0
+
+	b := overlay.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := overlay.PixOffset(x, y)
+			if a := float64(overlay.Pix[o+3]) / 255; a > 0 {
+				overlayLuma += luma(overlay.Pix[o:o+3]) * a
+				overlayN += a
+			}
+			if !image.Pt(x, y).In(bg.Bounds()) {
+				continue
+			}
+			g := bg.PixOffset(x, y)
+			if bg.Pix[g+3] > 0 {
+				bgLuma += luma(bg.Pix[g : g+3])
+				bgN++
+			}
+		}
+	}
+	if overlayN == 0 || bgN == 0 {
+		return
+	}
+
+	gain := (bgLuma/bgN + 8) / (overlayLuma/overlayN + 8)
+	gain = math.Max(0.25, math.Min(gain, 2))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			o := overlay.PixOffset(x, y)
+			if overlay.Pix[o+3] == 0 {
+				continue
+			}
+			for c := 0; c < 3; c++ {
+				overlay.Pix[o+c] = helper.ClampUint8(float64(overlay.Pix[o+c]) * gain)
+			}
+		}
+	}
+}
+
+func luma(rgb []uint8) float64 {
+	return 0.299*float64(rgb[0]) + 0.587*float64(rgb[1]) + 0.114*float64(rgb[2])
+}
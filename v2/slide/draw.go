@@ -11,6 +11,7 @@ import (
 
 	"github.com/wenlng/go-captcha/v2/base/canvas"
 	"github.com/wenlng/go-captcha/v2/base/randgen"
+	"github.com/wenlng/go-captcha/v2/base/random"
 	"golang.org/x/image/draw"
 )
 
@@ -21,6 +22,10 @@ type DrawImageParams struct {
 	Background        image.Image
 	Alpha             float32
 	CaptchaDrawBlocks []*DrawBlock
+	// Camouflage of the gaps
+	GapFeather  int
+	GapNoise    float32
+	FlattenGaps bool
 }
 
 // DrawTplImageParams defines the parameters for drawing the template image (tile)
@@ -33,6 +38,9 @@ type DrawTplImageParams struct {
 	MaskImage        image.Image
 	Alpha            float32
 	CaptchaDrawBlock *DrawBlock
+	// Camouflage of the overlay
+	EdgeJitter float32
+	Lighting   bool
 }
 
 // DrawImage defines the interface for drawing images
@@ -79,6 +87,12 @@ func (d *drawImage) DrawWithTemplate(params *DrawTplImageParams) (image.Image, e
 	if err != nil {
 		return nil, err
 	}
+	if params.Lighting {
+		matchLighting(maskImage.Get(), bgCvs.Get())
+	}
+	if params.EdgeJitter > 0 {
+		jitterColors(maskImage.Get(), float64(params.EdgeJitter), random.New())
+	}
 	draw.Draw(cvs.Get(), maskImage.Bounds(), maskImage, image.Point{}, draw.Over)
 
 	return cvs, nil
@@ -95,6 +109,7 @@ func (d *drawImage) DrawWithTemplate(params *DrawTplImageParams) (image.Image, e
 func (d *drawImage) DrawWithNRGBA(params *DrawImageParams) (img image.Image, bgImg image.Image, err error) {
 	blocks := params.CaptchaDrawBlocks
 	cvs := canvas.CreateNRGBACanvas(params.Width, params.Height, true)
+	shapes := make([]*image.NRGBA, len(blocks))
 
 	for i := 0; i < len(blocks); i++ {
 		block := blocks[i]
@@ -104,6 +119,14 @@ func (d *drawImage) DrawWithNRGBA(params *DrawImageParams) (img image.Image, bgI
 			return nil, nil, err
 		}
 
+		if params.FlattenGaps || params.GapNoise > 0 {
+			shapes[i] = image.NewNRGBA(graphImage.Bounds())
+			draw.Draw(shapes[i], shapes[i].Bounds(), graphImage.Get(), image.Point{}, draw.Src)
+		}
+		if params.GapFeather > 0 {
+			featherAlpha(graphImage.Get(), params.GapFeather)
+		}
+
 		graphBounds := graphImage.Bounds()
 		draw.Draw(cvs.Get(), image.Rect(block.X, block.Y, block.X+graphBounds.Dx(), block.Y+graphBounds.Dy()), graphImage.Get(), image.Point{}, draw.Over)
 	}
@@ -118,7 +141,22 @@ func (d *drawImage) DrawWithNRGBA(params *DrawImageParams) (img image.Image, bgI
 		m.SubImage(image.Rect(0, 0, params.Width, params.Height))
 
 		draw.Draw(rcm.Get(), rcm.Bounds(), m.Get(), image.Point{}, draw.Over)
+
+		if params.FlattenGaps {
+			for i, block := range blocks {
+				flattenGap(m.Get(), image.Pt(block.X, block.Y), shapes[i])
+			}
+		}
+
 		draw.Draw(m.Get(), cvs.Bounds(), cvs, image.Point{}, draw.Over)
+
+		if params.GapNoise > 0 {
+			rnd := random.New()
+			band := params.GapFeather + 3
+			for i, block := range blocks {
+				addGapNoise(m.Get(), image.Pt(block.X, block.Y), shapes[i], float64(params.GapNoise), band, rnd)
+			}
+		}
 		return m.Get(), rcm, nil
 	}
 
@@ -7,6 +7,10 @@
 package slide
 
 import (
+	"github.com/wenlng/go-captcha/v2/base/bgcache"
+	"github.com/wenlng/go-captcha/v2/base/bgvary"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/helper"
 	"github.com/wenlng/go-captcha/v2/base/option"
 )
 
@@ -29,6 +33,20 @@ type Options struct {
 	rangeGraphAnglePos        []*option.RangeVal
 	genGraphNumber            int
 	enableGraphVerticalRandom bool
+
+	decoyNumber             int
+	gapFeather              int
+	gapNoise                float32
+	tileEdgeJitter          float32
+	tileLighting            bool
+	indistinguishableDecoys bool
+
+	backgroundCache     *bgcache.Cache
+	backgroundVariation *bgvary.Variation
+	backgroundTracker   *bgvary.Tracker
+
+	masterFilters []canvas.Filter
+	tileFilters   []canvas.Filter
 }
 
 // GetImageSize .
@@ -64,11 +82,80 @@ func (o *Options) GetRangeGraphSize() *option.RangeVal {
 	}
 }
 
+// GetGenGraphNumber .
+func (o *Options) GetGenGraphNumber() int {
+	return o.genGraphNumber
+}
+
+// GetEnableGraphVerticalRandom .
+func (o *Options) GetEnableGraphVerticalRandom() bool {
+	return o.enableGraphVerticalRandom
+}
+
 // GetRangeDeadZoneDirections .
 func (o *Options) GetRangeDeadZoneDirections() []DeadZoneDirectionType {
 	return o.rangeDeadZoneDirections
 }
 
+// GetDecoyNumber .
+func (o *Options) GetDecoyNumber() int {
+	return o.decoyNumber
+}
+
+// GetGapFeather .
+func (o *Options) GetGapFeather() int {
+	return o.gapFeather
+}
+
+// GetGapNoise .
+func (o *Options) GetGapNoise() float32 {
+	return o.gapNoise
+}
+
+// GetTileEdgeJitter .
+func (o *Options) GetTileEdgeJitter() float32 {
+	return o.tileEdgeJitter
+}
+
+// GetTileLighting .
+func (o *Options) GetTileLighting() bool {
+	return o.tileLighting
+}
+
+// GetIndistinguishableDecoys .
+func (o *Options) GetIndistinguishableDecoys() bool {
+	return o.indistinguishableDecoys
+}
+
+// GetBackgroundCache .
+func (o *Options) GetBackgroundCache() *bgcache.Cache {
+	return o.backgroundCache
+}
+
+// GetBackgroundVariation .
+func (o *Options) GetBackgroundVariation() *bgvary.Variation {
+	if o.backgroundVariation == nil {
+		return nil
+	}
+	v := *o.backgroundVariation
+	return &v
+}
+
+// GetBackgroundTracker .
+func (o *Options) GetBackgroundTracker() *bgvary.Tracker {
+	return o.backgroundTracker
+}
+
+// GetMasterFilters .
+func (o *Options) GetMasterFilters() []canvas.Filter {
+	return append([]canvas.Filter(nil), o.masterFilters...)
+}
+
+// GetTileFilters .
+func (o *Options) GetTileFilters() []canvas.Filter {
+	return append([]canvas.Filter(nil), o.tileFilters...)
+}
+
 type Option func(*Options)
 
 // NewOptions .
@@ -76,6 +163,28 @@ func NewOptions() *Options {
 	return &Options{}
 }
 
+// clone returns a deep copy of the options, the copy shares nothing mutable with the original
+func (o *Options) clone() *Options {
+	no := *o
+
+	if o.imageSize != nil {
+		no.imageSize = o.GetImageSize()
+	}
+	if o.rangeGraphSize != nil {
+		no.rangeGraphSize = o.GetRangeGraphSize()
+	}
+	if o.rangeGraphAnglePos != nil {
+		no.rangeGraphAnglePos = o.GetRangeGraphAnglePos()
+	}
+	no.rangeDeadZoneDirections = append([]DeadZoneDirectionType(nil), o.rangeDeadZoneDirections...)
+	no.masterFilters = o.GetMasterFilters()
+	no.tileFilters = o.GetTileFilters()
+
+	no.backgroundVariation = o.GetBackgroundVariation()
+
+	return &no
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Image
 //_______________________________________________________________________
@@ -94,6 +203,34 @@ func WithImageAlpha(val float32) Option {
 	}
 }
 
+// WithBackgroundCache sets the cache of pre-scaled backgrounds, nil disables it,
+// it is skipped when a background variation is set, the variation scales the full-size source
+func WithBackgroundCache(cache *bgcache.Cache) Option {
+	return func(opts *Options) {
+		opts.backgroundCache = cache
+	}
+}
+
+// WithBackgroundVariation sets the random crop, flip, hue, brightness, contrast and texture applied to
+// the backgrounds of every captcha, see bgvary.Default, nil disables it
+func WithBackgroundVariation(val *bgvary.Variation) Option {
+	return func(opts *Options) {
+		if val != nil {
+			v := *val
+			val = &v
+		}
+		opts.backgroundVariation = val
+	}
+}
+
+// WithBackgroundTracker sets the tracker warning when few distinct backgrounds are in use, it may be
+// shared by several captchas, nil disables it
+func WithBackgroundTracker(val *bgvary.Tracker) Option {
+	return func(opts *Options) {
+		opts.backgroundTracker = val
+	}
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Graph Image
 //_______________________________________________________________________
@@ -137,6 +274,81 @@ func WithEnableGraphVerticalRandom(val bool) Option {
 // WithRangeDeadZoneDirections .
 func WithRangeDeadZoneDirections(val []DeadZoneDirectionType) Option {
 	return func(opts *Options) {
-		opts.rangeDeadZoneDirections = val
+		opts.rangeDeadZoneDirections = append([]DeadZoneDirectionType(nil), val...)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Camouflage
+//_______________________________________________________________________
+
+// WithDecoyNumber sets the number of decoy gaps drawn besides the graphs, they use the
+// shadow of the real gap with a slightly different size, on the row of the tile in basic mode
+func WithDecoyNumber(val int) Option {
+	return func(opts *Options) {
+		if val < 0 {
+			val = 0
+		}
+		opts.decoyNumber = val
+	}
+}
+
+// WithGapFeather sets the radius in pixels the edges of every gap shadow are blurred by, 0 keeps them sharp
+func WithGapFeather(val int) Option {
+	return func(opts *Options) {
+		if val < 0 {
+			val = 0
+		}
+		opts.gapFeather = val
+	}
+}
+
+// WithGapNoise sets the strength of the noise added around the edges of every gap, from 0 to 1
+func WithGapNoise(val float32) Option {
+	return func(opts *Options) {
+		opts.gapNoise = float32(helper.ClampUnit(float64(val)))
+	}
+}
+
+// WithTileEdgeJitter sets the strength of the color jitter on the overlay of the tile, from 0 to 1
+func WithTileEdgeJitter(val float32) Option {
+	return func(opts *Options) {
+		opts.tileEdgeJitter = float32(helper.ClampUnit(float64(val)))
+	}
+}
+
+// WithTileLighting matches the brightness of the tile overlay to the background under the tile
+func WithTileLighting(val bool) Option {
+	return func(opts *Options) {
+		opts.tileLighting = val
+	}
+}
+
+// WithIndistinguishableDecoys makes every gap look the same: the decoys keep the size of the
+// real gap and the inside of every gap is flattened to the color around it, so the content
+// of the tile matches none of them
+func WithIndistinguishableDecoys(val bool) Option {
+	return func(opts *Options) {
+		opts.indistinguishableDecoys = val
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Filters
+//_______________________________________________________________________
+
+// WithMasterFilters sets the filters applied in order to the master image, the tile is cut before
+// they run, give it the same chain with WithTileFilters to keep them alike
+// Geometric filters such as canvas.NewSwirl move the answer, Generate returns canvas.GeometricFilterErr
+func WithMasterFilters(vals ...canvas.Filter) Option {
+	return func(opts *Options) {
+		opts.masterFilters = append([]canvas.Filter(nil), vals...)
+	}
+}
+
+// WithTileFilters sets the filters applied in order to the tile image
+func WithTileFilters(vals ...canvas.Filter) Option {
+	return func(opts *Options) {
+		opts.tileFilters = append([]canvas.Filter(nil), vals...)
 	}
 }
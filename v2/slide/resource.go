@@ -8,6 +8,8 @@ package slide
 
 import (
 	"image"
+
+	"github.com/wenlng/go-captcha/v2/base/bggen"
 )
 
 // GraphImage defines the graph resources for the slide CAPTCHA
@@ -21,6 +23,8 @@ type GraphImage struct {
 type Resources struct {
 	rangBackgrounds []image.Image
 	rangGraphImage  []*GraphImage
+
+	backgroundGenerators []bggen.Generator
 }
 
 // NewResources creates a new Resources instance
@@ -29,6 +33,17 @@ func NewResources() *Resources {
 	return &Resources{}
 }
 
+// clone returns a copy of the resources, the slices are copied while the images they hold are shared
+// return: Pointer to the copied Resources
+func (r *Resources) clone() *Resources {
+	return &Resources{
+		rangBackgrounds: append([]image.Image(nil), r.rangBackgrounds...),
+		rangGraphImage:  append([]*GraphImage(nil), r.rangGraphImage...),
+
+		backgroundGenerators: append([]bggen.Generator(nil), r.backgroundGenerators...),
+	}
+}
+
 type Resource func(*Resources)
 
 // WithBackgrounds sets the background images
@@ -38,7 +53,7 @@ type Resource func(*Resources)
 // return: Resource function
 func WithBackgrounds(images []image.Image) Resource {
 	return func(resources *Resources) {
-		resources.rangBackgrounds = images
+		resources.rangBackgrounds = append([]image.Image(nil), images...)
 	}
 }
 
@@ -49,6 +64,18 @@ func WithBackgrounds(images []image.Image) Resource {
 // return: Resource function
 func WithGraphImages(images []*GraphImage) Resource {
 	return func(resources *Resources) {
-		resources.rangGraphImage = images
+		resources.rangGraphImage = append([]*GraphImage(nil), images...)
+	}
+}
+
+// WithBackgroundGenerators sets generators drawing a new background for every captcha,
+// they are selected randomly together with WithBackgrounds
+// params:
+//   - generators: List of background generators, see bggen.Defaults
+//
+// return: Resource function
+func WithBackgroundGenerators(generators []bggen.Generator) Resource {
+	return func(resources *Resources) {
+		resources.backgroundGenerators = append([]bggen.Generator(nil), generators...)
 	}
 }
@@ -8,14 +8,16 @@ package slide
 
 import (
 	"errors"
+	"fmt"
 	"image"
 	"math"
 
+	"github.com/wenlng/go-captcha/v2/base/canvas"
 	"github.com/wenlng/go-captcha/v2/base/helper"
 	"github.com/wenlng/go-captcha/v2/base/imagedata"
 	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/base/observe"
 	"github.com/wenlng/go-captcha/v2/base/option"
-	"github.com/wenlng/go-captcha/v2/base/randgen"
 	"github.com/wenlng/go-captcha/v2/base/random"
 )
 
@@ -26,12 +28,34 @@ const (
 	ModeDrag
 )
 
+// String returns the name of the mode
+func (m Mode) String() string {
+	switch m {
+	case ModeBasic:
+		return "basic"
+	case ModeDrag:
+		return "drag"
+	}
+	return "unknown"
+}
+
 // Captcha defines the interface for slide CAPTCHA
+//
+// A Captcha is safe for concurrent use by multiple goroutines. Its options and
+// resources are copied when it is made and never change afterwards, the images and
+// fonts they hold are shared and must be left untouched, every call of Generate works
+// on its own blocks and canvases. To reconfigure at runtime,
+// call With or WithResources, they return a new instance and leave the receiver untouched.
 type Captcha interface {
 	setOptions(opts ...Option)
 	setResources(resources ...Resource)
+	setLogger(l logger.Logger)
+	setObserver(o observe.Observer)
 	GetOptions() *Options
+	GetMode() Mode
 	Generate() (CaptchaData, error)
+	With(opts ...Option) Captcha
+	WithResources(resources ...Resource) Captcha
 }
 
 var _ Captcha = (*captcha)(nil)
@@ -49,6 +73,7 @@ var (
 type captcha struct {
 	version   string
 	logger    logger.Logger
+	observer  observe.Observer
 	drawImage DrawImage
 	opts      *Options
 	resources *Resources
@@ -63,7 +88,6 @@ type captcha struct {
 // return: Captcha interface instance
 func newWithMode(mode Mode, opts ...Option) Captcha {
 	capt := &captcha{
-		logger:    logger.New(),
 		drawImage: NewDrawImage(),
 		opts:      NewOptions(),
 		resources: NewResources(),
@@ -90,6 +114,7 @@ func (c *captcha) setOptions(opts ...Option) {
 	for _, opt := range opts {
 		opt(c.opts)
 	}
+	c.preloadBackgrounds()
 }
 
 // setResources sets the CAPTCHA resources
@@ -99,6 +124,77 @@ func (c *captcha) setResources(resources ...Resource) {
 	for _, resource := range resources {
 		resource(c.resources)
 	}
+	c.preloadBackgrounds()
+}
+
+// setLogger sets the logger of the captcha, nil uses the default logger
+func (c *captcha) setLogger(l logger.Logger) {
+	c.logger = l
+}
+
+// setObserver sets the observer of the captcha, nil uses the default observer
+func (c *captcha) setObserver(o observe.Observer) {
+	c.observer = o
+}
+
+// log returns the logger of the captcha with its kind and mode as fields
+func (c *captcha) log() logger.Logger {
+	l := c.logger
+	if l == nil {
+		l = logger.Default()
+	}
+	return logger.With(l, logger.F("kind", "slide"), logger.F("mode", c.mode.String()))
+}
+
+// preloadBackgrounds prepares the background cache for the configured image size, the cache is
+// left out with a background variation
+func (c *captcha) preloadBackgrounds() {
+	if c.opts.backgroundCache == nil || c.opts.backgroundVariation != nil || c.opts.imageSize == nil {
+		return
+	}
+
+	c.opts.backgroundCache.Preload(c.resources.rangBackgrounds, c.opts.imageSize.Width, c.opts.imageSize.Height)
+}
+
+// randBackground randomly selects a background image or generator, images get the background variation when it is set,
+// otherwise they are served from the background cache when it is set
+// params:
+//   - size: Image size
+//   - rec: Recorder of the generation
+//
+// return: Background image
+func (c *captcha) randBackground(size *option.Size, rec *observe.Recorder) image.Image {
+	images, generators := c.resources.rangBackgrounds, c.resources.backgroundGenerators
+	i := random.RandInt(0, len(images)+len(generators)-1)
+	if i >= len(images) && len(generators) > 0 {
+		rec.Resource("background", fmt.Sprintf("generator#%d", i-len(images)))
+		img := generators[i-len(images)].Generate(size.Width, size.Height, random.New())
+		if c.opts.backgroundTracker != nil {
+			c.opts.backgroundTracker.Observe(img, c.log())
+		}
+		return img
+	}
+
+	if i < 0 || i >= len(images) {
+		return nil
+	}
+	rec.ResourceIndex("background", i)
+	img := images[i]
+	if img == nil {
+		return nil
+	}
+	if c.opts.backgroundTracker != nil {
+		c.opts.backgroundTracker.Observe(img, c.log())
+	}
+
+	// the variation crops the full-size source, the few candidates of the cache would leave it little to vary
+	if c.opts.backgroundVariation != nil {
+		return c.opts.backgroundVariation.Apply(img, size.Width, size.Height, random.New())
+	}
+	if c.opts.backgroundCache != nil {
+		img = c.opts.backgroundCache.Get(img, size.Width, size.Height)
+	}
+	return img
 }
 
 // GetOptions gets the CAPTCHA options
@@ -107,16 +203,72 @@ func (c *captcha) GetOptions() *Options {
 	return c.opts
 }
 
+// GetMode gets the CAPTCHA mode
+// return: CAPTCHA mode
+func (c *captcha) GetMode() Mode {
+	return c.mode
+}
+
+// With creates a new CAPTCHA from a copy of the current options with opts applied
+// params:
+//   - opts: Options to apply on the copy
+//
+// return: New Captcha instance, the receiver is not modified
+func (c *captcha) With(opts ...Option) Captcha {
+	capt := c.clone()
+	capt.setOptions(opts...)
+	return capt
+}
+
+// WithResources creates a new CAPTCHA from a copy of the current resources with resources applied
+// params:
+//   - resources: Resources to apply on the copy
+//
+// return: New Captcha instance, the receiver is not modified
+func (c *captcha) WithResources(resources ...Resource) Captcha {
+	capt := c.clone()
+	capt.setResources(resources...)
+	return capt
+}
+
+// clone copies the CAPTCHA with its own options and resources
+// return: Copied captcha
+func (c *captcha) clone() *captcha {
+	return &captcha{
+		version:   c.version,
+		logger:    c.logger,
+		observer:  c.observer,
+		drawImage: c.drawImage,
+		opts:      c.opts.clone(),
+		resources: c.resources.clone(),
+		mode:      c.mode,
+	}
+}
+
 // Generate generates slide CAPTCHA data
 // returns:
 //   - CaptchaData: Generated CAPTCHA data
 //   - error: Error information
 func (c *captcha) Generate() (CaptchaData, error) {
+	rec := observe.Start(c.observer, "slide", c.mode.String())
+	data, err := c.generate(rec)
+	rec.End(err)
+	return data, err
+}
+
+// generate generates slide CAPTCHA data
+// params:
+//   - rec: Recorder of the generation
+//
+// returns:
+//   - CaptchaData: Generated CAPTCHA data
+//   - error: Error information
+func (c *captcha) generate(rec *observe.Recorder) (CaptchaData, error) {
 	if err := c.check(); err != nil {
 		return nil, err
 	}
 
-	overlayImage, shadowImage, maskImage := c.genGraph()
+	overlayImage, shadowImage, maskImage := c.genGraph(rec)
 	if overlayImage == nil || shadowImage == nil || maskImage == nil {
 		return nil, GraphImageErr
 	}
@@ -140,15 +292,24 @@ func (c *captcha) Generate() (CaptchaData, error) {
 	var masterImage, masterBgImage, tileImage image.Image
 	var err error
 
-	masterImage, masterBgImage, err = c.genMasterImage(c.opts.imageSize, shadowImage, blocks)
+	gaps := blocks
+	if decoys := c.genDecoyBlocks(c.opts.imageSize, blocks, tilePoint); len(decoys) > 0 {
+		gaps = append(append(make([]*Block, 0, len(blocks)+len(decoys)), blocks...), decoys...)
+	}
+
+	rec.Mark(observe.StageLayout)
+
+	masterImage, masterBgImage, err = c.genMasterImage(c.opts.imageSize, shadowImage, gaps, rec)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageMaster)
 
 	tileImage, err = c.genTileImage(maskImage, masterBgImage, overlayImage, block)
 	if err != nil {
 		return nil, err
 	}
+	rec.Mark(observe.StageThumb)
 
 	if c.mode == ModeBasic {
 		block.TileY = block.Y
@@ -159,11 +320,17 @@ func (c *captcha) Generate() (CaptchaData, error) {
 	}
 	block.TileX = tilePoint.X
 	block.DX = tilePoint.X
+	rec.Mark(observe.StageLayout)
+
+	masterImage = canvas.ApplyFilters(masterImage, c.opts.masterFilters)
+	rec.Mark(observe.StageMaster)
+	tileImage = canvas.ApplyFilters(tileImage, c.opts.tileFilters)
+	rec.Mark(observe.StageThumb)
 
 	return &CaptData{
 		block:       block,
-		masterImage: imagedata.NewJPEGImageData(masterImage),
-		tileImage:   imagedata.NewPNGImageData(tileImage),
+		masterImage: imagedata.NewJPEGImageDataWithHook(masterImage, rec.EncodeHook("master")),
+		tileImage:   imagedata.NewPNGImageDataWithHook(tileImage, rec.EncodeHook("thumb")),
 	}, nil
 }
 
@@ -172,12 +339,13 @@ func (c *captcha) Generate() (CaptchaData, error) {
 //   - size: Image size
 //   - shadowImage: Shadow image
 //   - blocks: List of blocks
+//   - rec: Recorder of the generation
 //
 // returns:
 //   - image.Image: Master image
 //   - image.Image: Background image
 //   - error: Error information
-func (c *captcha) genMasterImage(size *option.Size, shadowImage image.Image, blocks []*Block) (image.Image, image.Image, error) {
+func (c *captcha) genMasterImage(size *option.Size, shadowImage image.Image, blocks []*Block, rec *observe.Recorder) (image.Image, image.Image, error) {
 	var drawBlocks = make([]*DrawBlock, 0, len(blocks))
 	for i := 0; i < len(blocks); i++ {
 		block := blocks[i]
@@ -195,9 +363,12 @@ func (c *captcha) genMasterImage(size *option.Size, shadowImage image.Image, blo
 	return c.drawImage.DrawWithNRGBA(&DrawImageParams{
 		Width:             size.Width,
 		Height:            size.Height,
-		Background:        randgen.RandImage(c.resources.rangBackgrounds),
+		Background:        c.randBackground(size, rec),
 		Alpha:             c.opts.imageAlpha,
 		CaptchaDrawBlocks: drawBlocks,
+		GapFeather:        c.opts.gapFeather,
+		GapNoise:          c.opts.gapNoise,
+		FlattenGaps:       c.opts.indistinguishableDecoys,
 	})
 }
 
@@ -227,6 +398,8 @@ func (c *captcha) genTileImage(maskImage image.Image, bgImage image.Image, overl
 			Block:  block,
 			Image:  overlayImage,
 		},
+		EdgeJitter: c.opts.tileEdgeJitter,
+		Lighting:   c.opts.tileLighting,
 	})
 }
 
@@ -327,6 +500,69 @@ func (c *captcha) genGraphBlocks(imageSize *option.Size, size *option.RangeVal,
 	return blocks, point
 }
 
+// genDecoyBlocks generates the decoy gaps, as many as fit clear of the graphs and of the tile start
+// params:
+//   - imageSize: Main image size
+//   - blocks: Graph blocks
+//   - tilePoint: Tile start position
+//
+// return: Decoy blocks
+func (c *captcha) genDecoyBlocks(imageSize *option.Size, blocks []*Block, tilePoint *option.Point) []*Block {
+	if c.opts.decoyNumber <= 0 || len(blocks) == 0 {
+		return nil
+	}
+
+	ref := blocks[0]
+	taken := []image.Rectangle{image.Rect(tilePoint.X, tilePoint.Y, tilePoint.X+ref.Width, tilePoint.Y+ref.Height)}
+	for _, block := range blocks {
+		taken = append(taken, image.Rect(block.X, block.Y, block.X+block.Width, block.Y+block.Height))
+	}
+
+	var decoys []*Block
+	for i := 0; i < c.opts.decoyNumber; i++ {
+		for try := 0; try < 50; try++ {
+			width, height := ref.Width, ref.Height
+			if !c.opts.indistinguishableDecoys {
+				scale := random.RandInt(90, 110)
+				width, height = ref.Width*scale/100, ref.Height*scale/100
+			}
+
+			maxX, maxY := imageSize.Width-width-5, imageSize.Height-height-5
+			if maxX < 5 || maxY < 5 {
+				return decoys
+			}
+
+			x := random.RandInt(5, maxX)
+			y := random.RandInt(5, maxY)
+			if c.mode == ModeBasic {
+				// The tile only slides along its row
+				y = int(math.Min(math.Max(float64(ref.Y+(ref.Height-height)/2), 5), float64(maxY)))
+			}
+
+			rect := image.Rect(x, y, x+width, y+height)
+			if overlapsAny(rect.Inset(-4), taken) {
+				continue
+			}
+
+			taken = append(taken, rect)
+			decoys = append(decoys, &Block{X: x, Y: y, Width: width, Height: height, Angle: ref.Angle})
+			break
+		}
+	}
+
+	return decoys
+}
+
+// overlapsAny reports whether the rectangle overlaps any of the others
+func overlapsAny(rect image.Rectangle, others []image.Rectangle) bool {
+	for _, o := range others {
+		if rect.Overlaps(o) {
+			return true
+		}
+	}
+	return false
+}
+
 // calcXWithDeadZone calculates the X coordinate range (considering dead zone)
 // params:
 //   - start: Start X coordinate
@@ -363,15 +599,19 @@ func (c *captcha) calcYWithDeadZone(start, end, value int, dzdType DeadZoneDirec
 }
 
 // genGraph generates random graph resources
+// params:
+//   - rec: Recorder of the generation
+//
 // returns:
 //   - maskImage: Mask image
 //   - shadowImage: Shadow image
 //   - templateImage: Template image
-func (c *captcha) genGraph() (maskImage, shadowImage, templateImage image.Image) {
+func (c *captcha) genGraph(rec *observe.Recorder) (maskImage, shadowImage, templateImage image.Image) {
 	index := helper.RandIndex(len(c.resources.rangGraphImage))
 	if index < 0 {
 		return nil, nil, nil
 	}
+	rec.ResourceIndex("tile", index)
 
 	graphImage := c.resources.rangGraphImage[index]
 
@@ -391,9 +631,12 @@ func (c *captcha) check() error {
 		}
 	}
 
-	if len(c.resources.rangBackgrounds) == 0 {
+	if len(c.resources.rangBackgrounds) == 0 && len(c.resources.backgroundGenerators) == 0 {
 		return EmptyBackgroundImageErr
 	}
+	if canvas.HasGeometric(c.opts.masterFilters) {
+		return canvas.GeometricFilterErr
+	}
 
 	return nil
 }
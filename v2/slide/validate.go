@@ -7,6 +7,7 @@
 package slide
 
 // Validate checks if the point position is within the specified range
+// It leaves the report to the caller, report the outcome of the whole verification with observe.Verified("slide", ok)
 // params:
 //   - sx: Source X coordinate
 //   - sy: Source Y coordinate
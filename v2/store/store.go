@@ -0,0 +1,218 @@
+/**
+ * @Author Awen
+ * @Date 2024/06/01
+ * @Email wengaolng@gmail.com
+ **/
+
+// Package store keeps the issued challenges until they are verified, it hands each challenge
+// to a single successful verification and invalidates it after too many failed ones.
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is the default lifetime of a challenge
+	DefaultTTL = 5 * time.Minute
+	// DefaultMaxAttempts is the default number of failed verifications after which a challenge is invalidated
+	DefaultMaxAttempts = 3
+)
+
+var (
+	NotFoundErr    = errors.New("challenge not found")
+	ExpiredErr     = errors.New("challenge expired")
+	InvalidatedErr = errors.New("challenge invalidated after too many failed attempts")
+	EmptyIDErr     = errors.New("challenge id is empty")
+)
+
+// Challenge is an issued challenge with the data needed to verify it, such as the dots of a
+// click captcha, the block of a slide or rotate captcha
+type Challenge struct {
+	ID        string
+	Kind      string
+	Data      interface{}
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// Attempts is the number of verifications, the current one included while verifying
+	Attempts int
+}
+
+// Store keeps the issued challenges
+type Store interface {
+	// Put stores a challenge, an empty IssuedAt and ExpiresAt are set from the TTL of the store
+	Put(c *Challenge) error
+	// Get returns a copy of a challenge without counting an attempt
+	Get(id string) (*Challenge, error)
+	// Verify counts an attempt and calls check with a copy of the challenge, the challenge is removed
+	// when check succeeds and invalidated when the failed attempts reach the maximum
+	Verify(id string, check func(c *Challenge) bool) (bool, error)
+	// Delete removes a challenge
+	Delete(id string) error
+}
+
+// NewID returns a random challenge id
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Options .
+type Options struct {
+	ttl         time.Duration
+	maxAttempts int
+}
+
+// Option .
+type Option func(*Options)
+
+// WithTTL sets the lifetime of the challenges put without ExpiresAt
+func WithTTL(val time.Duration) Option {
+	return func(opts *Options) {
+		if val > 0 {
+			opts.ttl = val
+		}
+	}
+}
+
+// WithMaxAttempts sets the number of failed verifications after which a challenge is invalidated
+func WithMaxAttempts(val int) Option {
+	return func(opts *Options) {
+		if val > 0 {
+			opts.maxAttempts = val
+		}
+	}
+}
+
+var _ Store = (*Memory)(nil)
+
+// Memory is a Store in memory, it is safe for concurrent use
+type Memory struct {
+	mu         sync.Mutex
+	opts       *Options
+	challenges map[string]*Challenge
+	puts       int
+}
+
+// NewMemory creates a store in memory
+// params:
+//   - opts: Options
+//
+// return: Memory instance
+func NewMemory(opts ...Option) *Memory {
+	o := &Options{ttl: DefaultTTL, maxAttempts: DefaultMaxAttempts}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Memory{opts: o, challenges: make(map[string]*Challenge)}
+}
+
+// Put .
+func (m *Memory) Put(c *Challenge) error {
+	if c.ID == "" {
+		return EmptyIDErr
+	}
+
+	nc := *c
+	if nc.IssuedAt.IsZero() {
+		nc.IssuedAt = time.Now()
+	}
+	if nc.ExpiresAt.IsZero() {
+		nc.ExpiresAt = nc.IssuedAt.Add(m.opts.ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.puts++
+	if m.puts%1024 == 0 {
+		m.sweep(time.Now())
+	}
+	m.challenges[nc.ID] = &nc
+	return nil
+}
+
+// Get .
+func (m *Memory) Get(id string) (*Challenge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, err := m.load(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	cc := *c
+	return &cc, nil
+}
+
+// Verify runs check with the store locked, so a challenge passes at most once,
+// check must be quick and must not use the store
+func (m *Memory) Verify(id string, check func(c *Challenge) bool) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, err := m.load(id, time.Now())
+	if err != nil {
+		return false, err
+	}
+	c.Attempts++
+	cc := *c
+
+	if check(&cc) {
+		delete(m.challenges, id)
+		return true, nil
+	}
+	if c.Attempts >= m.opts.maxAttempts {
+		delete(m.challenges, id)
+		return false, InvalidatedErr
+	}
+	return false, nil
+}
+
+// Delete .
+func (m *Memory) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.challenges[id]; !ok {
+		return NotFoundErr
+	}
+	delete(m.challenges, id)
+	return nil
+}
+
+// Len returns the number of stored challenges, expired ones included until they are swept
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.challenges)
+}
+
+// load returns a live challenge and removes an expired one, m.mu must be held
+func (m *Memory) load(id string, now time.Time) (*Challenge, error) {
+	c, ok := m.challenges[id]
+	if !ok {
+		return nil, NotFoundErr
+	}
+	if now.After(c.ExpiresAt) {
+		delete(m.challenges, id)
+		return nil, ExpiredErr
+	}
+	return c, nil
+}
+
+// sweep removes the expired challenges, m.mu must be held
+func (m *Memory) sweep(now time.Time) {
+	for id, c := range m.challenges {
+		if now.After(c.ExpiresAt) {
+			delete(m.challenges, id)
+		}
+	}
+}
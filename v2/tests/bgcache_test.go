@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"image"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/bgcache"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+func TestBackgroundCacheLRU(t *testing.T) {
+	imgs := []image.Image{
+		image.NewNRGBA(image.Rect(0, 0, 400, 300)),
+		image.NewNRGBA(image.Rect(0, 0, 400, 300)),
+		image.NewNRGBA(image.Rect(0, 0, 100, 80)),
+	}
+
+	// room for exactly two entries of 2 candidates at 300x220
+	cache := bgcache.New(2*2*300*220*4, 2)
+	cache.Preload(imgs, 300, 220)
+
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 entries after eviction, got %d", cache.Len())
+	}
+	if cache.UsedBytes() > 2*2*300*220*4 {
+		t.Fatalf("memory limit exceeded: %d", cache.UsedBytes())
+	}
+
+	bg := cache.Get(imgs[2], 300, 220)
+	if bg.Bounds().Dx() != 300 || bg.Bounds().Dy() != 220 {
+		t.Fatalf("small background not scaled to size: %v", bg.Bounds())
+	}
+}
+
+func BenchmarkSlideGenerate(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := slideTileCapt.Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSlideGenerateWithBackgroundCache(b *testing.B) {
+	capt := slideTileCapt.With(slide.WithBackgroundCache(bgcache.New(0, 0)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := capt.Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/bggen"
+	"github.com/wenlng/go-captcha/v2/base/random"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/rotate"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+func TestBackgroundGenerators(t *testing.T) {
+	for i, g := range bggen.Defaults() {
+		a := g.Generate(120, 80, random.New())
+		b := g.Generate(120, 80, random.New())
+		if a.Bounds().Dx() != 120 || a.Bounds().Dy() != 80 {
+			t.Fatalf("generator %d: bounds %v", i, a.Bounds())
+		}
+		for k := 3; k < len(a.Pix); k += 4 {
+			if a.Pix[k] != 0xff {
+				t.Fatalf("generator %d: pixel %d is not opaque", i, k/4)
+			}
+		}
+		if bytes.Equal(a.Pix, b.Pix) {
+			t.Fatalf("generator %d: two backgrounds are the same", i)
+		}
+	}
+}
+
+func TestCaptchaBackgroundGenerators(t *testing.T) {
+	// click text mode draws procedural backgrounds when none are set
+	if _, err := textCapt.WithResources(click.WithBackgrounds(nil)).Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	gens := []bggen.Generator{bggen.NewVoronoi(12)}
+	if _, err := slideTileCapt.WithResources(slide.WithBackgrounds(nil), slide.WithBackgroundGenerators(gens)).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotateCapt.WithResources(rotate.WithImages(nil), rotate.WithImageGenerators(gens)).Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := slideTileCapt.WithResources(slide.WithBackgrounds(nil)).Generate(); err != slide.EmptyBackgroundImageErr {
+		t.Fatalf("slide without backgrounds: %v", err)
+	}
+}
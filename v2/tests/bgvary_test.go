@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/bgcache"
+	"github.com/wenlng/go-captcha/v2/base/bggen"
+	"github.com/wenlng/go-captcha/v2/base/bgvary"
+	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/base/random"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+func TestBackgroundVariation(t *testing.T) {
+	src := bggen.NewNoise().Generate(400, 300, random.New())
+	before := append([]uint8(nil), src.Pix...)
+
+	v := bgvary.Default()
+	a := v.Apply(src, 300, 220, random.New())
+	b := v.Apply(src, 300, 220, random.New())
+	if a.Bounds() != image.Rect(0, 0, 300, 220) {
+		t.Fatalf("varied bounds %v", a.Bounds())
+	}
+	if bytes.Equal(a.Pix, b.Pix) {
+		t.Fatal("two variations of a background are the same")
+	}
+	if !bytes.Equal(src.Pix, before) {
+		t.Fatal("Apply changed the source background")
+	}
+}
+
+func TestBackgroundTracker(t *testing.T) {
+	tracker := bgvary.NewTracker(5, 20)
+	same := bggen.NewGradient().Generate(60, 40, random.New())
+	for i := 0; i < 20; i++ {
+		tracker.Observe(same, logger.NewNop())
+	}
+	if tracker.Distinct() != 1 || tracker.Effective() != 1 {
+		t.Fatalf("one background: %d distinct, %.2f effective", tracker.Distinct(), tracker.Effective())
+	}
+
+	// the window keeps only the last 20, all of them new
+	gen := bggen.NewNoise()
+	for i := 0; i < 20; i++ {
+		tracker.Observe(gen.Generate(60, 40, random.New()), logger.NewNop())
+	}
+	if tracker.Distinct() != 20 || math.Abs(tracker.Effective()-20) > 1e-9 {
+		t.Fatalf("twenty backgrounds: %d distinct, %.2f effective", tracker.Distinct(), tracker.Effective())
+	}
+
+	// the variation works on the full-size source, the cache is left out
+	cache := bgcache.New(0, 0)
+	bg := bggen.NewVoronoi(20).Generate(300, 220, random.New())
+	capt := slideTileCapt.With(
+		slide.WithBackgroundCache(cache),
+		slide.WithBackgroundVariation(bgvary.Default()),
+		slide.WithBackgroundTracker(tracker),
+	).WithResources(slide.WithBackgrounds([]image.Image{bg}))
+	for i := 0; i < 5; i++ {
+		if _, err := capt.Generate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tracker.Distinct() != 16 {
+		t.Fatalf("after 5 captchas on one background: %d distinct, want 16", tracker.Distinct())
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("the cache holds %d entries with a variation", cache.Len())
+	}
+}
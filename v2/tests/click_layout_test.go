@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"image"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/click"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestClickLayout(t *testing.T) {
+	regular, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bgImage, err := loadPng("../.cache/bg.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := click.NewBuilder(
+		click.WithRangeLen(option.RangeVal{Min: 7, Max: 7}),
+		click.WithDisabledRangeVerifyLen(true),
+		click.WithDotSpacing(4),
+		click.WithDotMargin(6),
+	)
+	builder.SetResources(
+		click.WithChars([]string{"A1", "B2", "C3", "D4", "E5", "F6", "G7", "H8"}),
+		click.WithSfntFonts([]*sfnt.Font{regular}),
+		click.WithBackgrounds([]image.Image{bgImage}),
+	)
+	capt := builder.Make()
+
+	for n := 0; n < 20; n++ {
+		captData, err := capt.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the drawn boxes of the dots keep the spacing and the margin
+		dots := captData.GetData()
+		var rects []image.Rectangle
+		for _, dot := range dots {
+			r := image.Rect(dot.X, dot.Y, dot.X+dot.Width+4, dot.Y+dot.Height+4)
+			if dot.X < 6 || dot.Y < 6 || dot.X+dot.Width > 300-6 || dot.Y+dot.Height > 220-6 {
+				t.Fatalf("dot %d crosses the margin: %+v", dot.Index, dot)
+			}
+			for _, p := range rects {
+				if r.Overlaps(p) {
+					t.Fatalf("dot %d overlaps another one: %v %v", dot.Index, r, p)
+				}
+			}
+			rects = append(rects, r)
+		}
+	}
+}
+
+func TestClickLayoutFit(t *testing.T) {
+	capt := textCapt.With(click.WithImageSize(option.Size{Width: 120, Height: 80}))
+	if _, err := capt.Generate(); err != click.DotsFitErr {
+		t.Fatalf("expected DotsFitErr, got %v", err)
+	}
+}
+
+// faceCounter counts the faces made of a font, without a glyph cache a face is made for every text drawn
+type faceCounter struct {
+	canvas.Font
+	faces int64
+}
+
+func (f *faceCounter) NewFace(size, dpi float64, hinting font.Hinting) (font.Face, error) {
+	atomic.AddInt64(&f.faces, 1)
+	return f.Font.NewFace(size, dpi, hinting)
+}
+
+func TestClickLayoutDrawsOnce(t *testing.T) {
+	regular, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bgImage, err := loadPng("../.cache/bg.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &faceCounter{Font: canvas.NewSfntFont(regular)}
+	builder := click.NewBuilder(
+		click.WithRangeLen(option.RangeVal{Min: 5, Max: 5}),
+		click.WithDisabledRangeVerifyLen(true),
+		click.WithDisplayShadow(true),
+		click.WithGlyphCache(nil),
+	)
+	builder.SetResources(
+		click.WithChars([]string{"A", "B", "C", "D", "E", "F"}),
+		click.WithFallbackFonts([]canvas.Font{counter}),
+		click.WithBackgrounds([]image.Image{bgImage}),
+	)
+	captData, err := builder.Make().Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a master dot is rendered once when measured, its color and shadow are painted from the
+	// rendering, a thumb dot is rendered once
+	if n := len(captData.GetData()); counter.faces != int64(2*n) {
+		t.Fatalf("%d faces made for %d dots", counter.faces, n)
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"image"
+	"log"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/base/shapegen"
+	"github.com/wenlng/go-captcha/v2/click"
+)
+
+func newModesBuilder() click.Builder {
+	builder := click.NewBuilder(
+		click.WithRangeLen(option.RangeVal{Min: 5, Max: 6}),
+		click.WithRangeVerifyLen(option.RangeVal{Min: 2, Max: 3}),
+	)
+
+	font, err := loadFont("../.cache/yrdzst-bold.ttf")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	bgImage, err := loadPng("../.cache/bg.png")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	builder.SetResources(
+		click.WithChars([]string{"F", "G", "J", "K", "L", "P", "R"}),
+		click.WithFonts([]*truetype.Font{font}),
+		click.WithBackgrounds([]image.Image{bgImage}),
+	)
+	return builder
+}
+
+func TestClickOddOneOut(t *testing.T) {
+	// the copies lean 40 degrees give or take 8, the odd dot is mirrored, turned by 180 degrees or both
+	builder := newModesBuilder()
+	builder.SetOptions(click.WithRangeAnglePos([]option.RangeVal{{Min: 40, Max: 40}}))
+	text := builder.MakeOddOneOut()
+	shape := text.WithResources(click.WithShapeGenerators(shapegen.Defaults()))
+
+	for i := 0; i < 20; i++ {
+		capt := text
+		if i%2 == 1 {
+			capt = shape
+		}
+		captData, err := capt.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dots := captData.GetData()
+		if len(dots) != 1 {
+			t.Fatalf("%d odd dots", len(dots))
+		}
+		odd := dots[0]
+		turned := odd.Angle >= 212 && odd.Angle <= 228
+		if !turned && !(odd.Mirror && odd.Angle >= 32 && odd.Angle <= 48) {
+			t.Fatalf("odd dot neither mirrored nor turned %+v", odd)
+		}
+		if captData.GetPrompt() != "Click the one that is different" {
+			t.Fatalf("unexpected prompt %q", captData.GetPrompt())
+		}
+
+		dot := dots[0]
+		if !click.ValidateSet([]option.Point{{X: dot.X + dot.Width/2, Y: dot.Y + dot.Height/2}}, dots, 4) {
+			t.Fatal("odd dot not validated")
+		}
+	}
+}
+
+func TestClickCount(t *testing.T) {
+	capt := newModesBuilder().MakeCount().WithResources(click.WithShapeGenerators(shapegen.Defaults()))
+	captData, err := capt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dots := captData.GetData()
+	if len(dots) < 2 {
+		t.Fatalf("%d copies", len(dots))
+	}
+	var points []option.Point
+	for i := len(dots) - 1; i >= 0; i-- {
+		if dots[i].Shape != dots[0].Shape {
+			t.Fatalf("copies of %q and %q", dots[0].Shape, dots[i].Shape)
+		}
+		points = append(points, option.Point{X: dots[i].X + dots[i].Width/2, Y: dots[i].Y + dots[i].Height/2})
+	}
+	if captData.GetPrompt() != "Click every "+dots[0].Shape {
+		t.Fatalf("unexpected prompt %q", captData.GetPrompt())
+	}
+
+	if !click.ValidateSet(points, dots, 4) {
+		t.Fatal("clicks in reverse order not validated")
+	}
+	if click.ValidateSet(points[1:], dots, 4) {
+		t.Fatal("missing click validated")
+	}
+	points[0] = points[1]
+	if click.ValidateSet(points, dots, 0) {
+		t.Fatal("double click on a copy validated")
+	}
+}
+
+func TestClickCountFewValues(t *testing.T) {
+	// the counting mode draws at least two values, a single one can't be told apart
+	shape, err := loadPng("../.cache/shape1.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	capt := newModesBuilder().MakeCount().With(click.WithRangeLen(option.RangeVal{Min: 1, Max: 1})).
+		WithResources(click.WithShapes(map[string]image.Image{"shape1": shape}))
+	if _, err = capt.Generate(); err != click.ShapesRangeLenErr {
+		t.Fatalf("got %v", err)
+	}
+
+	capt = newModesBuilder().MakeCount().With(click.WithRangeLen(option.RangeVal{Min: 1, Max: 1})).
+		WithResources(click.WithChars([]string{"F"}))
+	if _, err = capt.Generate(); err != click.CharRangeLenErr {
+		t.Fatalf("got %v", err)
+	}
+}
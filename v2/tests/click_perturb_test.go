@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/click"
+)
+
+func TestClickPerturbStrength(t *testing.T) {
+	capt := textCapt.With(click.WithPerturbStrength(1))
+	if n := len(capt.GetOptions().GetPerturbers()); n != 4 {
+		t.Fatalf("got %d perturbers, want the 4 built-in ones", n)
+	}
+
+	for i := 0; i < 5; i++ {
+		captData, err := capt.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		size := capt.GetOptions().GetImageSize()
+		b := captData.GetMasterImage().Get().Bounds()
+		if b.Dx() != size.Width || b.Dy() != size.Height {
+			t.Fatalf("master is %v, want %dx%d", b, size.Width, size.Height)
+		}
+	}
+}
+
+func TestClickCustomPerturber(t *testing.T) {
+	var calls, dotsSeen int
+	marker := click.PerturberFunc(func(img *image.NRGBA, dots []*click.DrawDot, rnd *rand.Rand) {
+		calls++
+		dotsSeen = len(dots)
+		img.Pix[0], img.Pix[1], img.Pix[2], img.Pix[3] = 1, 2, 3, 255
+	})
+
+	capt := textCapt.With(click.WithPerturbStrength(0.5), click.WithPerturbers(marker))
+	if capt.GetOptions().GetPerturbStrength() != 0 {
+		t.Fatal("custom perturbers keep the built-in strength")
+	}
+
+	captData, err := capt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("perturber called %d times", calls)
+	}
+	if dotsSeen != len(captData.GetData()) {
+		t.Fatalf("perturber saw %d dots, captcha has %d", dotsSeen, len(captData.GetData()))
+	}
+
+	r, g, b, _ := captData.GetMasterImage().Get().At(0, 0).RGBA()
+	if r>>8 != 1 || g>>8 != 2 || b>>8 != 3 {
+		t.Fatal("perturber did not change the master image")
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/base/shapegen"
+	"github.com/wenlng/go-captcha/v2/click"
+)
+
+func TestClickPromptAttributes(t *testing.T) {
+	capt := shapeCapt.WithResources(click.WithShapes(nil), click.WithShapeGenerators(shapegen.Defaults())).With(
+		click.WithPromptAttributes(click.AttributeColor|click.AttributeSize),
+		click.WithRangeSize(option.RangeVal{Min: 20, Max: 40}),
+		click.WithRangeColors([]string{"#d81e06", "#1677ff", "#13a10e"}),
+	)
+
+	colors := map[string]string{"#d81e06": "red", "#1677ff": "blue", "#13a10e": "green"}
+	for n := 0; n < 20; n++ {
+		captData, err := capt.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		prompt := captData.GetPrompt()
+		if !strings.HasPrefix(prompt, "Click the ") {
+			t.Fatalf("unexpected prompt %q", prompt)
+		}
+		seen := make(map[string]bool)
+		for i := 0; i < len(captData.GetData()); i++ {
+			dot := captData.GetData()[i]
+			size := "small"
+			if dot.Size >= 35 {
+				size = "large"
+			} else if dot.Size > 25 {
+				t.Fatalf("size %d is in neither quarter", dot.Size)
+			}
+			desc := size + " " + colors[dot.Color] + " " + dot.Shape
+			if seen[desc] || !strings.Contains(prompt, desc) {
+				t.Fatalf("%q is duplicated or missing from %q", desc, prompt)
+			}
+			seen[desc] = true
+		}
+	}
+
+	zh, ok := click.LocalePrompt("zh-CN")
+	if !ok {
+		t.Fatal("no zh prompt")
+	}
+	captData, err := capt.With(click.WithPromptLocale(zh)).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(captData.GetPrompt(), "请依次点击") {
+		t.Fatalf("unexpected prompt %q", captData.GetPrompt())
+	}
+}
+
+func TestClickPromptColorOriginal(t *testing.T) {
+	// the shape images keep their colors, the prompt could name a color no shape has
+	capt := shapeCapt.With(
+		click.WithPromptAttributes(click.AttributeColor),
+		click.WithUseShapeOriginalColor(true),
+	)
+	if _, err := capt.Generate(); err != click.PromptColorErr {
+		t.Fatalf("expected PromptColorErr, got %v", err)
+	}
+
+	// the generated shapes are drawn in the named color
+	capt = capt.WithResources(click.WithShapes(nil), click.WithShapeGenerators(shapegen.Defaults()))
+	captData, err := capt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	master := captData.GetMasterImage().Get()
+	for i := 0; i < len(captData.GetData()); i++ {
+		dot := captData.GetData()[i]
+		if !hasColor(master, image.Rect(dot.X, dot.Y, dot.X+dot.Width, dot.Y+dot.Height), dot.Color) {
+			t.Fatalf("the %s of the prompt %q is not drawn in %s", dot.Shape, captData.GetPrompt(), dot.Color)
+		}
+	}
+}
+
+// hasColor checks if a pixel of the area is close to the hex color, the master image is a JPEG
+func hasColor(img image.Image, area image.Rectangle, hex string) bool {
+	co, err := helper.ParseHexColor(hex)
+	if err != nil {
+		return false
+	}
+	near := func(a uint32, b uint8) bool {
+		d := int(a>>8) - int(b)
+		return d > -40 && d < 40
+	}
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if near(r, co.R) && near(g, co.G) && near(b, co.B) {
+				return true
+			}
+		}
+	}
+	return false
+}
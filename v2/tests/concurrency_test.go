@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/rotate"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+const (
+	concurrencyWorkers = 8
+	concurrencyRounds  = 4
+)
+
+// hammer runs fn from many goroutines at once, run with -race to catch shared state
+func hammer(t *testing.T, fn func() error) {
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrencyWorkers*concurrencyRounds)
+	for i := 0; i < concurrencyWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < concurrencyRounds; j++ {
+				if err := fn(); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestClickTextConcurrentGenerate(t *testing.T) {
+	hammer(t, func() error {
+		captData, err := textCapt.Generate()
+		if err != nil {
+			return err
+		}
+		_, err = captData.GetMasterImage().ToBytes()
+		return err
+	})
+}
+
+func TestClickShapeConcurrentGenerate(t *testing.T) {
+	hammer(t, func() error {
+		_, err := shapeCapt.Generate()
+		return err
+	})
+}
+
+func TestSlideConcurrentGenerate(t *testing.T) {
+	hammer(t, func() error {
+		_, err := slideTileCapt.Generate()
+		return err
+	})
+}
+
+func TestRotateConcurrentGenerate(t *testing.T) {
+	hammer(t, func() error {
+		_, err := rotateCapt.Generate()
+		return err
+	})
+}
+
+func TestConcurrentWith(t *testing.T) {
+	before := textCapt.GetOptions().GetImageSize()
+
+	hammer(t, func() error {
+		capt := textCapt.With(click.WithImageSize(option.Size{Width: 320, Height: 240}))
+		if _, err := capt.Generate(); err != nil {
+			return err
+		}
+
+		if _, err := slideTileCapt.With(slide.WithGenGraphNumber(2)).Generate(); err != nil {
+			return err
+		}
+
+		_, err := rotateCapt.With(rotate.WithImageSquareSize(200)).Generate()
+		return err
+	})
+
+	after := textCapt.GetOptions().GetImageSize()
+	if *before != *after {
+		t.Fatalf("With modified the original captcha: %v -> %v", before, after)
+	}
+
+	size := textCapt.With(click.WithImageSize(option.Size{Width: 320, Height: 240})).GetOptions().GetImageSize()
+	if size.Width != 320 || size.Height != 240 {
+		t.Fatalf("With did not apply the option: %v", size)
+	}
+}
+
+func TestResourcesCopied(t *testing.T) {
+	bg := image.NewNRGBA(image.Rect(0, 0, 300, 240))
+	draw.Draw(bg, bg.Bounds(), image.NewUniform(color.NRGBA{R: 0x40, G: 0x80, B: 0xc0, A: 0xff}), image.Point{}, draw.Src)
+	shapes := getShapeMaps()
+	backgrounds := []image.Image{bg}
+
+	clickRes := shapeCapt.WithResources(click.WithShapes(shapes), click.WithBackgrounds(backgrounds))
+	slideRes := slideTileCapt.WithResources(slide.WithBackgrounds(backgrounds))
+	rotateRes := rotateCapt.WithResources(rotate.WithImages(backgrounds))
+
+	// the captchas keep their own copies when the caller changes its slice and map
+	backgrounds[0] = nil
+	for name := range shapes {
+		delete(shapes, name)
+	}
+
+	if _, err := clickRes.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slideRes.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotateRes.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
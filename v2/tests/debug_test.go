@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/debug"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+func isAnswerColor(img *image.NRGBA, x, y int) bool {
+	c := img.NRGBAAt(x, y)
+	return c == color.NRGBA(debug.AnswerColor)
+}
+
+func TestDebugDrawClick(t *testing.T) {
+	captData, err := textCapt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	master := captData.GetMasterImage().Get()
+	before := master.At(0, 0)
+	img := debug.DrawClick(captData)
+
+	if img.Bounds().Size() != master.Bounds().Size() {
+		t.Fatalf("size changed: %v -> %v", master.Bounds(), img.Bounds())
+	}
+	if master.At(0, 0) != before {
+		t.Fatal("master image was modified")
+	}
+
+	for _, dot := range captData.GetData() {
+		drawn := false
+		for x := dot.X; x < dot.X+dot.Width && !drawn; x++ {
+			drawn = isAnswerColor(img, x, dot.Y+dot.Height-1)
+		}
+		if !drawn {
+			t.Fatalf("no box drawn around dot %d", dot.Index)
+		}
+	}
+}
+
+func TestDebugDrawSlide(t *testing.T) {
+	captData, err := slideTileCapt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := debug.DrawSlide(captData)
+	block := captData.GetData()
+	if !isAnswerColor(img, block.X+block.Width-1, block.Y+block.Height-1) {
+		t.Fatal("no target rectangle drawn")
+	}
+}
+
+func TestDebugDrawSlideStart(t *testing.T) {
+	master := image.NewNRGBA(image.Rect(0, 0, 200, 120))
+	block := &slide.Block{X: 140, Y: 40, Width: 40, Height: 40, DX: 10, DY: 60}
+	img := debug.DrawSlideBlock(master, block)
+
+	// the dashed start rectangle is at the display position
+	drawn := false
+	for y := block.DY; y < block.DY+block.Height && !drawn; y++ {
+		drawn = img.NRGBAAt(block.DX, y) == color.NRGBA(debug.StartColor)
+	}
+	if !drawn {
+		t.Fatal("no start rectangle drawn at DX, DY")
+	}
+}
+
+func TestDebugDrawRotate(t *testing.T) {
+	captData, err := rotateCapt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := debug.DrawRotate(captData)
+	master := captData.GetMasterImage().Get()
+
+	changed := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if isAnswerColor(img, x, y) && master.At(x, y) != img.At(x, y) {
+				changed++
+			}
+		}
+	}
+	if changed == 0 {
+		t.Fatal("no angle drawn")
+	}
+}
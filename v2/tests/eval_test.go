@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/shapegen"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/eval"
+)
+
+func TestEvalSlide(t *testing.T) {
+	results, err := eval.EvaluateSlide("default", slideTileCapt, nil, eval.WithTrials(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(eval.DefaultSlideAttackers()) {
+		t.Fatalf("got %d results", len(results))
+	}
+	for _, r := range results {
+		if r.Trials != 10 || r.Solved > r.Trials {
+			t.Fatalf("%s: %d/%d solved", r.Attacker, r.Solved, r.Trials)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = eval.WriteReport(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "edge-match") {
+		t.Fatal(buf.String())
+	}
+	t.Log("\n" + buf.String())
+}
+
+func TestEvalClick(t *testing.T) {
+	results, err := eval.EvaluateClick("shape", shapeCapt, nil, eval.WithTrials(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Trials != 10 {
+			t.Fatalf("%s: %d trials", r.Attacker, r.Trials)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = eval.WriteReport(&buf, results)
+	t.Log("\n" + buf.String())
+}
+
+// answerCapt keeps the data of the last generation for answerAttacker
+type answerCapt struct {
+	click.Captcha
+	last click.CaptchaData
+}
+
+func (c *answerCapt) Generate() (click.CaptchaData, error) {
+	data, err := c.Captcha.Generate()
+	c.last = data
+	return data, err
+}
+
+// answerAttacker clicks the centers of the dots from the last to the first
+type answerAttacker struct {
+	capt *answerCapt
+}
+
+func (a answerAttacker) Name() string {
+	return "answer"
+}
+
+func (a answerAttacker) SolveClick(c *eval.ClickChallenge) ([]image.Point, error) {
+	dots := a.capt.last.GetData()
+	var points []image.Point
+	for i := len(dots) - 1; i >= 0; i-- {
+		points = append(points, image.Point{X: dots[i].X + dots[i].Width/2, Y: dots[i].Y + dots[i].Height/2})
+	}
+	return points, nil
+}
+
+func TestEvalClickCount(t *testing.T) {
+	capt := &answerCapt{Captcha: newModesBuilder().MakeCount().WithResources(click.WithShapeGenerators(shapegen.Defaults()))}
+	results, err := eval.EvaluateClick("count", capt, []eval.ClickAttacker{answerAttacker{capt}}, eval.WithTrials(5), eval.WithWorkers(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := results[0]; r.Solved != r.Trials {
+		t.Fatalf("%d/%d solved in any order", r.Solved, r.Trials)
+	}
+}
+
+func TestEvalRotate(t *testing.T) {
+	results, err := eval.EvaluateRotate("default", rotateCapt, nil, eval.WithTrials(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Trials != 10 {
+			t.Fatalf("%s: %d trials", r.Attacker, r.Trials)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = eval.WriteReport(&buf, results)
+	t.Log("\n" + buf.String())
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/rotate"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+func TestCanvasFilters(t *testing.T) {
+	// an opaque disc on a transparent square, like a tile
+	src := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if (x-32)*(x-32)+(y-32)*(y-32) < 20*20 {
+				src.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 4), G: uint8(y * 4), B: 128, A: 255})
+			}
+		}
+	}
+	before := append([]uint8(nil), src.Pix...)
+
+	filters := map[string]canvas.Filter{
+		"blur":     canvas.NewGaussianBlur(1.5),
+		"wave":     canvas.NewWaveWarp(2, 16),
+		"swirl":    canvas.NewSwirl(0.5),
+		"salt":     canvas.NewSaltPepper(0.2),
+		"jpeg":     canvas.NewJPEGArtifacts(20),
+		"jitter":   canvas.NewColorJitter(0.2, 0.2, 0.2),
+		"vignette": canvas.NewVignette(0.8),
+		"grid":     canvas.NewGridLines(6, color.Black, 0.5),
+	}
+	for name, f := range filters {
+		out := canvas.ApplyFilters(src, []canvas.Filter{f}).(*image.NRGBA)
+		if out.Bounds() != src.Bounds() {
+			t.Fatalf("%s: bounds %v", name, out.Bounds())
+		}
+		if _, _, _, a := out.At(0, 0).RGBA(); a != 0 {
+			t.Fatalf("%s: transparent corner became visible", name)
+		}
+		if _, _, _, a := out.At(32, 32).RGBA(); a == 0 {
+			t.Fatalf("%s: center became transparent", name)
+		}
+	}
+
+	for i := range before {
+		if src.Pix[i] != before[i] {
+			t.Fatal("ApplyFilters changed the source image")
+		}
+	}
+	if canvas.ApplyFilters(src, nil) != image.Image(src) {
+		t.Fatal("empty chain copied the image")
+	}
+}
+
+func TestCaptchaFilters(t *testing.T) {
+	var order []string
+	mark := func(name string) canvas.Filter {
+		return canvas.FilterFunc(func(img *image.NRGBA, rnd *rand.Rand) {
+			order = append(order, name)
+		})
+	}
+
+	if _, err := textCapt.With(click.WithMasterFilters(mark("master"), mark("master2")), click.WithThumbFilters(mark("thumb"))).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slideTileCapt.With(slide.WithMasterFilters(mark("master")), slide.WithTileFilters(mark("tile"))).Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotateCapt.With(rotate.WithMasterFilters(mark("master")), rotate.WithThumbFilters(mark("thumb"))).Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"master", "master2", "thumb", "master", "tile", "master", "thumb"}
+	if len(order) != len(want) {
+		t.Fatalf("filters ran %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("filters ran %v, want %v", order, want)
+		}
+	}
+}
+
+func TestGeometricFilters(t *testing.T) {
+	swirl := canvas.NewSwirl(0.5)
+	if _, err := textCapt.With(click.WithMasterFilters(swirl)).Generate(); err != canvas.GeometricFilterErr {
+		t.Fatalf("click master swirl: %v", err)
+	}
+	if _, err := slideTileCapt.With(slide.WithMasterFilters(swirl)).Generate(); err != canvas.GeometricFilterErr {
+		t.Fatalf("slide master swirl: %v", err)
+	}
+	if _, err := rotateCapt.With(rotate.WithMasterFilters(canvas.NewWaveWarp(2, 16))).Generate(); err != canvas.GeometricFilterErr {
+		t.Fatalf("rotate master wave: %v", err)
+	}
+
+	// the thumbnail carries no answer, the dot centers still validate
+	captData, err := textCapt.With(click.WithThumbFilters(swirl)).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dot := range captData.GetData() {
+		cx, cy := dot.X+dot.Width/2, dot.Y+dot.Height/2
+		if !click.Validate(cx, cy, dot.X, dot.Y, dot.Width, dot.Height, 0) {
+			t.Fatalf("the center of dot %d does not validate", dot.Index)
+		}
+	}
+}
+
+func TestBlurPlane(t *testing.T) {
+	// a constant plane stays constant, a spike spreads and keeps its sum
+	w, h := 16, 12
+	flat := make([]float64, w*h*2)
+	spike := make([]float64, w*h)
+	for i := range flat {
+		flat[i] = 0.5
+	}
+	spike[6*w+8] = 1
+	canvas.BlurPlane(flat, w, h, 2, canvas.RadiusSigma(2))
+	canvas.BlurPlane(spike, w, h, 1, canvas.RadiusSigma(2))
+
+	for i, v := range flat {
+		if math.Abs(v-0.5) > 1e-9 {
+			t.Fatalf("flat value %d blurred to %v", i, v)
+		}
+	}
+	sum := 0.0
+	for _, v := range spike {
+		sum += v
+	}
+	if math.Abs(sum-1) > 1e-9 || spike[6*w+8] >= 0.5 || spike[6*w+9] <= 0 {
+		t.Fatalf("spike blurred to %v, sum %v", spike[6*w+8], sum)
+	}
+
+	if canvas.RadiusSigma(0) != 0 || math.Abs(canvas.RadiusSigma(3)-2) > 1e-9 {
+		t.Fatal("the sigma of a box radius doesn't match its variance")
+	}
+}
+
+func TestShiftHue(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(2, 3, 4, 4))
+	img.SetNRGBA(2, 3, color.NRGBA{R: 200, G: 40, B: 40, A: 0xff})
+	img.SetNRGBA(3, 3, color.NRGBA{R: 200, G: 40, B: 40})
+
+	// a full turn keeps the color, half a turn moves red towards cyan, transparent pixels are kept
+	canvas.ShiftHue(img, 2*math.Pi)
+	if c := img.NRGBAAt(2, 3); math.Abs(float64(c.R)-200) > 1 || math.Abs(float64(c.G)-40) > 1 {
+		t.Fatalf("a full turn changed the color to %v", c)
+	}
+	canvas.ShiftHue(img, math.Pi)
+	if c := img.NRGBAAt(2, 3); c.R >= c.G || c.R >= c.B {
+		t.Fatalf("half a turn shifted red to %v", c)
+	}
+	if c := img.NRGBAAt(3, 3); c.R != 200 || c.G != 40 {
+		t.Fatalf("a transparent pixel changed to %v", c)
+	}
+
+	n := 0
+	canvas.EachPixel(img, func(x, y, i int) {
+		if img.PixOffset(x, y) != i {
+			t.Fatalf("offset %d of %d,%d", i, x, y)
+		}
+		n++
+	})
+	if n != 2 {
+		t.Fatalf("visited %d pixels", n)
+	}
+}
+
+func TestJPEGArtifactsSubImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 48, 48))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 200, 120, 40, 255
+	}
+
+	// a sub-image keeps the origin of its parent
+	sub := img.SubImage(image.Rect(16, 16, 40, 40)).(*image.NRGBA)
+	canvas.NewJPEGArtifacts(80).Apply(sub, rand.New(rand.NewSource(1)))
+	c := sub.NRGBAAt(30, 30)
+	if math.Abs(float64(c.R)-200) > 8 || math.Abs(float64(c.G)-120) > 8 || math.Abs(float64(c.B)-40) > 8 {
+		t.Fatalf("sub-image color %v", c)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"unicode"
+
+	"github.com/golang/freetype"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+	"github.com/wenlng/go-captcha/v2/click"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+// noDigits hides the digit glyphs of a font to exercise the fallback chain
+type noDigits struct {
+	canvas.Font
+}
+
+func (n noDigits) HasGlyph(r rune) bool {
+	return !unicode.IsDigit(r) && n.Font.HasGlyph(r)
+}
+
+func TestFontChainFallback(t *testing.T) {
+	bold, err := canvas.ParseFonts(gobold.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regular, err := canvas.ParseFonts(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := []canvas.Font{noDigits{bold[0]}, regular[0]}
+	if canvas.PickFont(chain, "A") != chain[0] {
+		t.Fatal("expected the first font for a letter")
+	}
+	if canvas.PickFont(chain, "1") != chain[1] {
+		t.Fatal("expected the fallback font for a digit")
+	}
+
+	cvs := canvas.CreateNRGBACanvas(80, 40, true)
+	err = cvs.DrawString(&canvas.DrawStringParams{
+		Color:     color.Black,
+		Size:      24,
+		FontDPI:   72,
+		Text:      "A1",
+		FontChain: chain,
+	}, freetype.Pt(4, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	area := cvs.CalcMarginBlankArea()
+	if area.MaxX <= area.MinX {
+		t.Fatal("nothing was drawn")
+	}
+}
+
+func TestClickTextWithSfntFonts(t *testing.T) {
+	regular, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bgImage, err := loadPng("../.cache/bg.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := click.NewBuilder()
+	builder.SetResources(
+		click.WithChars([]string{"A1", "B2", "C3", "D4", "E5", "F6", "G7", "H8"}),
+		click.WithSfntFonts([]*sfnt.Font{regular}),
+		click.WithBackgrounds([]image.Image{bgImage}),
+	)
+
+	captData, err := builder.Make().Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(captData.GetData()) == 0 {
+		t.Fatal("no dots generated")
+	}
+}
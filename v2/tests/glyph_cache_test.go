@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/golang/freetype"
+	"github.com/wenlng/go-captcha/v2/base/canvas"
+)
+
+func glyphCacheParams(b testing.TB, cache *canvas.GlyphCache) *canvas.DrawStringParams {
+	b.Helper()
+	fontN, err := loadFont("../.cache/fzshengsksjw_cu.ttf")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return &canvas.DrawStringParams{
+		Color:   color.RGBA{R: 0x1f, G: 0x55, B: 0xc4, A: 0xff},
+		Size:    28,
+		FontDPI: 72,
+		Text:    "A1B2",
+		Font:    fontN,
+		Cache:   cache,
+	}
+}
+
+func TestGlyphCacheDrawString(t *testing.T) {
+	cache := canvas.NewGlyphCache(0)
+	params := glyphCacheParams(t, cache)
+
+	cvs := canvas.CreateNRGBACanvas(120, 40, true)
+	if err := cvs.DrawString(params, freetype.Pt(4, 30)); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 4 {
+		t.Fatalf("expected 4 cached glyphs, got %d", cache.Len())
+	}
+
+	area := cvs.CalcMarginBlankArea()
+	if area.MaxX <= area.MinX || area.MaxY <= area.MinY {
+		t.Fatal("nothing was drawn")
+	}
+
+	params.Text = "B2"
+	if err := cvs.DrawString(params, freetype.Pt(4, 30)); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 4 {
+		t.Fatalf("repeated glyphs were rasterized again, got %d", cache.Len())
+	}
+
+	small := canvas.NewGlyphCache(1)
+	params.Cache = small
+	if err := cvs.DrawString(params, freetype.Pt(4, 30)); err != nil {
+		t.Fatal(err)
+	}
+	if small.UsedBytes() > 1 {
+		t.Fatalf("memory limit exceeded: %d", small.UsedBytes())
+	}
+}
+
+func benchmarkDrawString(b *testing.B, cache *canvas.GlyphCache) {
+	params := glyphCacheParams(b, cache)
+	texts := []string{"A1", "B2", "C3", "D4", "E5", "F6"}
+	cvs := canvas.CreateNRGBACanvas(60, 40, true)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		params.Text = texts[i%len(texts)]
+		params.Size = 26 + i%4
+		if err := cvs.DrawString(params, freetype.Pt(4, 30)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDrawStringFreetype(b *testing.B) {
+	benchmarkDrawString(b, nil)
+}
+
+func BenchmarkDrawStringGlyphCache(b *testing.B) {
+	benchmarkDrawString(b, canvas.NewGlyphCache(0))
+}
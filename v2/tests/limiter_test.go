@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wenlng/go-captcha/v2/limiter"
+	"github.com/wenlng/go-captcha/v2/slide"
+	"github.com/wenlng/go-captcha/v2/store"
+)
+
+func TestLimiters(t *testing.T) {
+	bucket := limiter.NewTokenBucket(0, 3)
+	for i := 0; i < 3; i++ {
+		if !bucket.Allow("a") {
+			t.Fatalf("token %d refused", i)
+		}
+	}
+	if bucket.Allow("a") || !bucket.Allow("b") {
+		t.Fatal("token bucket does not keep a bucket per key")
+	}
+
+	window := limiter.NewSlidingWindow(2, 50*time.Millisecond)
+	if !window.Allow("a") || !window.Allow("a") || window.Allow("a") {
+		t.Fatal("sliding window allowed more than its limit")
+	}
+	time.Sleep(120 * time.Millisecond)
+	if !window.Allow("a") {
+		t.Fatal("sliding window refused after two windows")
+	}
+}
+
+func TestGuardInvalidatesChallenge(t *testing.T) {
+	guard := limiter.NewGuard(
+		limiter.WithStore(store.NewMemory(store.WithMaxAttempts(3))),
+		limiter.WithVerifyLimiter(limiter.NewSlidingWindow(5, time.Minute)),
+	)
+
+	c := &store.Challenge{Kind: "slide", Data: &slide.Block{X: 120, Y: 40}}
+	if err := guard.Issue("10.0.0.1", c); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(x int) func(c *store.Challenge) bool {
+		return func(c *store.Challenge) bool {
+			block := c.Data.(*slide.Block)
+			return slide.Validate(x, block.Y, block.X, block.Y, 2)
+		}
+	}
+
+	// brute force with incrementing offsets
+	var err error
+	for x := 100; x < 103; x++ {
+		var ok bool
+		if ok, err = guard.Verify("10.0.0.1", c.ID, check(x)); ok {
+			t.Fatalf("offset %d passed", x)
+		}
+	}
+	if err != store.InvalidatedErr {
+		t.Fatalf("third failure: %v, want %v", err, store.InvalidatedErr)
+	}
+	if _, err = guard.Verify("10.0.0.1", c.ID, check(120)); err != store.NotFoundErr {
+		t.Fatalf("invalidated challenge: %v", err)
+	}
+
+	c = &store.Challenge{Kind: "slide", Data: &slide.Block{X: 120, Y: 40}}
+	_ = guard.Issue("10.0.0.1", c)
+	if _, err = guard.Verify("10.0.0.1", c.ID, check(120)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = guard.Verify("10.0.0.1", c.ID, check(120)); err != limiter.VerifyLimitErr {
+		t.Fatalf("sixth verification: %v, want %v", err, limiter.VerifyLimitErr)
+	}
+}
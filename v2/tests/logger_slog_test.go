@@ -0,0 +1,28 @@
+//go:build go1.21
+
+package tests
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/click"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	builder := click.NewBuilder()
+	builder.SetLogger(logger.NewSlog(slog.New(slog.NewTextHandler(&buf, nil))))
+	builder.SetResources(click.WithLocaleChars("xx"))
+	builder.MakeShape()
+
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "kind=click", "mode=shape", "resource=WithLocaleChars"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("%q is missing %q", out, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/bgvary"
+	"github.com/wenlng/go-captcha/v2/base/logger"
+	"github.com/wenlng/go-captcha/v2/click"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+func TestLoggerWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.With(logger.NewWriter(&buf), logger.F("kind", "click"), logger.F("note", "two words"))
+	l.Warnf("%d-%s", 1, "a")
+
+	out := buf.String()
+	if !strings.Contains(out, `WARN GO-CAPTCHA: 1-a kind=click note="two words"`) {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("color codes written to a buffer: %q", out)
+	}
+
+	logger.NewNop().Errorf("%v", "discarded")
+}
+
+func TestBuilderLogger(t *testing.T) {
+	var buf bytes.Buffer
+	builder := click.NewBuilder()
+	builder.SetLogger(logger.NewWriter(&buf))
+	builder.SetResources(click.WithChars([]string{"abc"}))
+	builder.Make()
+
+	out := buf.String()
+	for _, want := range []string{click.CharLenErr.Error(), "kind=click", "mode=text", "resource=WithChars"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("%q is missing %q", out, want)
+		}
+	}
+}
+
+func TestDefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetDefault(logger.NewWriter(&buf))
+	defer logger.SetDefault(nil)
+
+	colors := make([]string, 256)
+	for i := range colors {
+		colors[i] = "#ffffff"
+	}
+	opts := click.NewOptions()
+	click.WithRangeColors(colors)(opts)
+	logger.Logx.Infof("%s", "forwarded")
+
+	out := buf.String()
+	if !strings.Contains(out, "option=WithRangeColors") || !strings.Contains(out, "forwarded") {
+		t.Fatalf("default logger got %q", out)
+	}
+}
+
+func TestTrackerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	bg, err := loadPng("../.cache/bg.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := slide.NewBuilder(slide.WithBackgroundTracker(bgvary.NewTracker(5, 4)))
+	builder.SetLogger(logger.NewWriter(&buf))
+	builder.SetResources(
+		slide.WithGraphImages(getSlideTileGraphArr()),
+		slide.WithBackgrounds([]image.Image{bg}),
+	)
+	capt := builder.Make()
+	for i := 0; i < 4; i++ {
+		if _, err = capt.Generate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := buf.String()
+	for _, want := range []string{"effective backgrounds", "kind=slide", "component=bgvary"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("%q is missing %q", out, want)
+		}
+	}
+}
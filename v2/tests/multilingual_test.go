@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/helper"
+	"github.com/wenlng/go-captcha/v2/click"
+)
+
+func TestGraphemes(t *testing.T) {
+	cases := []struct {
+		str    string
+		length int
+		width  int
+	}{
+		{"A1", 2, 2},
+		{"我", 1, 2},
+		{"é", 1, 1},
+		{"👍🏽", 1, 2},
+		{"👨‍👩‍👧", 1, 2},
+		{"🇨🇳", 1, 2},
+		{"한", 1, 2},
+		{"한", 1, 2},
+		{"กำ", 1, 1},
+		{"مَر", 2, 2},
+	}
+
+	for _, c := range cases {
+		if l := helper.LenGrapheme(c.str); l != c.length {
+			t.Errorf("LenGrapheme(%q) = %d, want %d", c.str, l, c.length)
+		}
+		if w := helper.DisplayWidth(c.str); w != c.width {
+			t.Errorf("DisplayWidth(%q) = %d, want %d", c.str, w, c.width)
+		}
+	}
+
+	if !helper.IsRTL("مر") || helper.IsRTL("A1") {
+		t.Error("IsRTL detected the wrong direction")
+	}
+}
+
+func TestLocaleChars(t *testing.T) {
+	for _, locale := range []string{"zh-CN", "en", "ja", "ko_KR", "ar", "he", "th", "ru"} {
+		chars, ok := click.LocaleChars(locale)
+		if !ok {
+			t.Fatalf("no chars for %s", locale)
+		}
+
+		builder := click.NewBuilder()
+		if len(chars) < builder.Make().GetOptions().GetRangeLen().Max {
+			t.Fatalf("not enough chars for %s", locale)
+		}
+		for _, char := range chars {
+			if helper.DisplayWidth(char) > 2 {
+				t.Fatalf("%q of %s is too wide", char, locale)
+			}
+		}
+	}
+
+	if _, ok := click.LocaleChars("xx"); ok {
+		t.Fatal("unexpected chars for an unknown locale")
+	}
+}
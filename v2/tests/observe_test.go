@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"bytes"
+	"expvar"
+	"strings"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/bggen"
+	"github.com/wenlng/go-captcha/v2/base/observe"
+	"github.com/wenlng/go-captcha/v2/rotate"
+)
+
+type stageObserver struct {
+	observe.Nop
+	end       observe.GenerateEvent
+	resources []observe.ResourceEvent
+}
+
+func (s *stageObserver) GenerateEnd(e observe.GenerateEvent) {
+	s.end = e
+}
+
+func (s *stageObserver) ResourceSelected(e observe.ResourceEvent) {
+	s.resources = append(s.resources, e)
+}
+
+func TestObserverGenerate(t *testing.T) {
+	stages := &stageObserver{}
+	metrics := observe.NewMetrics()
+
+	builder := rotate.NewBuilder()
+	builder.SetResources(rotate.WithImageGenerators([]bggen.Generator{bggen.NewGradient()}))
+	builder.SetObserver(observe.Multi(stages, metrics))
+	capt := builder.Make()
+
+	captData, err := capt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = captData.GetMasterImage().ToBase64(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]bool)
+	for _, st := range stages.end.Stages {
+		names[st.Name] = true
+	}
+	for _, want := range []string{observe.StageLayout, observe.StageMaster, observe.StageThumb} {
+		if !names[want] {
+			t.Fatalf("stage %q is missing from %v", want, stages.end.Stages)
+		}
+	}
+	if len(stages.resources) != 1 || stages.resources[0].Name != "generator#0" {
+		t.Fatalf("unexpected resources %v", stages.resources)
+	}
+
+	observe.SetDefault(metrics)
+	defer observe.SetDefault(nil)
+	observe.Verified("rotate", rotate.Validate(10, 350, 5))
+	observe.Verified("rotate", rotate.Validate(10, 10, 5))
+
+	var buf bytes.Buffer
+	if err = metrics.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE gocaptcha_generate_total counter",
+		`gocaptcha_generate_total{kind="rotate",mode="",result="ok"} 1`,
+		`gocaptcha_stage_duration_seconds_count{kind="rotate",mode="",stage="encode"} 1`,
+		`gocaptcha_resource_selected_total{kind="rotate",type="image",name="generator#0"} 1`,
+		`gocaptcha_verify_total{kind="rotate",result="pass"} 1`,
+		`gocaptcha_verify_total{kind="rotate",result="fail"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("exposition is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestObserverExpvar(t *testing.T) {
+	metrics := observe.NewExpvar("gocaptcha_test")
+	metrics.Verified(observe.VerifyEvent{Kind: "slide", OK: true})
+
+	v := expvar.Get("gocaptcha_test")
+	if v == nil || !strings.Contains(v.String(), `"kind=slide,result=pass":1`) {
+		t.Fatalf("unexpected expvar value %v", v)
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/wenlng/go-captcha/v2/passive"
+	"github.com/wenlng/go-captcha/v2/risk"
+	"github.com/wenlng/go-captcha/v2/store"
+)
+
+func TestPassive(t *testing.T) {
+	issuer, err := passive.NewIssuer([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	human, _ := issuer.Issue()
+	time.Sleep(650 * time.Millisecond)
+
+	// the page sends the telemetry as JSON
+	body, _ := json.Marshal(human)
+	tel := &passive.Telemetry{Fingerprint: "human", Duration: 4200}
+	if err = json.Unmarshal(body, &tel.Challenge); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range humanTrack() {
+		tel.Pointer = append(tel.Pointer, passive.PointerEvent{X: p.X, Y: p.Y, T: p.T})
+	}
+	tel.Keys = []passive.KeyEvent{{Down: 800, Up: 880}, {Down: 1010, Up: 1075}, {Down: 1290, Up: 1380}, {Down: 1400, Up: 1460}}
+	tel.Focus = []passive.FocusEvent{{Type: "focus", T: 0}}
+
+	res, err := issuer.Verify(tel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Decision != risk.Allow {
+		t.Fatalf("human telemetry: %.2f %v %+v", res.Score, res.Decision, res.Signals)
+	}
+	if _, err = issuer.Verify(tel); err != store.NotFoundErr {
+		t.Fatalf("replayed challenge: %v", err)
+	}
+
+	// submitted at once without any interaction
+	bot, _ := issuer.Issue()
+	res, err = issuer.Verify(&passive.Telemetry{Challenge: *bot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Decision != risk.Deny {
+		t.Fatalf("empty telemetry: %.2f %v %+v", res.Score, res.Decision, res.Signals)
+	}
+
+	forged := *human
+	forged.ExpiresAt += int64(time.Hour / time.Millisecond)
+	if _, err = issuer.Verify(&passive.Telemetry{Challenge: forged}); err != passive.SignatureErr {
+		t.Fatalf("forged challenge: %v", err)
+	}
+}
+
+func TestPassiveDuration(t *testing.T) {
+	signal := passive.NewInteraction()
+	tel := &passive.Telemetry{
+		Pointer:  []passive.PointerEvent{{X: 10, Y: 20, T: 300}, {X: 14, Y: 22, T: 340}},
+		Keys:     []passive.KeyEvent{{Down: 900, Up: 980}},
+		Focus:    []passive.FocusEvent{{Type: "focus", T: 0}},
+		Duration: 1200,
+	}
+	if score, ok := signal.Evaluate(&risk.Attempt{Extra: tel}); !ok || score != 0 {
+		t.Fatalf("consistent duration scored %.2f", score)
+	}
+
+	// a missing duration, or one ending before the last key, is made up
+	for _, d := range []int64{0, 950} {
+		tel.Duration = d
+		if score, _ := signal.Evaluate(&risk.Attempt{Extra: tel}); score != 1 {
+			t.Fatalf("duration %d scored %.2f", d, score)
+		}
+	}
+}
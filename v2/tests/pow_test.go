@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/pow"
+)
+
+func TestPowSHA256(t *testing.T) {
+	issuer, err := pow.NewIssuer([]byte("secret"), pow.WithDifficulty(4, 12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issuer.Difficulty(0) != 4 || issuer.Difficulty(0.5) != 8 || issuer.Difficulty(1) != 12 {
+		t.Fatal("difficulty does not scale with the score")
+	}
+
+	c := issuer.Issue("challenge-1", 0.5)
+	nonce := pow.Solve(c)
+	if err = issuer.Verify(c, "challenge-1", nonce); err != nil {
+		t.Fatal(err)
+	}
+	if err = issuer.Verify(c, "challenge-2", nonce); err != pow.ChallengeIDErr {
+		t.Fatalf("other captcha challenge: %v", err)
+	}
+
+	tampered := *c
+	tampered.Difficulty = 0
+	if err = issuer.Verify(&tampered, "challenge-1", 0); err != pow.SignatureErr {
+		t.Fatalf("tampered difficulty: %v", err)
+	}
+
+	for n := uint64(0); ; n++ {
+		if pow.LeadingZeros(pow.Hash(c, n)) < c.Difficulty {
+			if err = issuer.Verify(c, "challenge-1", n); err != pow.InsufficientWorkErr {
+				t.Fatalf("bad nonce: %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestPowMemory(t *testing.T) {
+	issuer, _ := pow.NewIssuer([]byte("secret"), pow.WithAlgorithm(pow.AlgorithmMemory), pow.WithMemory(16))
+	c := issuer.Issue("challenge-1", 0)
+	if c.Memory != 16 || c.Difficulty != pow.DefaultMemoryMinDifficulty {
+		t.Fatalf("unexpected challenge %+v", c)
+	}
+	if err := issuer.Verify(c, "challenge-1", pow.Solve(c)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wenlng/go-captcha/v2/risk"
+)
+
+func humanTrack() []risk.TrackPoint {
+	var track []risk.TrackPoint
+	var t int64
+	for i := 0; i < 30; i++ {
+		t += int64(12 + (i*7)%9)
+		x := int(200 * (1 - math.Cos(float64(i)/29*math.Pi)) / 2)
+		track = append(track, risk.TrackPoint{X: x, Y: 80 + (i*5)%7, T: t})
+	}
+	return track
+}
+
+func botTrack() []risk.TrackPoint {
+	var track []risk.TrackPoint
+	for i := 0; i < 30; i++ {
+		track = append(track, risk.TrackPoint{X: i * 7, Y: 80, T: int64(i * 10)})
+	}
+	return track
+}
+
+func TestRiskScorer(t *testing.T) {
+	scorer := risk.NewScorer()
+	issued := time.Now()
+
+	human := &risk.Attempt{
+		Kind:        "slide",
+		IssuedAt:    issued,
+		SubmittedAt: issued.Add(2400 * time.Millisecond),
+		Attempts:    1,
+		Fingerprint: "human",
+		Points:      []risk.Point{{X: 203, Y: 81}},
+		Targets:     []risk.Target{{X: 200, Y: 80}},
+		Trajectory:  humanTrack(),
+	}
+	if res := scorer.Verify(true, human); res.Decision != risk.Allow {
+		t.Fatalf("human attempt: %.2f %v %+v", res.Score, res.Decision, res.Signals)
+	}
+	if res := scorer.Verify(false, human); res.Decision != risk.Challenge {
+		t.Fatalf("failed human attempt: %v", res.Decision)
+	}
+
+	bot := &risk.Attempt{
+		Kind:        "slide",
+		IssuedAt:    issued,
+		SubmittedAt: issued.Add(300 * time.Millisecond),
+		Attempts:    4,
+		Fingerprint: "bot",
+		Points:      []risk.Point{{X: 200, Y: 80}},
+		Targets:     []risk.Target{{X: 200, Y: 80}},
+		Trajectory:  botTrack(),
+	}
+	if res := scorer.Verify(true, bot); res.Decision == risk.Allow {
+		t.Fatalf("scripted attempt: %.2f %v %+v", res.Score, res.Decision, res.Signals)
+	}
+
+	// the same fingerprint over and over
+	var res *risk.Result
+	for i := 0; i < risk.DefaultFingerprintLimit; i++ {
+		res = scorer.Verify(true, bot)
+	}
+	if res.Decision != risk.Deny {
+		t.Fatalf("repeated scripted attempt: %.2f %v %+v", res.Score, res.Decision, res.Signals)
+	}
+}
+
+type fixedSignal float64
+
+func (f fixedSignal) Name() string { return "fixed" }
+
+func (f fixedSignal) Evaluate(*risk.Attempt) (float64, bool) { return float64(f), true }
+
+func TestRiskPlugin(t *testing.T) {
+	scorer := risk.NewScorer(
+		risk.WithSignal(fixedSignal(0.6), 1),
+		risk.WithSignal(risk.NewAttempts(3), 1),
+		risk.WithThresholds(0.4, 0.7),
+	)
+
+	res := scorer.Score(&risk.Attempt{})
+	if len(res.Signals) != 1 || res.Score != 0.6 || res.Decision != risk.Challenge {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	res = scorer.Score(&risk.Attempt{Attempts: 3})
+	if res.Score != 0.8 || res.Decision != risk.Deny {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
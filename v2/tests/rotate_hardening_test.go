@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/option"
+	"github.com/wenlng/go-captcha/v2/eval"
+	"github.com/wenlng/go-captcha/v2/rotate"
+)
+
+func TestRotateHardening(t *testing.T) {
+	capt := rotateCapt.With(
+		rotate.WithRingGap(6),
+		rotate.WithRangeRingBlur(option.RangeVal{Min: 2, Max: 4}),
+		rotate.WithThumbOcclusion(0.3),
+		rotate.WithThumbScaleJitter(0.06),
+		rotate.WithThumbHueJitter(20),
+		rotate.WithBackgroundNoise(0.3),
+	)
+
+	for i := 0; i < 10; i++ {
+		captData, err := capt.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		block := captData.GetData()
+		master := captData.GetMasterImage().Get()
+		size := master.Bounds().Dx()
+
+		// The ring gap clears the master under the thumb and just outside it
+		_, _, _, a := master.At(size/2, size/2).RGBA()
+		if a != 0 {
+			t.Fatal("master center is not cleared")
+		}
+		_, _, _, a = master.At(size/2+block.Width/2+3, size/2).RGBA()
+		if a != 0 {
+			t.Fatal("ring gap is not cleared")
+		}
+		_, _, _, a = master.At(size/2+block.Width/2+12, size/2).RGBA()
+		if a == 0 {
+			t.Fatal("master is cleared outside the ring gap")
+		}
+
+		thumb := captData.GetThumbImage().Get().Bounds()
+		if thumb.Dx() < block.Width-2 || thumb.Dx() > block.Width+2 {
+			t.Fatalf("thumb is %v, block is %d", thumb, block.Width)
+		}
+	}
+}
+
+func TestRotateHardeningEdgeContinuity(t *testing.T) {
+	capt := rotateCapt.With(
+		rotate.WithRingGap(6),
+		rotate.WithRangeRingBlur(option.RangeVal{Min: 2, Max: 4}),
+		rotate.WithBackgroundNoise(0.3),
+	)
+
+	results, err := eval.EvaluateRotate("hardened", capt, nil, eval.WithTrials(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate := results[0].SuccessRate(); rate > 0.5 {
+		t.Fatalf("edge continuity still solves %.0f%% of the hardened captchas", rate*100)
+	}
+}
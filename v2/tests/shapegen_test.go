@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/base/random"
+	"github.com/wenlng/go-captcha/v2/base/shapegen"
+	"github.com/wenlng/go-captcha/v2/click"
+)
+
+func TestShapeGenerators(t *testing.T) {
+	names := make(map[string]bool)
+	for _, s := range shapegen.Defaults() {
+		if s.Name() == "" || names[s.Name()] {
+			t.Fatalf("shape name %q is empty or duplicated", s.Name())
+		}
+		names[s.Name()] = true
+
+		a := s.Render(40, color.RGBA{R: 0xff, A: 0xff}, random.New())
+		b := s.Render(40, color.RGBA{R: 0xff, A: 0xff}, random.New())
+		if a.Bounds().Dx() != 40 || a.Bounds().Dy() != 40 {
+			t.Fatalf("%s: bounds %v", s.Name(), a.Bounds())
+		}
+		var opaque, clear int
+		for k := 3; k < len(a.Pix); k += 4 {
+			switch a.Pix[k] {
+			case 0xff:
+				opaque++
+			case 0:
+				clear++
+			}
+		}
+		if opaque < 40 || clear < 40 {
+			t.Fatalf("%s: %d opaque and %d transparent pixels", s.Name(), opaque, clear)
+		}
+		if bytes.Equal(a.Pix, b.Pix) {
+			t.Fatalf("%s: two renders are the same", s.Name())
+		}
+	}
+
+	capt := shapeCapt.WithResources(click.WithShapes(nil), click.WithShapeGenerators(shapegen.Defaults()))
+	captData, err := capt.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dot := range captData.GetData() {
+		if !names[dot.Shape] {
+			t.Fatalf("unexpected shape %q", dot.Shape)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/wenlng/go-captcha/v2/eval"
+	"github.com/wenlng/go-captcha/v2/slide"
+)
+
+func TestSlideCamouflage(t *testing.T) {
+	capt := slideTileCapt.With(
+		slide.WithDecoyNumber(2),
+		slide.WithGapFeather(2),
+		slide.WithGapNoise(0.5),
+		slide.WithTileEdgeJitter(0.4),
+		slide.WithTileLighting(true),
+	)
+
+	for i := 0; i < 10; i++ {
+		captData, err := capt.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		block := captData.GetData()
+		tile := captData.GetTileImage().Get().Bounds()
+		if tile.Dx() != block.Width || tile.Dy() != block.Height {
+			t.Fatalf("tile is %v, block is %dx%d", tile, block.Width, block.Height)
+		}
+		master := captData.GetMasterImage().Get().Bounds()
+		if master.Dx() != 300 || master.Dy() != 220 {
+			t.Fatalf("master is %v", master)
+		}
+	}
+}
+
+func TestSlideIndistinguishableDecoys(t *testing.T) {
+	capt := slideTileCapt.With(
+		slide.WithDecoyNumber(2),
+		slide.WithIndistinguishableDecoys(true),
+	)
+
+	results, err := eval.EvaluateSlide("decoys", capt, []eval.SlideAttacker{eval.TemplateMatch{}}, eval.WithTrials(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate := results[0].SuccessRate(); rate > 0.5 {
+		t.Fatalf("template matching still solves %.0f%% of the flattened gaps", rate*100)
+	}
+}